@@ -0,0 +1,44 @@
+// Package main is a sample crd-swagger post-processor that records where the swagger doc was generated from
+// as vendor extensions on the doc's info block.
+//
+// Build it and pass it to crd-swagger with:
+//
+//	go build -o info-extensions ./examples/post-processor
+//	crd-swagger --post-processor ./info-extensions -o swagger.json -f ./crds.yaml
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/KevinJoiner/crd-swagger/pkg/generator"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	if version := os.Getenv(generator.EnvPluginAPIVersion); version != generator.PluginAPIVersion {
+		return fmt.Errorf("unsupported plugin API version '%s'", version)
+	}
+	var swagger spec.Swagger
+	if err := json.NewDecoder(os.Stdin).Decode(&swagger); err != nil {
+		return fmt.Errorf("failed to decode swagger doc: %w", err)
+	}
+
+	if swagger.Info == nil {
+		swagger.Info = &spec.Info{}
+	}
+	swagger.Info.AddExtension("x-crd-source", os.Getenv(generator.EnvCRDSource))
+	swagger.Info.AddExtension("x-group-kinds", strings.Split(os.Getenv(generator.EnvGroupKinds), ","))
+	fmt.Fprintf(os.Stderr, "added info extensions for %s\n", os.Getenv(generator.EnvCRDSource))
+
+	return json.NewEncoder(os.Stdout).Encode(&swagger)
+}
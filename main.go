@@ -2,6 +2,7 @@ package main
 
 import (
 	"log"
+	"os"
 
 	"github.com/KevinJoiner/crd-swagger/pkg/cmd"
 )
@@ -9,6 +10,7 @@ import (
 func main() {
 	rootCmd := cmd.NewRootCommand()
 	if err := rootCmd.Execute(); err != nil {
-		log.Fatal(err)
+		log.Print(err)
+		os.Exit(cmd.ExitCode(err))
 	}
 }
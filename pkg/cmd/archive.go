@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/KevinJoiner/crd-swagger/pkg/generator"
+	"github.com/spf13/cobra"
+)
+
+type archiveFlagVar struct {
+	dir     string
+	version string
+}
+
+// archiveCommand holds the state of an archive command.
+type archiveCommand struct {
+	flags archiveFlagVar
+}
+
+// newArchiveCommand returns the command that manages an append-only archive of released swagger docs.
+func newArchiveCommand() *cobra.Command {
+	c := &archiveCommand{}
+	cmd := &cobra.Command{
+		Use:   "archive",
+		Short: "Manage an archive of released swagger docs",
+		Long: `Manages an append-only directory of the swagger docs of past releases. Each doc is stored as VERSION.json
+and recorded with its checksum in the directory's index.json. Archived docs can not be replaced.`,
+	}
+	cmd.PersistentFlags().StringVar(&c.flags.dir, "dir", "archive", "directory of the archive")
+
+	add := &cobra.Command{
+		Use:   "add FILE",
+		Short: "Add a swagger doc to the archive",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entry, err := generator.AddToArchive(c.flags.dir, args[0], c.flags.version)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("archived %s as %s\n", entry.Version, entry.File)
+			return nil
+		},
+	}
+	add.Flags().StringVar(&c.flags.version, "version", "", "release the doc is for, e.g. v2.9.1")
+	_ = add.MarkFlagRequired("version")
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List the archived versions",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.list()
+		},
+	}
+	show := &cobra.Command{
+		Use:   "show VERSION",
+		Short: "Print the swagger doc archived for a version",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			docPath, err := generator.ArchivedDoc(c.flags.dir, args[0])
+			if err != nil {
+				return err
+			}
+			data, err := os.ReadFile(docPath)
+			if err != nil {
+				return fmt.Errorf("failed to read archived doc: %w", err)
+			}
+			_, err = os.Stdout.Write(data)
+			return err
+		},
+	}
+	diffCmd := &diffCommand{}
+	diff := &cobra.Command{
+		Use:   "diff OLD NEW",
+		Short: "Show the changes between two archived versions",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			oldDoc, err := generator.ArchivedDoc(c.flags.dir, args[0])
+			if err != nil {
+				return err
+			}
+			newDoc, err := generator.ArchivedDoc(c.flags.dir, args[1])
+			if err != nil {
+				return err
+			}
+			return diffCmd.run(oldDoc, newDoc)
+		},
+	}
+	diffCmd.addFlags(diff.Flags())
+	for _, sub := range []*cobra.Command{add, list, show, diff} {
+		// archive errors and breaking changes are not usage errors
+		sub.SilenceUsage = true
+		cmd.AddCommand(sub)
+	}
+	return cmd
+}
+
+func (c *archiveCommand) list() error {
+	index, err := generator.LoadArchive(c.flags.dir)
+	if err != nil {
+		return err
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "VERSION\tADDED\tFILE\tSHA256")
+	for _, entry := range index.Entries {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", entry.Version, entry.Added.Format(time.RFC3339), entry.File, entry.SHA256[:12])
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/KevinJoiner/crd-swagger/pkg/generator"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// outputAudiences splits the swagger doc by the audience map and writes the doc of each audience
+// to its own output file named after the audience.
+func (c *generateCommand) outputAudiences(swagger *spec.Swagger) error {
+	if c.flags.outputFile == "" {
+		return fmt.Errorf("an output file is required when using an audience map")
+	}
+	audienceMap, err := generator.LoadAudienceMap(c.flags.audienceMap)
+	if err != nil {
+		return err
+	}
+	docs, err := generator.PartitionByAudience(swagger, audienceMap)
+	if err != nil {
+		return err
+	}
+
+	outputFile, badgeFile, findingsFile, rbacFile := c.flags.outputFile, c.flags.badgeFile, c.flags.findingsFile, c.flags.rbacFile
+	defer func() {
+		c.flags.outputFile, c.flags.badgeFile, c.flags.findingsFile, c.flags.rbacFile = outputFile, badgeFile, findingsFile, rbacFile
+	}()
+	for _, audience := range generator.Audiences {
+		c.flags.outputFile = suffixedPath(outputFile, audience)
+		c.flags.badgeFile = suffixedPath(badgeFile, audience)
+		c.flags.findingsFile = suffixedPath(findingsFile, audience)
+		c.flags.rbacFile = suffixedPath(rbacFile, audience)
+		if err := c.output(docs[audience]); err != nil {
+			return fmt.Errorf("failed to output swagger doc for the %s audience: %w", audience, err)
+		}
+	}
+	return nil
+}
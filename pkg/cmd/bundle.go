@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/KevinJoiner/crd-swagger/pkg/generator"
+	"github.com/spf13/cobra"
+)
+
+const bundleManifestName = "manifest.json"
+
+type bundleFlagVar struct {
+	outputFile string
+	name       string
+	version    string
+}
+
+// bundleCommand holds the state of a bundle command.
+type bundleCommand struct {
+	flags bundleFlagVar
+}
+
+// bundleManifest describes the contents of a bundle.
+type bundleManifest struct {
+	Name    string       `json:"name,omitempty"`
+	Version string       `json:"version,omitempty"`
+	Created string       `json:"created"`
+	Files   []bundleFile `json:"files"`
+}
+
+type bundleFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// newBundleCommand returns the command that packages generated docs into a single archive.
+func newBundleCommand() *cobra.Command {
+	c := &bundleCommand{}
+	cmd := &cobra.Command{
+		Use:   "bundle [flags] FILE|DIR...",
+		Short: "Package generated docs into a single archive",
+		Long: `Packages generated docs (swagger doc, html page, markdown pages, badges, findings, ...) into a single gzipped tar archive.
+The archive contains a manifest.json listing every file with its size and sha256 digest.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.run(args)
+		},
+	}
+	cmd.Flags().StringVarP(&c.flags.outputFile, "output-file", "o", "", "location to write the bundle archive")
+	cmd.Flags().StringVar(&c.flags.name, "name", "", "name recorded in the bundle manifest")
+	cmd.Flags().StringVar(&c.flags.version, "version", "", "version recorded in the bundle manifest, e.g. the Rancher version the docs are for")
+	_ = cmd.MarkFlagRequired("output-file")
+	return cmd
+}
+
+func (c *bundleCommand) run(inputs []string) error {
+	// map of path in the archive to path on disk
+	files := map[string]string{}
+	for _, input := range inputs {
+		err := filepath.WalkDir(input, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() {
+				return nil
+			}
+			archivePath, err := bundlePath(input, path)
+			if err != nil {
+				return err
+			}
+			if archivePath == bundleManifestName {
+				return fmt.Errorf("'%s' conflicts with the bundle manifest", path)
+			}
+			if existing, ok := files[archivePath]; ok {
+				return fmt.Errorf("'%s' and '%s' have the same path in the bundle", existing, path)
+			}
+			files[archivePath] = path
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to read '%s': %w", input, err)
+		}
+	}
+
+	// SOURCE_DATE_EPOCH makes the bundle reproducible
+	created, err := generator.BuildTime()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(c.flags.outputFile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+	defer out.Close()
+	gzipWriter := gzip.NewWriter(out)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	manifest := bundleManifest{
+		Name:    c.flags.name,
+		Version: c.flags.version,
+		Created: created.Format(time.RFC3339),
+	}
+	for _, archivePath := range sortedKeys(files) {
+		data, err := os.ReadFile(files[archivePath])
+		if err != nil {
+			return fmt.Errorf("failed to read '%s': %w", files[archivePath], err)
+		}
+		if err := writeTarFile(tarWriter, archivePath, data, created); err != nil {
+			return err
+		}
+		digest := sha256.Sum256(data)
+		manifest.Files = append(manifest.Files, bundleFile{Path: archivePath, Size: int64(len(data)), SHA256: hex.EncodeToString(digest[:])})
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal bundle manifest: %w", err)
+	}
+	if err := writeTarFile(tarWriter, bundleManifestName, manifestData, created); err != nil {
+		return err
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return out.Close()
+}
+
+// bundlePath returns the path of a file in the archive.
+// Files given directly are placed at the root and directories are placed at the root with their contents.
+func bundlePath(input, path string) (string, error) {
+	rel, err := filepath.Rel(filepath.Dir(filepath.Clean(input)), path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+func writeTarFile(tarWriter *tar.Writer, name string, data []byte, modTime time.Time) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+		Format:  tar.FormatPAX,
+	}
+	if err := tarWriter.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write '%s' to bundle: %w", name, err)
+	}
+	if _, err := tarWriter.Write(data); err != nil {
+		return fmt.Errorf("failed to write '%s' to bundle: %w", name, err)
+	}
+	return nil
+}
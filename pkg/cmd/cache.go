@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/KevinJoiner/crd-swagger/pkg/generator"
+	"github.com/docker/go-units"
+	"github.com/spf13/cobra"
+)
+
+type cacheFlagVar struct {
+	cacheDir string
+	maxAge   string
+	maxSize  string
+}
+
+// cacheCommand holds the state of a cache command.
+type cacheCommand struct {
+	flags cacheFlagVar
+}
+
+// newCacheCommand returns the command that manages the swagger doc cache.
+func newCacheCommand() *cobra.Command {
+	c := &cacheCommand{}
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the swagger doc cache",
+		Long:  `Lists, garbage collects, and purges the cluster swagger docs cached by previous runs.`,
+	}
+	cmd.PersistentFlags().StringVar(&c.flags.cacheDir, "cache-dir", "", "directory of the swagger doc cache (default the crd-swagger directory in the user cache directory)")
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List the cached swagger docs",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.list()
+		},
+	}
+	gc := &cobra.Command{
+		Use:   "gc",
+		Short: "Remove old cached swagger docs",
+		Long: `Removes the cached swagger docs that have not been used for max-age, then removes the least recently used docs
+until the cache is at most max-size.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.gc()
+		},
+	}
+	gc.Flags().StringVar(&c.flags.maxAge, "max-age", "30d", "remove docs not used for this long, e.g. 30d or 12h (0 disables the limit)")
+	gc.Flags().StringVar(&c.flags.maxSize, "max-size", "0", "remove the least recently used docs until the cache is at most this size, e.g. 5GB (0 disables the limit)")
+	purge := &cobra.Command{
+		Use:   "purge",
+		Short: "Remove every cached swagger doc",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			removed, err := generator.PurgeCache(c.dir())
+			printRemoved(removed)
+			return err
+		},
+	}
+	cmd.AddCommand(list, gc, purge)
+	return cmd
+}
+
+func (c *cacheCommand) dir() string {
+	if c.flags.cacheDir != "" {
+		return c.flags.cacheDir
+	}
+	return defaultCacheDir()
+}
+
+func (c *cacheCommand) list() error {
+	entries, err := generator.ListCache(c.dir())
+	if err != nil {
+		return err
+	}
+	var total int64
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "KEY\tSIZE\tLAST USED")
+	for _, entry := range entries {
+		total += entry.Size
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", entry.Key, units.HumanSize(float64(entry.Size)), entry.LastUsed.Format(time.RFC3339))
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write cache entries: %w", err)
+	}
+	fmt.Printf("%d docs, %s\n", len(entries), units.HumanSize(float64(total)))
+	return nil
+}
+
+func (c *cacheCommand) gc() error {
+	maxAge, err := parseAge(c.flags.maxAge)
+	if err != nil {
+		return err
+	}
+	maxSize, err := units.FromHumanSize(c.flags.maxSize)
+	if err != nil {
+		return fmt.Errorf("invalid max-size: %w", err)
+	}
+	removed, err := generator.PruneCache(c.dir(), maxAge, maxSize)
+	printRemoved(removed)
+	return err
+}
+
+// parseAge parses a duration that may also be given in days, e.g. 30d.
+func parseAge(age string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(age, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid max-age '%s': %w", age, err)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	duration, err := time.ParseDuration(age)
+	if err != nil {
+		return 0, fmt.Errorf("invalid max-age '%s': %w", age, err)
+	}
+	return duration, nil
+}
+
+func printRemoved(removed []generator.CacheEntry) {
+	var total int64
+	for _, entry := range removed {
+		total += entry.Size
+	}
+	fmt.Printf("Removed %d docs, %s\n", len(removed), units.HumanSize(float64(total)))
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		age     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{age: "30d", want: 30 * 24 * time.Hour},
+		{age: "0d", want: 0},
+		{age: "12h", want: 12 * time.Hour},
+		{age: "90m", want: 90 * time.Minute},
+		{age: "d", wantErr: true},
+		{age: "1.5d", wantErr: true},
+		{age: "soon", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.age, func(t *testing.T) {
+			got, err := parseAge(tt.age)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("parseAge(%s) = %v, %v, want %v, wantErr %v", tt.age, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
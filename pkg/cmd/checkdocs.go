@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/KevinJoiner/crd-swagger/pkg/generator"
+	"github.com/spf13/cobra"
+)
+
+type checkDocsFlagVar struct {
+	crdSource     string
+	recurse       bool
+	goPackages    string
+	controllerGen string
+	maxMissing    int
+}
+
+// checkDocsCommand holds the state of a check-docs command.
+type checkDocsCommand struct {
+	cluster *generateCommand
+	flags   checkDocsFlagVar
+}
+
+// newCheckDocsCommand returns the command that reports API fields without doc comments.
+func newCheckDocsCommand() *cobra.Command {
+	c := &checkDocsCommand{cluster: newClusterCommand()}
+	cmd := &cobra.Command{
+		Use:   "check-docs",
+		Short: "Report API fields without doc comments",
+		Long: `Reports the fields of the input CRDs that have no description, for Go types these are the fields without a doc comment.
+Undocumented fields have an empty description in the generated swagger doc.
+Prints a summary per group version and exits non-zero when more than max-missing fields are undocumented.`,
+		Args: cobra.NoArgs,
+		// missing docs fail the command but are not a usage error
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.cluster.setupLogger(); err != nil {
+				return err
+			}
+			return c.run()
+		},
+	}
+	cmd.Flags().StringVarP(&c.flags.crdSource, "files", "f", "", "location to find input CRD file/files, either a file path or a remote file URL")
+	cmd.Flags().BoolVarP(&c.flags.recurse, "recurse", "r", false, "if files is a local directory recursively search for all CRDs")
+	cmd.Flags().StringVar(&c.flags.goPackages, "from-go-module", "", "check the kubebuilder annotated Go types in these packages using controller-gen instead of reading files, e.g. ./pkg/apis/...")
+	cmd.Flags().StringVar(&c.flags.controllerGen, "controller-gen", "controller-gen", "controller-gen binary used by from-go-module")
+	cmd.Flags().IntVar(&c.flags.maxMissing, "max-missing", 0, "number of undocumented fields allowed before failing")
+	return cmd
+}
+
+func (c *checkDocsCommand) run() error {
+	summaries, findings, err := generator.CheckDocs(generator.Options{
+		CRDSource:     c.flags.crdSource,
+		Recurse:       c.flags.recurse,
+		GoPackages:    c.flags.goPackages,
+		ControllerGen: c.flags.controllerGen,
+	})
+	if err != nil {
+		return err
+	}
+	for _, finding := range findings {
+		fmt.Println(finding.String())
+	}
+	if len(findings) != 0 {
+		fmt.Println()
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "PACKAGE\tFIELDS\tMISSING\tCOVERAGE")
+	for _, summary := range summaries {
+		fmt.Fprintf(writer, "%s\t%d\t%d\t%.1f%%\n", summary.Package, summary.Fields, summary.Missing, summary.Coverage())
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write summary: %w", err)
+	}
+
+	if len(findings) > c.flags.maxMissing {
+		return fmt.Errorf("%d fields are missing doc comments, at most %d are allowed", len(findings), c.flags.maxMissing)
+	}
+	return nil
+}
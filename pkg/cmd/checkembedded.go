@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/KevinJoiner/crd-swagger/pkg/generator"
+	"github.com/spf13/cobra"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+type checkEmbeddedFlagVar struct {
+	upstreamFile  string
+	minSimilarity float64
+	server        string
+	token         string
+	username      string
+	password      string
+	caFile        string
+	insecure      bool
+}
+
+// checkEmbeddedCommand holds the state of a check-embedded command.
+type checkEmbeddedCommand struct {
+	flags checkEmbeddedFlagVar
+}
+
+// newCheckEmbeddedCommand returns the command that reports copies of upstream Kubernetes types that differ from them.
+func newCheckEmbeddedCommand() *cobra.Command {
+	c := &checkEmbeddedCommand{}
+	cmd := &cobra.Command{
+		Use:   "check-embedded DOC",
+		Short: "Report copies of Kubernetes types that differ from upstream",
+		Long: `Finds the schemas in the definitions of a generated swagger doc that copy a Kubernetes type, such as a PodSpec or
+ObjectMeta vendored into a CRD, and reports how each copy differs from the upstream type. Stale copies document
+fields that no longer match what the API server accepts.
+The upstream types are read from a swagger doc, e.g. the Kubernetes api/openapi-spec/swagger.json, or from the
+swagger doc served by a live API server. Exits non-zero when any copy differs.`,
+		Args: cobra.ExactArgs(1),
+		// divergent copies fail the command but are not a usage error
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.run(args[0])
+		},
+	}
+	cmd.Flags().StringVar(&c.flags.upstreamFile, "upstream", "", "swagger doc with the upstream Kubernetes definitions, e.g. the Kubernetes api/openapi-spec/swagger.json")
+	cmd.Flags().Float64Var(&c.flags.minSimilarity, "min-similarity", generator.DefaultMinSimilarity, "fraction of fields a schema must share with a Kubernetes type to be treated as a copy of it")
+	cmd.Flags().StringVar(&c.flags.server, "server", "", "address of a live API server to read the upstream definitions from instead of a swagger doc")
+	cmd.Flags().StringVar(&c.flags.token, "token", "", "bearer token used to authenticate to the API server")
+	cmd.Flags().StringVar(&c.flags.username, "username", "", "username for basic authentication to the API server")
+	cmd.Flags().StringVar(&c.flags.password, "password", "", "password for basic authentication to the API server")
+	cmd.Flags().StringVar(&c.flags.caFile, "ca-file", "", "path to a cert file for the certificate authority of the API server")
+	cmd.Flags().BoolVar(&c.flags.insecure, "insecure-skip-tls-verify", false, "do not verify the API server's certificate")
+	return cmd
+}
+
+func (c *checkEmbeddedCommand) run(docFile string) error {
+	if c.flags.minSimilarity <= 0 || c.flags.minSimilarity > 1 {
+		return fmt.Errorf("min-similarity must be greater than 0 and at most 1")
+	}
+	var upstream *spec.Swagger
+	var err error
+	switch {
+	case c.flags.upstreamFile != "" && c.flags.server != "":
+		return fmt.Errorf("only one of upstream or server can be set")
+	case c.flags.upstreamFile != "":
+		upstream, err = readSwagger(c.flags.upstreamFile)
+	case c.flags.server != "":
+		upstream, err = generator.ServerSwagger(generator.Options{
+			Server:                c.flags.server,
+			Token:                 c.flags.token,
+			Username:              c.flags.username,
+			Password:              c.flags.password,
+			CAFile:                c.flags.caFile,
+			InsecureSkipTLSVerify: c.flags.insecure,
+		})
+	default:
+		return fmt.Errorf("either upstream or server must be set")
+	}
+	if err != nil {
+		return err
+	}
+	doc, err := readSwagger(docFile)
+	if err != nil {
+		return err
+	}
+
+	embedded := generator.CheckEmbedded(doc, upstream, c.flags.minSimilarity)
+	for _, copied := range embedded {
+		fmt.Println(copied.String())
+	}
+	if len(embedded) != 0 {
+		return fmt.Errorf("%d copies of Kubernetes types differ from upstream", len(embedded))
+	}
+	fmt.Println("no copies of Kubernetes types differ from upstream")
+	return nil
+}
@@ -3,213 +3,489 @@ package cmd
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
 	"time"
 
+	"github.com/KevinJoiner/crd-swagger/pkg/generator"
+	"github.com/KevinJoiner/crd-swagger/pkg/render"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
-	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
-	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/kube-openapi/pkg/aggregator"
 	"k8s.io/kube-openapi/pkg/validation/spec"
 )
 
 const (
-	kubePath       = "/etc/rancher/k3s/k3s.yaml"
-	crdKind        = "CustomResourceDefinition"
-	requestTimeout = time.Second * 5
-	waitInterval   = time.Millisecond * 500
-	waitTime       = time.Second * 15
-	syncTime       = time.Second * 2
-	extensionGVK   = "x-kubernetes-group-version-kind"
+	formatJSON = "json"
+	formatHTML = "html"
+	formatMD   = "markdown"
+	formatTS   = "typescript"
+	formatGo   = "go"
+
+	findingsText  = "text"
+	findingsSARIF = "sarif"
+
+	encoderStandard = "standard"
+	encoderStream   = "stream"
+
+	logFormatText = "text"
+	logFormatJSON = "json"
 )
 
 type flagVar struct {
-	outputFile  string
-	crdSource   string
-	k3sPort     string
-	prettyPrint bool
-	recurse     bool
-	silent      bool
-}
+	outputFile     string
+	outputFormat   string
+	jsonEncoder    string
+	chunkMaxDefs   int
+	redocScript    string
+	goTypesPackage string
+	badgeFile      string
+	rbacFile       string
+	summaryFile    string
+	notifyWebhook  string
+	crdSource      string
+	resourcesFile  string
+	aliasesFile    string
+	installOrder   string
+	k3sPort        string
+	prettyPrint    bool
+	compress       bool
+	validate       bool
+	ignoreMissing  bool
+	rbac           bool
+	recurse        bool
+	goPackages     string
+	controllerGen  string
+	silent         bool
+	logFormat      string
+	logLevel       string
+	logFile        string
+	configFile     string
+	engine         string
+	flattenAllOf   bool
+	anonymize      bool
+	stripDescs     bool
+	stripExts      []string
+	slowTime       time.Duration
+	watch          bool
+	verbs          []string
+	excludeVerbs   []string
+	excludeSubs    []string
+	subsOnly       bool
+	defsOnly       bool
+	lintRules      []string
+	findingsFormat string
+	findingsFile   string
+	audienceMap    string
 
-var (
-	cmdFlags     flagVar
-	errDuplicate = fmt.Errorf("duplicate CRD")
-)
+	keepContainer      bool
+	debugLogsDir       string
+	reuseContainer     bool
+	persistCredentials bool
+	privileged         bool
+	readOnlyRootFS     bool
+	seccompProfile     string
+	apparmorProfile    string
+	noNewPrivileges    bool
+	restartPolicy      string
+	image              string
+	k8sVersion         string
+	k8sVersions        []string
+	maxClusters        int
+	maxFilters         int
+	containerEnv       []string
+	k3sRegistries      string
+	k3sCABundle        string
+	featureGates       []string
+	applyManifests     []string
+	manifestTime       time.Duration
+	charts             []string
+	chartVersion       string
+	imageTar           string
+	registryAuth       string
+
+	openAPIURL string
+	server     string
+	token      string
+	username   string
+	password   string
+	caFile     string
+	insecure   bool
+
+	pullTime     time.Duration
+	readyTime    time.Duration
+	discoverTime time.Duration
+	retries      int
+
+	host                string
+	basePath            string
+	schemes             []string
+	bearerAuth          bool
+	pathPrefix          string
+	verifyDeterministic bool
+	canonical           bool
+	resolveRefs         bool
+	stevePaths          bool
+	minVersions         bool
+	cacheDir            string
+	noCache             bool
+	offlineInputs       bool
+	postProcessors      []string
+
+	title        string
+	docVersion   string
+	description  string
+	contactName  string
+	contactURL   string
+	contactEmail string
+	license      string
+	licenseURL   string
+}
 
 // NewRootCommand returns the root crd-swagger command.
 func NewRootCommand() *cobra.Command {
-	cmd := &cobra.Command{
-		Use:   "crd-swagger",
-		Short: "crd-swagger creates swagger docs for CRDs",
-		Long:  `Generates a Swagger (openapiv2) document for Custom Resource Definitions (CRDs) installed and accessed through kube-apiserver.`,
-		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := setupLogger(); err != nil {
-				return err
-			}
-			defer zap.L().Sync()
-			return run()
-		},
-	}
-	addFlags(cmd)
+	cmd := NewGenerateCommand(GenerateCommandOptions{Use: "crd-swagger"})
+	cmd.Short = "crd-swagger creates swagger docs for CRDs"
+	cmd.AddCommand(newBundleCommand())
+	cmd.AddCommand(newCheckDocsCommand())
+	cmd.AddCommand(newCheckEmbeddedCommand())
+	cmd.AddCommand(newValidateCommand())
+	cmd.AddCommand(newValidateCRCommand())
+	cmd.AddCommand(newDiffCommand())
+	cmd.AddCommand(newCacheCommand())
+	cmd.AddCommand(newListCommand())
+	cmd.AddCommand(newDaemonCommand())
+	cmd.AddCommand(newDiagnoseCommand())
+	cmd.AddCommand(newArchiveCommand())
+	cmd.AddCommand(newInitCommand(cmd))
 	return cmd
 }
 
-func setupLogger() error {
-	atom := zap.NewAtomicLevel()
-	if cmdFlags.silent {
+func (c *generateCommand) setupLogger() error {
+	c.logOut = c.streams.LogOut
+	if c.streams.Logger != nil {
+		_ = zap.ReplaceGlobals(c.streams.Logger)
+		return nil
+	}
+	level, err := zapcore.ParseLevel(c.flags.logLevel)
+	if err != nil || level < zapcore.DebugLevel || level > zapcore.ErrorLevel {
+		return fmt.Errorf("unknown log level '%s' must be one of [debug, info, warn, error]", c.flags.logLevel)
+	}
+	logrusLevel, _ := logrus.ParseLevel(level.String())
+	atom := zap.NewAtomicLevelAt(level)
+	if c.flags.silent {
 		atom.SetLevel(zapcore.FatalLevel)
-		// need to set logrus level for wrangler logging
-		logrus.SetLevel(logrus.FatalLevel)
+		logrusLevel = logrus.FatalLevel
+	}
+	// need to set logrus level for wrangler logging
+	logrus.SetLevel(logrusLevel)
+	if c.flags.logFile != "" {
+		file, err := os.OpenFile(c.flags.logFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		c.logOut = file
+		logrus.SetOutput(file)
 	}
 	encoderCfg := zap.NewProductionEncoderConfig()
 	encoderCfg.EncodeTime = zapcore.ISO8601TimeEncoder
+	var encoder zapcore.Encoder
+	switch c.flags.logFormat {
+	case logFormatText:
+		encoder = zapcore.NewConsoleEncoder(encoderCfg)
+	case logFormatJSON:
+		encoder = zapcore.NewJSONEncoder(encoderCfg)
+	default:
+		return fmt.Errorf("unknown log format '%s' must be one of [%s, %s]", c.flags.logFormat, logFormatText, logFormatJSON)
+	}
 	logger := zap.New(zapcore.NewCore(
-		zapcore.NewConsoleEncoder(encoderCfg),
-		zapcore.Lock(os.Stdout),
+		encoder,
+		zapcore.Lock(zapcore.AddSync(c.logOut)),
 		atom,
 	))
 	_ = zap.ReplaceGlobals(logger)
 	return nil
 }
 
-func addFlags(cmd *cobra.Command) {
-	cmd.Flags().StringVarP(&cmdFlags.crdSource, "files", "f", "", "location to find input CRD file/files, either a file path or a remote file URL")
-	cmd.Flags().BoolVarP(&cmdFlags.recurse, "recurse", "r", false, "if files is a local directory recursively search for all CRDs")
-	cmd.Flags().StringVarP(&cmdFlags.outputFile, "output-file", "o", "", "location to output the generate swagger doc (if unset stdout is used)")
-	cmd.Flags().BoolVarP(&cmdFlags.prettyPrint, "pretty-print", "p", false, "print the output json with formatted with newlines and indentations")
-	cmd.Flags().StringVar(&cmdFlags.k3sPort, "cluster-port", defaultK3sPort, "port to bind kubeapi-server to on the host machine")
-	cmd.Flags().BoolVar(&cmdFlags.silent, "silent", false, "do not print any log messages")
-	_ = cmd.MarkFlagRequired("files")
+func (c *generateCommand) addFlags(cmd *cobra.Command) {
+	c.addClusterFlags(cmd.Flags())
+	cmd.Flags().StringVar(&c.flags.configFile, "config", "", "YAML file of flag names and values to use for the flags not set on the command line, see crd-swagger init")
+	cmd.Flags().StringVarP(&c.flags.outputFile, "output-file", "o", "", "location to output the generate swagger doc (if unset stdout is used)")
+	cmd.Flags().StringVar(&c.flags.outputFormat, "output-format", formatJSON, "format of the generated doc, one of json, html (a static Redoc page), markdown (a page per kind written to the output-file directory), typescript (an interface per definition), or go (a type per definition)")
+	cmd.Flags().StringVar(&c.flags.goTypesPackage, "go-package", "types", "package name of the types generated by go output")
+	cmd.Flags().StringVar(&c.flags.jsonEncoder, "json-encoder", encoderStandard, "encoder of json output, either standard or stream (writes one path and definition at a time to the output instead of building the whole doc in memory, for very large docs)")
+	cmd.Flags().IntVar(&c.flags.chunkMaxDefs, "chunk-max-definitions", 0, "split the json doc into docs with at most this many definitions each, written to the output-file with the chunk number added to its name (0 disables splitting)")
+	cmd.Flags().StringVar(&c.flags.redocScript, "redoc-script", "", fmt.Sprintf("local file path or URL of the Redoc bundle used by html output instead of the bundle built into crd-swagger, local files are embedded in the page and URLs such as %s are loaded when the page is viewed", render.RedocCDNScript))
+	cmd.Flags().StringVar(&c.flags.badgeFile, "badge-out", "", "location to output a shields.io endpoint badge JSON with the number of documented kinds")
+	cmd.Flags().StringVar(&c.flags.rbacFile, "rbac-out", "", "location to output example ClusterRoles granting read-only and read-write access to exactly the documented kinds")
+	cmd.Flags().StringVar(&c.flags.summaryFile, "summary-file", "", "location to output a JSON summary of the run with the image, the requested, found, and missing kinds, the doc size, the duration, and the exit code")
+	cmd.Flags().StringVar(&c.flags.notifyWebhook, "notify-webhook", "", "URL of a Slack compatible webhook to post a summary to after the swagger doc is written")
+	cmd.Flags().BoolVar(&c.flags.compress, "compress", false, "gzip the output, .gz is added to the output-file name")
+	cmd.Flags().BoolVarP(&c.flags.prettyPrint, "pretty-print", "p", false, "print the output json with formatted with newlines and indentations")
+	cmd.Flags().BoolVar(&c.flags.ignoreMissing, "ignore-missing", false, "write the swagger doc for the GroupKinds that were found instead of failing when some are missing, the missing GroupKinds are reported and the exit code is 2")
+	cmd.Flags().BoolVar(&c.flags.validate, "validate", false, "validate the generated doc against the Swagger 2.0 specification and verify every $ref resolves, failing instead of writing an invalid doc")
+	cmd.Flags().BoolVar(&c.flags.anonymize, "anonymize", false, "remove server URLs, UIDs, and other details that identify the source cluster from the output")
+	cmd.Flags().BoolVar(&c.flags.stripDescs, "strip-descriptions", false, "remove every description from the output to shrink it")
+	cmd.Flags().StringSliceVar(&c.flags.stripExts, "strip-extensions", nil, "remove the vendor extensions matching these names from the output, patterns such as x-kubernetes-* are supported")
+	cmd.Flags().BoolVar(&c.flags.flattenAllOf, "flatten-allof", false, "merge allOf members into a single object schema where it is safe to do so")
+	cmd.Flags().DurationVar(&c.flags.slowTime, "slow-threshold", 0, "log a warning for any generation phase that takes longer than this duration (0 disables the warnings)")
+	cmd.Flags().StringSliceVar(&c.flags.verbs, "verbs", nil, "only keep operations for these Kubernetes verbs, e.g. get,list,watch (default all verbs)")
+	cmd.Flags().StringSliceVar(&c.flags.excludeVerbs, "exclude-verbs", nil, "remove operations for these Kubernetes verbs, e.g. create,patch,delete")
+	cmd.Flags().StringSliceVar(&c.flags.excludeSubs, "exclude-subresources", nil, "remove the paths for these subresources, e.g. status,scale")
+	cmd.Flags().BoolVar(&c.flags.subsOnly, "subresources-only", false, "only keep the paths for subresources")
+	cmd.Flags().BoolVar(&c.flags.defsOnly, "definitions-only", false, "only output the schema definitions of the kinds and the definitions they reference, without any paths")
+	cmd.Flags().StringSliceVar(&c.flags.lintRules, "lint-defaults", nil, fmt.Sprintf("warn about schema defaults that break conventions using these rules: %s or all", strings.Join(generator.LintRules, ", ")))
+	cmd.Flags().StringVar(&c.flags.findingsFormat, "findings-format", findingsText, "format of lint findings, either text (logged as warnings) or sarif (written to findings-file)")
+	cmd.Flags().StringVar(&c.flags.findingsFile, "findings-file", "", "location to write sarif lint findings")
+	cmd.Flags().StringVar(&c.flags.audienceMap, "audience-map", "", "YAML file assigning kinds and fields to the public, partner, or internal audience, a doc is written for each audience to the output-file with the audience added to its name")
+	cmd.Flags().BoolVarP(&c.flags.watch, "watch", "w", false, "keep the cluster running and regenerate the swagger doc whenever the local CRD files change")
+	cmd.Flags().BoolVar(&c.flags.keepContainer, "keep-container", false, "leave the cluster container running after the swagger doc is generated, under a name with a random suffix unless --reuse-container is set so later runs do not conflict with it")
+	cmd.Flags().StringVar(&c.flags.debugLogsDir, "debug-logs-dir", "", "save the cluster container's logs to this directory when the cluster fails instead of logging their last lines")
+	cmd.Flags().BoolVar(&c.flags.reuseContainer, "reuse-container", false, "reuse the cluster container from a previous run if one exists and leave it running afterwards")
+	cmd.Flags().BoolVar(&c.flags.persistCredentials, "persist-credentials", false, "write the docker cluster's kubeconfig to a file only readable by the current user and leave it after exiting")
+	cmd.Flags().BoolVar(&c.flags.readOnlyRootFS, "read-only-rootfs", false, "run the cluster container with a read-only root filesystem, the paths k3s writes to are mounted as volumes")
+	cmd.Flags().StringVar(&c.flags.seccompProfile, "seccomp-profile", "", "path to a seccomp profile JSON file, or unconfined, to apply to the cluster container")
+	cmd.Flags().StringVar(&c.flags.apparmorProfile, "apparmor-profile", "", "name of an AppArmor profile to apply to the cluster container")
+	cmd.Flags().BoolVar(&c.flags.noNewPrivileges, "no-new-privileges", false, "stop processes in the cluster container from gaining new privileges")
+	cmd.Flags().StringArrayVar(&c.flags.applyManifests, "apply-manifests", nil, "YAML file, directory, or remote file URL of objects (APIServices, operators, ...) to apply to the cluster after the CRDs are installed, can be repeated")
+	cmd.Flags().StringArrayVar(&c.flags.charts, "install-chart", nil, "Helm chart to install into the cluster after the CRDs are installed, in the form REPO_URL/NAME[@VERSION] or oci://REGISTRY/NAME[@VERSION], can be repeated")
+	cmd.Flags().StringVar(&c.flags.chartVersion, "chart-version", "", "version of the chart when a single chart is installed (default latest)")
+	cmd.Flags().DurationVar(&c.flags.manifestTime, "apply-manifests-timeout", 2*time.Minute, "how long to wait for applied manifests and installed charts to be ready")
+	cmd.Flags().StringSliceVar(&c.flags.k8sVersions, "k8s-versions", nil, "generate a swagger doc for each of these Kubernetes versions concurrently, each doc is written to the output-file with the version added to its name and support-matrix.json next to it records the kinds of every version")
+	cmd.Flags().IntVar(&c.flags.maxClusters, "max-parallel-clusters", 0, "most clusters to run at once when generating a version matrix (default one per 2 CPUs and 1GiB of memory of the host)")
+	cmd.Flags().IntVar(&c.flags.maxFilters, "max-parallel-filters", 0, "most docs to filter at once when generating a version matrix (default the host's CPUs)")
+	cmd.Flags().StringVar(&c.flags.restartPolicy, "restart-policy", "no", "docker restart policy for the cluster container, one of no, on-failure, unless-stopped, or always")
+	cmd.Flags().StringVar(&c.flags.host, "host", "", "host (and port) serving the API to set in the output, e.g. rancher.example.com")
+	cmd.Flags().StringVar(&c.flags.basePath, "base-path", "", "base path of the API to set in the output, e.g. /k8s/clusters/local")
+	cmd.Flags().StringSliceVar(&c.flags.schemes, "schemes", nil, "transfer protocols of the API to set in the output, e.g. https")
+	cmd.Flags().BoolVar(&c.flags.bearerAuth, "bearer-auth", false, "add a bearer token security definition that applies to every operation to the output")
+	cmd.Flags().StringVar(&c.flags.pathPrefix, "path-prefix", "", "prefix to add to every path in the output, {param} templates are documented as path parameters, e.g. /k8s/clusters/{clusterId}")
+	cmd.Flags().BoolVar(&c.flags.minVersions, "annotate-min-version", false, "set x-min-kubernetes-version on each CRD definition to the oldest Kubernetes version serving the CRD features its schema uses")
+	cmd.Flags().StringVar(&c.flags.cacheDir, "cache-dir", "", "directory to cache the cluster's full swagger doc in so reruns with the same image and CRDs skip starting a cluster (default the crd-swagger directory in the user cache directory)")
+	cmd.Flags().BoolVar(&c.flags.noCache, "no-cache", false, "do not read or write the swagger doc cache or the cache of remote inputs")
+	cmd.Flags().BoolVar(&c.flags.offlineInputs, "offline-inputs", false, "read remote CRD and resources file URLs only from the copies cached by earlier runs instead of downloading them")
+	cmd.Flags().BoolVar(&c.flags.rbac, "rbac-annotations", false, "set x-required-rbac on each operation to the apiGroup, resource, and verb of the RBAC rule needed to call it")
+	cmd.Flags().BoolVar(&c.flags.stevePaths, "steve-paths", false, "also document the Rancher Steve API (/v1/{type}) paths for each CRD")
+	cmd.Flags().StringArrayVar(&c.flags.postProcessors, "post-processor", nil, "executable to pass the swagger doc through as JSON on stdin and stdout before it is written, can be repeated to run several in order")
+	cmd.Flags().BoolVar(&c.flags.verifyDeterministic, "verify-deterministic", false, "generate the swagger doc twice using the same cluster and fail if the two docs differ")
+	cmd.Flags().BoolVar(&c.flags.resolveRefs, "resolve-refs", false, "inline every $ref into a self-contained doc, references to recursive definitions are kept")
+	cmd.Flags().BoolVar(&c.flags.canonical, "canonical", false, "sort every list in the doc whose order has no meaning, such as tags, parameters, and required properties, so committed docs only change when their content does")
+	cmd.Flags().StringVar(&c.flags.title, "title", "", "title of the API to set in the output (defaults to the API server's title)")
+	cmd.Flags().StringVar(&c.flags.docVersion, "doc-version", "", "version of the API to set in the output (defaults to the API server's version)")
+	cmd.Flags().StringVar(&c.flags.description, "description", "", "description of the API to set in the output")
+	cmd.Flags().StringVar(&c.flags.contactName, "contact-name", "", "name of the API's contact to set in the output")
+	cmd.Flags().StringVar(&c.flags.contactURL, "contact-url", "", "URL of the API's contact to set in the output")
+	cmd.Flags().StringVar(&c.flags.contactEmail, "contact-email", "", "email of the API's contact to set in the output")
+	cmd.Flags().StringVar(&c.flags.license, "license", "", "name of the API's license to set in the output, e.g. Apache 2.0")
+	cmd.Flags().StringVar(&c.flags.licenseURL, "license-url", "", "URL of the API's license to set in the output")
 }
 
-func run() (err error) {
-	// attempt to get the desired CRDs request by the users
-	zap.S().Info("Gathering CustomResourceDefinitions from source.")
-	crdMap, err := crdsFromInput(cmdFlags.crdSource)
-	if err != nil {
-		return fmt.Errorf("failed to get CRDs: %w", err)
-	}
-	if len(crdMap) == 0 {
-		return fmt.Errorf("no CRDs found at '%s'", cmdFlags.crdSource)
-	}
+// addClusterFlags adds the flags for the input CRDs and the cluster they are installed into, shared by every command that starts a cluster.
+func (c *generateCommand) addClusterFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&c.flags.crdSource, "files", "f", "", "location to find input CRD file/files, either a file path or a remote file URL")
+	flags.StringVar(&c.flags.aliasesFile, "aliases-file", "", "file of old and new Kind.group or *.group pairs, one per line, used for resources file entries that match nothing so entries from before a kind or group was renamed keep working")
+	flags.StringVar(&c.flags.resourcesFile, "resources-file", "", "file or URL listing the Kind.group of the input CRDs to document, one per line, the kind and group may be globs such as *.management.cattle.io or Cluster.*, and an entry may be pinned to a version such as Cluster.provisioning.cattle.io/v1, .yaml and .json files are read as a manifest with the versions, verbs, subresources, and tags of each resource (default all CRDs)")
+	flags.BoolVarP(&c.flags.recurse, "recurse", "r", false, "if files is a local directory recursively search for all CRDs")
+	flags.StringVar(&c.flags.goPackages, "from-go-module", "", "generate the input CRDs from the kubebuilder annotated Go types in these packages using controller-gen instead of reading files, e.g. ./pkg/apis/...")
+	flags.StringVar(&c.flags.controllerGen, "controller-gen", "controller-gen", "controller-gen binary used by from-go-module")
+	flags.StringVar(&c.flags.k3sPort, "cluster-port", "", "port to bind kubeapi-server to on the host machine (if unset a free port is used)")
+	flags.BoolVar(&c.flags.silent, "silent", false, "do not print any log messages")
+	flags.StringVar(&c.flags.logLevel, "log-level", "info", "minimum level of the log messages to print, one of debug, info, warn, or error")
+	flags.StringVar(&c.flags.logFile, "log-file", "", "file to append the log messages and image pull progress to instead of printing them, so the doc can be written to stdout")
+	flags.StringVar(&c.flags.logFormat, "log-format", logFormatText, "format of log messages, either text or json (one object per line with the phase, image, and containerID as fields, for log pipelines)")
+	flags.StringVar(&c.flags.engine, "engine", generator.EngineDocker, "backend used to run kube-apiserver, either docker (k3s container) or envtest (local kube-apiserver and etcd binaries)")
+	flags.DurationVar(&c.flags.pullTime, "pull-timeout", 10*time.Minute, "how long to wait for the cluster image to be pulled")
+	flags.DurationVar(&c.flags.readyTime, "cluster-ready-timeout", 15*time.Second, "how long to wait for the cluster to be ready")
+	flags.DurationVar(&c.flags.discoverTime, "discovery-timeout", 15*time.Second, "how long to wait for installed CRDs to be established and added to the swagger doc")
+	flags.IntVar(&c.flags.retries, "retries", 0, "retry generation from a new cluster up to this many times when it fails with a known flaky failure, such as an image pull cut off by the registry or a timeout while the cluster was starting")
+	flags.BoolVar(&c.flags.privileged, "privileged", false, "run the cluster container in privileged mode, only needed on hosts where k3s fails to start without it")
+	flags.StringVar(&c.flags.image, "image", "", fmt.Sprintf("k3s image to run the cluster with (default %s)", generator.DefaultImage))
+	flags.StringVar(&c.flags.k8sVersion, "k8s-version", "", fmt.Sprintf("Kubernetes version to run the cluster with instead of setting the image, a patch version such as v1.27.5 or one of %s", strings.Join(generator.KubernetesVersions(), ", ")))
+	flags.StringArrayVar(&c.flags.containerEnv, "container-env", nil, "KEY=VALUE environment variable to set in the cluster container, can be repeated")
+	flags.StringVar(&c.flags.k3sRegistries, "k3s-registries", "", fmt.Sprintf("k3s registries.yaml mounted at %s to configure the mirrors k3s pulls its system images from", generator.K3sRegistriesPath))
+	flags.StringVar(&c.flags.k3sCABundle, "k3s-ca-bundle", "", fmt.Sprintf("PEM CA bundle mounted at %s, reference it from registries.yaml with tls.ca_file to trust a mirror signed by a private CA", generator.K3sCABundlePath))
+	flags.StringSliceVar(&c.flags.featureGates, "feature-gates", nil, "Kubernetes feature gates to set in kube-apiserver, e.g. ValidatingAdmissionPolicy=true, needed for APIs that are only served when a feature is enabled")
+	flags.StringVar(&c.flags.registryAuth, "registry-auth", "", "username:password used to pull the image (defaults to the credentials in the docker config file)")
+	flags.StringVar(&c.flags.imageTar, "image-tar", "", "load the image from a tarball created by docker save instead of pulling it")
+	flags.StringVar(&c.flags.installOrder, "install-order", "", "file of Kind.group entries installed one line at a time, each line's CRDs are ready before the next line is installed and unlisted CRDs are installed last (default CRDs with conversion webhooks last)")
+	flags.StringVar(&c.flags.openAPIURL, "from-openapi-url", "", "filter the openapiv2 document served at this URL instead of starting a cluster, the CRDs must already be installed in the serving API server")
+	flags.StringVar(&c.flags.server, "server", "", "address of an existing API server to install the CRDs into instead of starting a cluster")
+	flags.StringVar(&c.flags.token, "token", "", "bearer token used to authenticate to the API server")
+	flags.StringVar(&c.flags.username, "username", "", "username for basic authentication to the API server")
+	flags.StringVar(&c.flags.password, "password", "", "password for basic authentication to the API server")
+	flags.StringVar(&c.flags.caFile, "ca-file", "", "path to a cert file for the certificate authority of the API server")
+	flags.BoolVar(&c.flags.insecure, "insecure-skip-tls-verify", false, "do not verify the API server's certificate")
+}
 
-	// convert the map of crds to a map of GroupKind and a list of crds to install
-	// the boolean value is used later on to identify if the desired GK was found in the path.
-	desiredGroupKinds := make(map[v1.GroupKind]bool, len(crdMap))
-	crdsToInstall := make([]*apiextv1.CustomResourceDefinition, 0, len(crdMap))
-	for _, crd := range crdMap {
-		crdsToInstall = append(crdsToInstall, crd)
-		gk := v1.GroupKind{
-			Group: crd.Spec.Group,
-			Kind:  crd.Spec.Names.Kind,
-		}
-		// add the CRDs GK to the map and initialize it to notFound aka false
-		desiredGroupKinds[gk] = false
+// generatorOptions converts the command flags to generator options.
+func (c *generateCommand) generatorOptions() generator.Options {
+	opts := generator.Options{
+		CRDSource:             c.flags.crdSource,
+		Recurse:               c.flags.recurse,
+		ResourcesFile:         c.flags.resourcesFile,
+		AliasesFile:           c.flags.aliasesFile,
+		InstallOrder:          c.flags.installOrder,
+		GoPackages:            c.flags.goPackages,
+		ControllerGen:         c.flags.controllerGen,
+		Engine:                c.flags.engine,
+		ClusterPort:           c.flags.k3sPort,
+		KeepContainer:         c.flags.keepContainer,
+		DebugLogsDir:          c.flags.debugLogsDir,
+		ReuseContainer:        c.flags.reuseContainer,
+		PersistCredentials:    c.flags.persistCredentials,
+		Privileged:            c.flags.privileged,
+		ReadOnlyRootFS:        c.flags.readOnlyRootFS,
+		SeccompProfile:        c.flags.seccompProfile,
+		AppArmorProfile:       c.flags.apparmorProfile,
+		NoNewPrivileges:       c.flags.noNewPrivileges,
+		Image:                 c.flags.image,
+		KubernetesVersion:     c.flags.k8sVersion,
+		ContainerEnv:          c.flags.containerEnv,
+		K3sRegistries:         c.flags.k3sRegistries,
+		K3sCABundle:           c.flags.k3sCABundle,
+		FeatureGates:          c.flags.featureGates,
+		ApplyManifests:        c.flags.applyManifests,
+		ManifestTimeout:       c.flags.manifestTime,
+		Charts:                c.flags.charts,
+		ChartVersion:          c.flags.chartVersion,
+		ImageTar:              c.flags.imageTar,
+		RegistryAuth:          c.flags.registryAuth,
+		RestartPolicy:         c.flags.restartPolicy,
+		SlowThreshold:         c.flags.slowTime,
+		PullTimeout:           c.flags.pullTime,
+		ClusterReadyTimeout:   c.flags.readyTime,
+		DiscoveryTimeout:      c.flags.discoverTime,
+		Retries:               c.flags.retries,
+		MaxParallelClusters:   c.flags.maxClusters,
+		MaxParallelFilters:    c.flags.maxFilters,
+		Verbs:                 c.flags.verbs,
+		ExcludeVerbs:          c.flags.excludeVerbs,
+		ExcludeSubresources:   c.flags.excludeSubs,
+		SubresourcesOnly:      c.flags.subsOnly,
+		DefinitionsOnly:       c.flags.defsOnly,
+		OpenAPIURL:            c.flags.openAPIURL,
+		Token:                 c.flags.token,
+		Server:                c.flags.server,
+		Username:              c.flags.username,
+		Password:              c.flags.password,
+		CAFile:                c.flags.caFile,
+		InsecureSkipTLSVerify: c.flags.insecure,
+		FlattenAllOf:          c.flags.flattenAllOf,
+		Anonymize:             c.flags.anonymize,
+		StripDescriptions:     c.flags.stripDescs,
+		StripExtensions:       c.flags.stripExts,
+		Host:                  c.flags.host,
+		BasePath:              c.flags.basePath,
+		Schemes:               c.flags.schemes,
+		BearerAuth:            c.flags.bearerAuth,
+		Info:                  c.infoProps(),
+		PathPrefix:            c.flags.pathPrefix,
+		VerifyDeterministic:   c.flags.verifyDeterministic,
+		Canonical:             c.flags.canonical,
+		ResolveRefs:           c.flags.resolveRefs,
+		StevePaths:            c.flags.stevePaths,
+		AnnotateMinVersions:   c.flags.minVersions,
+		PostProcessors:        c.flags.postProcessors,
+		ClusterProvider:       c.streams.ClusterProvider,
+		IgnoreMissing:         c.flags.ignoreMissing,
+		RBACAnnotations:       c.flags.rbac,
+		OfflineInputs:         c.flags.offlineInputs,
+		LogFields:             c.flags.logFormat == logFormatJSON,
 	}
-
-	zap.S().Info("Starting cluster in a docker container.")
-	// Start the cluster for installing the CRDs and getting the swagger doc
-	ctx := context.Background()
-	var cluster dockerCluster
-	err = cluster.start(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to start cluster: %w", err)
+	if !c.flags.silent {
+		opts.PullOutput = c.logOut
 	}
-	defer func() {
-		stopErr := cluster.stop(ctx)
-		if err == nil {
-			err = stopErr
+	if !c.flags.noCache {
+		opts.CacheDir = c.flags.cacheDir
+		if opts.CacheDir == "" {
+			opts.CacheDir = defaultCacheDir()
 		}
-	}()
-
-	zap.S().Info("Installing CRDs into the cluster.")
-	err = cluster.ensureCRD(ctx, crdsToInstall)
-	if err != nil {
-		return fmt.Errorf("failed to create CRDs: %w", err)
 	}
+	return opts
+}
 
-	// give k8s time to add newly installed CRDs to the swagger doc
-	time.Sleep(syncTime)
-
-	zap.S().Info("Creating new Swagger doc.")
-	// get the swagger doc from the crds
-	swagger, err := cluster.getSwagger()
+// defaultCacheDir returns the crd-swagger directory in the user's cache directory, or an empty string if it is unknown.
+func defaultCacheDir() string {
+	dir, err := os.UserCacheDir()
 	if err != nil {
-		return err
+		return ""
 	}
+	return filepath.Join(dir, "crd-swagger")
+}
 
-	keepPaths, err := getDesiredPaths(swagger, desiredGroupKinds)
-	if err != nil {
-		return err
+// infoProps converts the info flags to the overrides for the document's info block.
+func (c *generateCommand) infoProps() spec.InfoProps {
+	info := spec.InfoProps{
+		Title:       c.flags.title,
+		Version:     c.flags.docVersion,
+		Description: c.flags.description,
 	}
-
-	// remove all paths that are not for the desired CRDs
-	aggregator.FilterSpecByPaths(swagger, keepPaths)
-
-	err = writeDoc(swagger)
-	if err != nil {
-		return fmt.Errorf("failed to write swagger: %w", err)
+	if c.flags.contactName != "" || c.flags.contactURL != "" || c.flags.contactEmail != "" {
+		info.Contact = &spec.ContactInfo{Name: c.flags.contactName, URL: c.flags.contactURL, Email: c.flags.contactEmail}
 	}
-
-	zap.S().Info("Swagger created successfully!")
-	return nil
+	if c.flags.license != "" || c.flags.licenseURL != "" {
+		info.License = &spec.License{Name: c.flags.license, URL: c.flags.licenseURL}
+	}
+	return info
 }
 
-// getDesiredPaths gets a list of paths to keep by checking if the path specified in the swagger doc references any of the desiredGroupKinds.
-func getDesiredPaths(swagger *spec.Swagger, desiredGroupKinds map[v1.GroupKind]bool) ([]string, error) {
-	if swagger.Paths == nil {
-		return nil, fmt.Errorf("cluster's swagger doc has no paths set")
-	}
-	var keepPaths []string
-	for pathName, pathItem := range swagger.Paths.Paths {
-		gks := groupKindsFromPath(pathItem)
-		for i := range gks {
-			if _, ok := desiredGroupKinds[gks[i]]; ok {
-				keepPaths = append(keepPaths, pathName)
-				desiredGroupKinds[gks[i]] = true // set the GK as found
-				break
-			}
-		}
+func (c *generateCommand) run(ctx context.Context) error {
+	if c.flags.retries < 0 {
+		return fmt.Errorf("retries must not be negative")
+	}
+	if c.flags.offlineInputs && c.flags.noCache {
+		return fmt.Errorf("offline inputs are read from the cache and can not be used with no-cache")
+	}
+	if c.flags.rbacFile != "" && c.flags.defsOnly {
+		return fmt.Errorf("ClusterRoles are derived from the doc's paths and can not be written for a definitions only doc")
 	}
-	for gk, foundPath := range desiredGroupKinds {
-		if !foundPath {
-			return nil, fmt.Errorf("failed to find path for GroupKind %s", gk.String())
+	if len(c.flags.k8sVersions) != 0 {
+		return c.runMatrix(ctx)
+	}
+	write := c.output
+	if c.flags.audienceMap != "" {
+		write = c.outputAudiences
+	}
+	if c.flags.watch {
+		if c.flags.summaryFile != "" {
+			return fmt.Errorf("a summary file can not be written when watching")
 		}
+		ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		return generator.Watch(ctx, c.generatorOptions(), write)
+	}
+
+	opts := c.generatorOptions()
+	if c.flags.summaryFile == "" {
+		return c.generateAndWrite(ctx, opts, write)
 	}
-	return keepPaths, nil
+	summary := &runSummary{}
+	opts.Summary = &summary.Summary
+	err := c.generateAndWrite(ctx, opts, write)
+	if summaryErr := c.writeSummary(summary, err); summaryErr != nil && err == nil {
+		return summaryErr
+	}
+	return err
 }
 
-func writeDoc(swagger *spec.Swagger) error {
-	var outData []byte
-	var err error
-	if cmdFlags.prettyPrint {
-		outData, err = json.MarshalIndent(swagger, "", "  ")
-		if err != nil {
-			return fmt.Errorf("failed to marshal swagger: %w", err)
-		}
-	} else {
-		outData, err = json.Marshal(swagger)
-		if err != nil {
-			return fmt.Errorf("failed to marshal swagger: %w", err)
-		}
+// generateAndWrite generates the swagger doc and writes it with write.
+func (c *generateCommand) generateAndWrite(ctx context.Context, opts generator.Options, write func(*spec.Swagger) error) error {
+	swagger, err := generator.Generate(ctx, opts)
+	if swagger == nil {
+		return err
 	}
-	if cmdFlags.outputFile == "" {
-		outData = append(outData, '\n')
-		_, err := os.Stdout.Write(outData)
-		if err != nil {
-			return fmt.Errorf("failed to write swagger to stdout: %w", err)
-		}
-		return nil
+	// with ignore-missing the doc is written and the missing GroupKinds are still reported
+	if writeErr := write(swagger); writeErr != nil {
+		return writeErr
 	}
-	err = os.WriteFile(cmdFlags.outputFile, outData, 0600)
 	if err != nil {
-		return fmt.Errorf("failed to write swagger doc: %w", err)
+		return &partialDocError{err: err}
 	}
 	return nil
 }
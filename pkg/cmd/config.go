@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+)
+
+// configHeader starts the config files written by init.
+const configHeader = "# crd-swagger config, the keys are the flag names of crd-swagger and flags set on the command line take precedence\n"
+
+// applyConfig sets the flags listed in the config file, if one is set, that were not set on the command line.
+// The config file is a YAML map of flag names to values, lists set flags that can be repeated once per item.
+func (c *generateCommand) applyConfig(flags *pflag.FlagSet) error {
+	if c.flags.configFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(c.flags.configFile)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	var config map[string]interface{}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return fmt.Errorf("failed to decode config file '%s': %w", c.flags.configFile, err)
+	}
+	names := make([]string, 0, len(config))
+	for name := range config {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		flag := flags.Lookup(name)
+		if flag == nil || name == "config" {
+			return fmt.Errorf("unknown option '%s' in config file '%s'", name, c.flags.configFile)
+		}
+		if flag.Changed {
+			continue
+		}
+		values, ok := config[name].([]interface{})
+		if !ok {
+			values = []interface{}{config[name]}
+		}
+		for _, value := range values {
+			if err := flags.Set(name, fmt.Sprint(value)); err != nil {
+				return fmt.Errorf("invalid option '%s' in config file '%s': %w", name, c.flags.configFile, err)
+			}
+		}
+	}
+	return nil
+}
+
+// writeConfig writes the flag values to a config file read by applyConfig.
+func writeConfig(file string, config map[string]interface{}) error {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	if err := os.WriteFile(file, append([]byte(configHeader), data...), 0600); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/KevinJoiner/crd-swagger/pkg/daemon"
+	"github.com/KevinJoiner/crd-swagger/pkg/generator"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+type daemonFlagVar struct {
+	listen string
+}
+
+// daemonCommand holds the state of a daemon command.
+type daemonCommand struct {
+	cluster *generateCommand
+	flags   daemonFlagVar
+}
+
+// newDaemonCommand returns the command that serves swagger generation over gRPC with a running cluster.
+func newDaemonCommand() *cobra.Command {
+	c := &daemonCommand{cluster: newClusterCommand()}
+	cmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Serve swagger generation over gRPC",
+		Long: `Starts the cluster (or connects to an existing API server) and keeps it running while serving the
+crdswagger.v1.Generator gRPC service described in pkg/daemon/daemon.proto. Each Generate call installs the CRDs
+it is sent and returns their swagger doc, so editors can regenerate docs without waiting for a cluster to start.
+The cluster is stopped on SIGINT or SIGTERM.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.cluster.setupLogger(); err != nil {
+				return err
+			}
+			defer zap.L().Sync()
+			return c.run(c.cluster.generatorOptions())
+		},
+	}
+	c.cluster.addClusterFlags(cmd.Flags())
+	cmd.Flags().StringVar(&c.flags.listen, "listen", "127.0.0.1:50051", "address to serve the gRPC API on")
+	return cmd
+}
+
+func (c *daemonCommand) run(opts generator.Options) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	listener, err := net.Listen("tcp", c.flags.listen)
+	if err != nil {
+		return fmt.Errorf("failed to listen on '%s': %w", c.flags.listen, err)
+	}
+	session, err := generator.NewSession(ctx, opts)
+	if err != nil {
+		_ = listener.Close()
+		return err
+	}
+	zap.S().Infof("Serving %s on %s.", daemon.ServiceName, listener.Addr())
+	serveErr := daemon.Serve(ctx, listener, session)
+	if err := session.Close(context.Background()); err != nil {
+		zap.S().Errorf("Failed to stop the cluster: %v", err)
+	}
+	return serveErr
+}
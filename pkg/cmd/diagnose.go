@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/KevinJoiner/crd-swagger/pkg/generator"
+	"github.com/KevinJoiner/crd-swagger/pkg/render"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+type diagnoseFlagVar struct {
+	findingsFile string
+}
+
+// diagnoseCommand holds the state of a diagnose command.
+type diagnoseCommand struct {
+	cluster *generateCommand
+	flags   diagnoseFlagVar
+}
+
+// newDiagnoseCommand returns the command that reports problems with CRD files at their lines.
+func newDiagnoseCommand() *cobra.Command {
+	c := &diagnoseCommand{cluster: newClusterCommand()}
+	cmd := &cobra.Command{
+		Use:   "diagnose PATH",
+		Short: "Report problems with CRD files at their lines",
+		Long: `Starts the cluster (or connects to an existing API server) and installs the CRDs in the YAML and JSON files at PATH,
+a file or a directory searched recursively with recurse, one at a time. Every problem the API server reports installing a CRD, such as a non-structural
+schema, and every field without a description is reported at the line of the field in its file, and written as SARIF
+to findings-file for code scanning tools. The daemon serves the same diagnostics with its Diagnose method.
+Exits non-zero when there are any problems.`,
+		Args: cobra.ExactArgs(1),
+		// problems fail the command but are not a usage error
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.cluster.setupLogger(); err != nil {
+				return err
+			}
+			defer zap.L().Sync()
+			return c.run(cmd, args[0])
+		},
+	}
+	c.cluster.addClusterFlags(cmd.Flags())
+	cmd.Flags().StringVar(&c.flags.findingsFile, "findings-file", "", "location to write the problems as sarif")
+	return cmd
+}
+
+func (c *diagnoseCommand) run(cmd *cobra.Command, path string) error {
+	session, err := generator.NewSession(cmd.Context(), c.cluster.generatorOptions())
+	if err != nil {
+		return err
+	}
+	diagnostics, err := session.DiagnosePath(cmd.Context(), path, c.cluster.flags.recurse)
+	if closeErr := session.Close(cmd.Context()); closeErr != nil {
+		zap.S().Errorf("Failed to stop the cluster: %v", closeErr)
+	}
+	if err != nil {
+		return err
+	}
+	for _, diagnostic := range diagnostics {
+		fmt.Println(diagnostic.String())
+	}
+	if c.flags.findingsFile != "" {
+		data, err := render.DiagnosticsSARIF(diagnostics)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(c.flags.findingsFile, data, 0600); err != nil {
+			return fmt.Errorf("failed to write findings: %w", err)
+		}
+	}
+	if len(diagnostics) != 0 {
+		return fmt.Errorf("found %d problems", len(diagnostics))
+	}
+	return nil
+}
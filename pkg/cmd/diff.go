@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/KevinJoiner/crd-swagger/pkg/generator"
+	"github.com/KevinJoiner/crd-swagger/pkg/render"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const (
+	diffText      = "text"
+	diffPRComment = "pr-comment"
+)
+
+type diffFlagVar struct {
+	breakingOnly   bool
+	format         string
+	findingsFormat string
+	findingsFile   string
+}
+
+// diffCommand holds the state of a diff command, so the archive diff command has flags of its own.
+type diffCommand struct {
+	flags diffFlagVar
+}
+
+// newDiffCommand returns the command that compares two swagger docs.
+func newDiffCommand() *cobra.Command {
+	c := &diffCommand{}
+	cmd := &cobra.Command{
+		Use:   "diff OLD NEW",
+		Short: "Show the changes between two swagger docs",
+		Long: `Shows the paths, operations, definitions, and fields that changed between two swagger docs.
+With breaking-only just the changes that break clients of the old doc are shown (removed paths, removed fields,
+newly required fields, type changes, and enum narrowing) and the command exits non-zero when there are any.
+The pr-comment format renders a Markdown summary with a collapsible section per kind that fits in a GitHub comment.
+With the sarif findings format the breaking changes are also written to findings-file for code scanning tools.`,
+		Args: cobra.ExactArgs(2),
+		// breaking changes fail the command but are not a usage error
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.run(args[0], args[1])
+		},
+	}
+	c.addFlags(cmd.Flags())
+	return cmd
+}
+
+func (c *diffCommand) addFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(&c.flags.breakingOnly, "breaking-only", false, "only show breaking changes and fail if there are any")
+	flags.StringVar(&c.flags.format, "format", diffText, "format of the changes, either text (a line per change) or pr-comment (a Markdown summary for a pull request comment)")
+	flags.StringVar(&c.flags.findingsFormat, "findings-format", findingsText, "format of breaking change findings, either text (only shown with the changes) or sarif (written to findings-file)")
+	flags.StringVar(&c.flags.findingsFile, "findings-file", "", "location to write sarif breaking change findings")
+}
+
+func (c *diffCommand) run(oldFile, newFile string) error {
+	if c.flags.format != diffText && c.flags.format != diffPRComment {
+		return fmt.Errorf("unknown diff format '%s' must be %s or %s", c.flags.format, diffText, diffPRComment)
+	}
+	switch c.flags.findingsFormat {
+	case findingsText:
+	case findingsSARIF:
+		if c.flags.findingsFile == "" {
+			return fmt.Errorf("findings-file must be set to write sarif findings")
+		}
+	default:
+		return fmt.Errorf("unknown findings format '%s' must be one of [%s, %s]", c.flags.findingsFormat, findingsText, findingsSARIF)
+	}
+	oldSwagger, err := readSwagger(oldFile)
+	if err != nil {
+		return err
+	}
+	newSwagger, err := readSwagger(newFile)
+	if err != nil {
+		return err
+	}
+
+	var changes []generator.Change
+	var findings []generator.Finding
+	for _, change := range generator.DiffSwagger(oldSwagger, newSwagger) {
+		if change.Breaking() {
+			findings = append(findings, generator.Finding{Rule: change.Rule, Path: change.Path, Message: change.Message})
+		} else if c.flags.breakingOnly {
+			continue
+		}
+		changes = append(changes, change)
+	}
+	if c.flags.format == diffPRComment {
+		fmt.Print(string(render.PRComment(changes)))
+	} else {
+		for _, change := range changes {
+			fmt.Println(change.String())
+		}
+	}
+	if c.flags.findingsFormat == findingsSARIF {
+		data, err := render.SARIF(findings, newFile)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(c.flags.findingsFile, data, 0600); err != nil {
+			return fmt.Errorf("failed to write findings: %w", err)
+		}
+	}
+	if c.flags.breakingOnly && len(findings) != 0 {
+		return fmt.Errorf("found %d breaking changes", len(findings))
+	}
+	return nil
+}
+
+// readSwagger reads the swagger doc in the JSON file.
+func readSwagger(file string) (*spec.Swagger, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read swagger doc: %w", err)
+	}
+	swagger := &spec.Swagger{}
+	if err := json.Unmarshal(data, swagger); err != nil {
+		return nil, fmt.Errorf("failed to parse swagger doc '%s': %w", file, err)
+	}
+	return swagger, nil
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/KevinJoiner/crd-swagger/pkg/generator"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"k8s.io/client-go/rest"
+)
+
+// GenerateCommandOptions configures the generate command for embedding in another CLI.
+type GenerateCommandOptions struct {
+	// Use is the one line usage of the command, defaults to "generate".
+	Use string
+	// Out receives the swagger doc when no output file is set, defaults to os.Stdout.
+	Out io.Writer
+	// LogOut receives the log messages and image pull progress, defaults to os.Stdout.
+	// It is not used when Logger is set.
+	LogOut io.Writer
+	// Logger replaces the logger configured from the command's flags.
+	Logger *zap.Logger
+	// ClusterProvider returns the rest config of an existing cluster to install the CRDs into instead of starting a cluster,
+	// e.g. the cluster the embedding CLI is logged in to.
+	ClusterProvider func(ctx context.Context) (*rest.Config, error)
+}
+
+// Exit codes of the commands, any other failure such as invalid flags or input exits with 1.
+const (
+	// ExitMissingGroupKinds is the exit code when the swagger doc was written without some of the GroupKinds.
+	ExitMissingGroupKinds = 2
+	// ExitMissingResources is the exit code when no doc was written because the cluster does not serve some of
+	// the requested GroupKinds.
+	ExitMissingResources = 3
+	// ExitInfrastructure is the exit code when the cluster could not be started, reached, or have the CRDs installed.
+	ExitInfrastructure = 4
+)
+
+// partialDocError is returned when the swagger doc was written without some of the GroupKinds.
+type partialDocError struct {
+	err error
+}
+
+func (e *partialDocError) Error() string { return e.err.Error() }
+
+func (e *partialDocError) Unwrap() error { return e.err }
+
+// ExitCode returns the process exit code for an error returned by the commands.
+func ExitCode(err error) int {
+	var partial *partialDocError
+	var missing *generator.MissingGroupKindsError
+	var stageErr *generator.StageError
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &partial):
+		return ExitMissingGroupKinds
+	case errors.As(err, &missing):
+		return ExitMissingResources
+	case errors.As(err, &stageErr) && stageErr.Infrastructure():
+		return ExitInfrastructure
+	default:
+		return 1
+	}
+}
+
+// generateCommand is the state of a command created by NewGenerateCommand, or of another command using the
+// cluster flags, so each command has flag values and IO streams of its own.
+type generateCommand struct {
+	flags flagVar
+	// streams are the IO streams and providers injected by NewGenerateCommand.
+	streams GenerateCommandOptions
+	// logOut receives the log messages and image pull progress once the logger is set up,
+	// the log file if one is set, otherwise streams.LogOut.
+	logOut io.Writer
+}
+
+// newClusterCommand returns the state of a command that is not a generate command, such as list, with the
+// default IO streams. The logging flags start at their defaults so commands without them log the same way.
+func newClusterCommand() *generateCommand {
+	return &generateCommand{
+		flags:   flagVar{logLevel: "info", logFormat: logFormatText},
+		streams: GenerateCommandOptions{Out: os.Stdout, LogOut: os.Stdout},
+	}
+}
+
+// NewGenerateCommand returns the command that generates the swagger doc with every generation flag,
+// so other CLIs can mount it as a subcommand, e.g. `rancher api-docs generate`.
+// Each command has flag values of its own so several can be created in one process.
+func NewGenerateCommand(opts GenerateCommandOptions) *cobra.Command {
+	if opts.Use == "" {
+		opts.Use = "generate"
+	}
+	if opts.Out == nil {
+		opts.Out = os.Stdout
+	}
+	if opts.LogOut == nil {
+		opts.LogOut = os.Stdout
+	}
+	c := &generateCommand{streams: opts}
+	cmd := &cobra.Command{
+		Use:   opts.Use,
+		Short: "Generate a swagger doc for CRDs",
+		Long:  `Generates a Swagger (openapiv2) document for Custom Resource Definitions (CRDs) installed and accessed through kube-apiserver.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.applyConfig(cmd.Flags()); err != nil {
+				return err
+			}
+			if err := c.setupLogger(); err != nil {
+				return err
+			}
+			defer zap.L().Sync()
+			err := c.run(cmd.Context())
+			if code := ExitCode(err); code != 1 {
+				// missing GroupKinds and cluster failures are not a usage error
+				cmd.SilenceUsage = true
+			}
+			return err
+		},
+	}
+	c.addFlags(cmd)
+	return cmd
+}
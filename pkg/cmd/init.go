@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/KevinJoiner/crd-swagger/pkg/generator"
+	"github.com/spf13/cobra"
+)
+
+const (
+	inputCRDs      = "crds"
+	inputResources = "resources"
+	inputChart     = "chart"
+
+	backendServer     = "server"
+	backendOpenAPIURL = "openapi-url"
+
+	// rancherChart is the chart suggested when the input is a chart.
+	rancherChart = "https://releases.rancher.com/server-charts/latest/rancher"
+	// defaultConfigFile is the config file written by init unless another is chosen.
+	defaultConfigFile = "crd-swagger.yaml"
+)
+
+// newInitCommand returns the command that interactively writes a config file for generate, the generate command
+// is run with the config file when the first generation is requested.
+func newInitCommand(generate *cobra.Command) *cobra.Command {
+	return &cobra.Command{
+		Use:   "init",
+		Short: "Interactively write a config file for generating docs",
+		Long: `Asks for the input (CRDs, a resources list, or a Helm chart), the backend running kube-apiserver, the Rancher version,
+and the output, then writes a config file used with --config. The first doc can be generated right away.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			wizard := prompter{in: bufio.NewReader(cmd.InOrStdin()), out: cmd.OutOrStdout()}
+			file, run, err := runInit(wizard)
+			if err != nil || !run {
+				return err
+			}
+			if err := generate.Flags().Set("config", file); err != nil {
+				return err
+			}
+			return generate.RunE(generate, nil)
+		},
+	}
+}
+
+// runInit asks the wizard's questions and writes the config file. Returns the config file and whether the first
+// generation was requested.
+func runInit(wizard prompter) (string, bool, error) {
+	config := map[string]interface{}{}
+	input, err := wizard.choose("Input type", []string{inputCRDs, inputResources, inputChart}, inputCRDs)
+	if err != nil {
+		return "", false, err
+	}
+	switch input {
+	case inputCRDs:
+		if err := wizard.setRequired(config, "files", "CRD file, directory, or URL"); err != nil {
+			return "", false, err
+		}
+		if info, err := os.Stat(config["files"].(string)); err == nil && info.IsDir() {
+			if err := wizard.setConfirm(config, "recurse", "Search subdirectories for CRDs", false); err != nil {
+				return "", false, err
+			}
+		}
+	case inputResources:
+		if err := wizard.setRequired(config, "resources-file", "Resources file or URL listing the Kind.group of the kinds to document"); err != nil {
+			return "", false, err
+		}
+		if err := wizard.set(config, "files", "CRD file, directory, or URL (blank to only document built-in kinds)", ""); err != nil {
+			return "", false, err
+		}
+	case inputChart:
+		chart, err := wizard.ask("Helm chart, REPO_URL/NAME or oci://REGISTRY/NAME", rancherChart)
+		if err != nil {
+			return "", false, err
+		}
+		config["install-chart"] = []string{chart}
+	}
+
+	backends := []string{generator.EngineDocker, generator.EngineEnvtest, backendServer}
+	if input != inputChart {
+		// charts can not be installed into the cluster serving an openapi URL
+		backends = append(backends, backendOpenAPIURL)
+	}
+	backend, err := wizard.choose("Backend running kube-apiserver", backends, generator.EngineDocker)
+	if err != nil {
+		return "", false, err
+	}
+	switch backend {
+	case generator.EngineDocker:
+		question := fmt.Sprintf("Kubernetes version, a patch version or one of %s (blank for the default)", strings.Join(generator.KubernetesVersions(), ", "))
+		if err := wizard.set(config, "k8s-version", question, ""); err != nil {
+			return "", false, err
+		}
+	case generator.EngineEnvtest:
+		config["engine"] = generator.EngineEnvtest
+	case backendServer:
+		if err := wizard.setRequired(config, "server", "API server address (pass --token or --username and --password when generating)"); err != nil {
+			return "", false, err
+		}
+	case backendOpenAPIURL:
+		if err := wizard.setRequired(config, "from-openapi-url", "URL of the openapiv2 doc"); err != nil {
+			return "", false, err
+		}
+	}
+
+	rancherVersion, err := wizard.ask("Rancher version the docs are for (blank to skip)", "")
+	if err != nil {
+		return "", false, err
+	}
+	if rancherVersion != "" {
+		config["doc-version"] = rancherVersion
+		if input == inputChart {
+			config["chart-version"] = rancherVersion
+		}
+	}
+
+	format, err := wizard.choose("Output format", []string{formatJSON, formatHTML, formatMD, formatTS, formatGo}, formatJSON)
+	if err != nil {
+		return "", false, err
+	}
+	config["output-format"] = format
+	outputFile := map[string]string{formatJSON: "swagger.json", formatHTML: "swagger.html", formatMD: "docs", formatTS: "types.ts", formatGo: "types.go"}[format]
+	if err := wizard.set(config, "output-file", "Output file", outputFile); err != nil {
+		return "", false, err
+	}
+	if format == formatJSON {
+		if err := wizard.setConfirm(config, "pretty-print", "Pretty print the doc", true); err != nil {
+			return "", false, err
+		}
+	}
+
+	file, err := wizard.ask("Config file", defaultConfigFile)
+	if err != nil {
+		return "", false, err
+	}
+	if _, err := os.Stat(file); err == nil {
+		overwrite, err := wizard.confirm(fmt.Sprintf("'%s' exists, overwrite it", file), false)
+		if err != nil {
+			return "", false, err
+		}
+		if !overwrite {
+			return "", false, fmt.Errorf("config file '%s' already exists", file)
+		}
+	}
+	if err := writeConfig(file, config); err != nil {
+		return "", false, err
+	}
+	fmt.Fprintf(wizard.out, "Wrote '%s', generate docs with: crd-swagger --config %s\n", file, file)
+
+	run, err := wizard.confirm("Generate the doc now", true)
+	return file, run, err
+}
+
+// prompter asks questions on out and reads the answers from in.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// ask returns the answer to the question, or def if the answer is blank.
+func (p prompter) ask(question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+	answer, err := p.in.ReadString('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || answer == "") {
+		if errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("input ended before init finished")
+		}
+		return "", fmt.Errorf("failed to read answer: %w", err)
+	}
+	if answer = strings.TrimSpace(answer); answer != "" {
+		return answer, nil
+	}
+	return def, nil
+}
+
+// choose asks the question until the answer is one of the options.
+func (p prompter) choose(question string, options []string, def string) (string, error) {
+	for {
+		answer, err := p.ask(fmt.Sprintf("%s (%s)", question, strings.Join(options, ", ")), def)
+		if err != nil {
+			return "", err
+		}
+		for _, option := range options {
+			if answer == option {
+				return answer, nil
+			}
+		}
+		fmt.Fprintf(p.out, "'%s' must be one of [%s]\n", answer, strings.Join(options, ", "))
+	}
+}
+
+// confirm asks the yes or no question until it is answered.
+func (p prompter) confirm(question string, def bool) (bool, error) {
+	defAnswer := "y/N"
+	if def {
+		defAnswer = "Y/n"
+	}
+	for {
+		answer, err := p.ask(fmt.Sprintf("%s? (%s)", question, defAnswer), "")
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		fmt.Fprintln(p.out, "answer y or n")
+	}
+}
+
+// set sets the flag in config to the answer to the question, unless the answer is blank.
+func (p prompter) set(config map[string]interface{}, flag, question, def string) error {
+	answer, err := p.ask(question, def)
+	if err == nil && answer != "" {
+		config[flag] = answer
+	}
+	return err
+}
+
+// setRequired sets the flag in config to the answer to the question, asking again while the answer is blank.
+func (p prompter) setRequired(config map[string]interface{}, flag, question string) error {
+	for config[flag] == nil {
+		if err := p.set(config, flag, question, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setConfirm sets the flag in config to the answer to the yes or no question, if it is not the flag's default of false.
+func (p prompter) setConfirm(config map[string]interface{}, flag, question string, def bool) error {
+	answer, err := p.confirm(question, def)
+	if err == nil && answer {
+		config[flag] = true
+	}
+	return err
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/KevinJoiner/crd-swagger/pkg/generator"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+type listFlagVar struct {
+	group string
+}
+
+// listCommand holds the state of a list command.
+type listCommand struct {
+	cluster *generateCommand
+	flags   listFlagVar
+}
+
+// newListCommand returns the command that lists the kinds served by the cluster.
+func newListCommand() *cobra.Command {
+	c := &listCommand{cluster: newClusterCommand()}
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the kinds served by the cluster",
+		Long: `Starts the cluster (or connects to an existing API server), installs the input CRDs if any are given,
+and prints the Kind.group of every kind in the cluster's openapi document.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.cluster.setupLogger(); err != nil {
+				return err
+			}
+			defer zap.L().Sync()
+			return c.run(cmd.Context(), c.cluster.generatorOptions())
+		},
+	}
+	c.cluster.addClusterFlags(cmd.Flags())
+	cmd.Flags().StringVar(&c.flags.group, "group", "", "only list kinds in groups matching this glob, e.g. *.cattle.io")
+	return cmd
+}
+
+func (c *listCommand) run(ctx context.Context, opts generator.Options) error {
+	if c.flags.group != "" {
+		if _, err := path.Match(c.flags.group, ""); err != nil {
+			return fmt.Errorf("invalid group glob '%s': %w", c.flags.group, err)
+		}
+	}
+	groupKinds, err := generator.ListGroupKinds(ctx, opts)
+	if err != nil {
+		return err
+	}
+	for _, gk := range groupKinds {
+		if c.flags.group != "" {
+			if matched, _ := path.Match(c.flags.group, gk.Group); !matched {
+				continue
+			}
+		}
+		fmt.Println(gk.String())
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/KevinJoiner/crd-swagger/pkg/generator"
+	"github.com/KevinJoiner/crd-swagger/pkg/render"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// supportMatrixName is the file next to the output files of a version matrix that records which kinds each
+// version documents.
+const supportMatrixName = "support-matrix.json"
+
+// runMatrix generates a swagger doc for each requested Kubernetes version and writes each doc
+// to its own output file named after the version, along with the support matrix of every version's kinds.
+func (c *generateCommand) runMatrix(ctx context.Context) error {
+	if c.flags.outputFile == "" {
+		return fmt.Errorf("an output file is required when generating a version matrix")
+	}
+	if c.flags.watch {
+		return fmt.Errorf("watch can not be used when generating a version matrix")
+	}
+	if c.flags.audienceMap != "" {
+		return fmt.Errorf("an audience map can not be used when generating a version matrix")
+	}
+	if c.flags.summaryFile != "" {
+		return fmt.Errorf("a summary file can not be written when generating a version matrix")
+	}
+	docs, genErr := generator.GenerateMatrix(ctx, c.generatorOptions(), c.flags.k8sVersions)
+
+	outputFile, badgeFile, findingsFile, rbacFile := c.flags.outputFile, c.flags.badgeFile, c.flags.findingsFile, c.flags.rbacFile
+	defer func() {
+		c.flags.outputFile, c.flags.badgeFile, c.flags.findingsFile, c.flags.rbacFile = outputFile, badgeFile, findingsFile, rbacFile
+	}()
+	for _, version := range sortedKeys(docs) {
+		c.flags.outputFile = versionedPath(outputFile, version)
+		c.flags.badgeFile = versionedPath(badgeFile, version)
+		c.flags.findingsFile = versionedPath(findingsFile, version)
+		c.flags.rbacFile = versionedPath(rbacFile, version)
+		if err := c.output(docs[version]); err != nil {
+			return fmt.Errorf("failed to output swagger doc for Kubernetes %s: %w", version, err)
+		}
+	}
+	if len(docs) != 0 {
+		if err := writeSupportMatrix(filepath.Join(filepath.Dir(outputFile), supportMatrixName), docs); err != nil {
+			return err
+		}
+	}
+	if genErr != nil && len(docs) == len(uniqueVersions(c.flags.k8sVersions)) {
+		// with ignore-missing a doc is written for every version and the missing GroupKinds are still reported
+		return &partialDocError{err: genErr}
+	}
+	return genErr
+}
+
+// uniqueVersions returns the versions without duplicates.
+func uniqueVersions(versions []string) map[string]bool {
+	unique := make(map[string]bool, len(versions))
+	for _, version := range versions {
+		unique[version] = true
+	}
+	return unique
+}
+
+func writeSupportMatrix(path string, docs map[string]*spec.Swagger) error {
+	data, err := render.SupportMatrix(docs)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write support matrix: %w", err)
+	}
+	return nil
+}
+
+// versionedPath inserts the version into path before its extension, e.g. swagger.json becomes swagger-v1.27.json.
+// Paths without an extension, such as markdown output directories, get the version appended.
+func versionedPath(path, version string) string {
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	return suffixedPath(path, version)
+}
+
+// suffixedPath inserts the suffix into path before its extension, e.g. swagger.json becomes swagger-public.json.
+func suffixedPath(path, suffix string) string {
+	if path == "" {
+		return ""
+	}
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "-" + suffix + ext
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUniqueVersions(t *testing.T) {
+	got := uniqueVersions([]string{"v1.27.1", "v1.26.5", "v1.27.1"})
+	want := map[string]bool{"v1.27.1": true, "v1.26.5": true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("uniqueVersions() = %v, want %v", got, want)
+	}
+}
+
+func TestVersionedPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		version string
+		want    string
+	}{
+		{path: "swagger.json", version: "v1.27.1", want: "swagger-v1.27.1.json"},
+		{path: "swagger.json", version: "1.27.1", want: "swagger-v1.27.1.json"},
+		{path: "out/docs", version: "v1.27.1", want: "out/docs-v1.27.1"},
+		{path: "", version: "v1.27.1", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path+" "+tt.version, func(t *testing.T) {
+			if got := versionedPath(tt.path, tt.version); got != tt.want {
+				t.Errorf("versionedPath(%s, %s) = %s, want %s", tt.path, tt.version, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/KevinJoiner/crd-swagger/pkg/render"
+	"go.uber.org/zap"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const notifyTimeout = time.Second * 10
+
+// notifyWebhook posts a summary of the generated swagger doc to the webhook in the Slack incoming webhook format.
+// Failures are only logged since the swagger doc has already been written.
+func (c *generateCommand) notifyWebhook(swagger *spec.Swagger) {
+	payload, err := json.Marshal(map[string]string{"text": c.notifySummary(swagger)})
+	if err != nil {
+		zap.S().Warnf("Failed to marshal webhook notification: %v", err)
+		return
+	}
+	client := http.Client{Timeout: notifyTimeout}
+	resp, err := client.Post(c.flags.notifyWebhook, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		zap.S().Warnf("Failed to send webhook notification: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		zap.S().Warnf("Webhook notification was rejected: %s", resp.Status)
+	}
+}
+
+// notifySummary describes the generated doc in a short human readable message.
+func (c *generateCommand) notifySummary(swagger *spec.Swagger) string {
+	var builder strings.Builder
+	kinds := render.DocumentedGroupKinds(swagger)
+	builder.WriteString("Generated swagger")
+	if swagger.Info != nil && swagger.Info.Version != "" {
+		fmt.Fprintf(&builder, " from Kubernetes %s", swagger.Info.Version)
+	}
+	fmt.Fprintf(&builder, " documenting %d kinds", len(kinds))
+	if c.flags.outputFile != "" {
+		fmt.Fprintf(&builder, " to %s", c.docPath())
+	}
+	builder.WriteString(".")
+	for _, gk := range kinds {
+		fmt.Fprintf(&builder, "\n• %s", gk.String())
+	}
+	return builder.String()
+}
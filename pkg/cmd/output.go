@@ -0,0 +1,312 @@
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KevinJoiner/crd-swagger/pkg/generator"
+	"github.com/KevinJoiner/crd-swagger/pkg/render"
+	"go.uber.org/zap"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"sigs.k8s.io/yaml"
+)
+
+// output writes the generated swagger doc.
+func (c *generateCommand) output(swagger *spec.Swagger) error {
+	if c.flags.validate {
+		if err := validateDoc(swagger); err != nil {
+			return err
+		}
+	}
+	if len(c.flags.lintRules) != 0 {
+		findings, err := generator.LintDefaults(swagger, c.flags.lintRules)
+		if err != nil {
+			return err
+		}
+		if err := c.writeFindings(findings); err != nil {
+			return err
+		}
+	}
+
+	writeStart := time.Now()
+	err := c.writeDoc(swagger)
+	if err != nil {
+		return fmt.Errorf("failed to write swagger: %w", err)
+	}
+	if c.flags.badgeFile != "" {
+		if err := c.writeBadge(swagger); err != nil {
+			return err
+		}
+	}
+	if c.flags.rbacFile != "" {
+		if err := c.writeRBAC(swagger); err != nil {
+			return err
+		}
+	}
+	generator.LogPhaseTiming("write", writeStart, c.flags.slowTime)
+
+	if c.flags.notifyWebhook != "" {
+		c.notifyWebhook(swagger)
+	}
+
+	zap.S().Info("Swagger created successfully!")
+	return nil
+}
+
+// marshalDoc converts the swagger doc to the requested output format.
+func (c *generateCommand) marshalDoc(swagger *spec.Swagger) ([]byte, error) {
+	switch c.flags.outputFormat {
+	case formatJSON:
+		if c.flags.prettyPrint {
+			return json.MarshalIndent(swagger, "", "  ")
+		}
+		return json.Marshal(swagger)
+	case formatHTML:
+		return render.HTML(swagger, c.flags.redocScript)
+	case formatTS:
+		return render.TypeScript(swagger)
+	case formatGo:
+		return render.Go(swagger, c.flags.goTypesPackage)
+	case formatMD:
+		pages, err := render.Markdown(swagger)
+		if err != nil {
+			return nil, err
+		}
+		// all pages are joined together when the doc is written as a single file
+		var joined []byte
+		for _, name := range sortedKeys(pages) {
+			joined = append(joined, pages[name]...)
+			joined = append(joined, '\n')
+		}
+		return joined, nil
+	default:
+		return nil, fmt.Errorf("unknown output format '%s' must be one of [%s, %s, %s, %s, %s]", c.flags.outputFormat, formatJSON, formatHTML, formatMD, formatTS, formatGo)
+	}
+}
+
+func (c *generateCommand) writeDoc(swagger *spec.Swagger) error {
+	if c.flags.chunkMaxDefs > 0 {
+		return c.writeChunks(swagger)
+	}
+	return c.writeSingleDoc(swagger)
+}
+
+// writeChunks splits the swagger doc by the maximum number of definitions and writes each chunk
+// to its own output file named after the chunk number.
+func (c *generateCommand) writeChunks(swagger *spec.Swagger) error {
+	if c.flags.outputFile == "" || c.flags.outputFormat != formatJSON {
+		return fmt.Errorf("chunking requires json output to an output file")
+	}
+	chunks, err := generator.ChunkSwagger(swagger, c.flags.chunkMaxDefs)
+	if err != nil {
+		return err
+	}
+	if len(chunks) == 1 {
+		return c.writeSingleDoc(swagger)
+	}
+	outputFile := c.flags.outputFile
+	defer func() { c.flags.outputFile = outputFile }()
+	for i, chunk := range chunks {
+		c.flags.outputFile = suffixedPath(outputFile, strconv.Itoa(i+1))
+		if err := c.writeSingleDoc(chunk); err != nil {
+			return err
+		}
+	}
+	zap.S().Infof("Split the swagger doc into %d docs.", len(chunks))
+	return nil
+}
+
+func (c *generateCommand) writeSingleDoc(swagger *spec.Swagger) error {
+	if c.flags.outputFormat == formatMD && c.flags.outputFile != "" {
+		if c.flags.compress {
+			return fmt.Errorf("markdown pages written to a directory can not be compressed")
+		}
+		return c.writeMarkdownPages(swagger)
+	}
+	switch c.flags.jsonEncoder {
+	case encoderStream:
+		if c.flags.outputFormat == formatJSON {
+			return c.streamDoc(swagger)
+		}
+	case encoderStandard:
+	default:
+		return fmt.Errorf("unknown json encoder '%s' must be %s or %s", c.flags.jsonEncoder, encoderStandard, encoderStream)
+	}
+	outData, err := c.marshalDoc(swagger)
+	if err != nil {
+		return fmt.Errorf("failed to marshal swagger: %w", err)
+	}
+	if c.flags.compress {
+		if outData, err = gzipData(outData); err != nil {
+			return err
+		}
+	} else if c.flags.outputFile == "" {
+		outData = append(outData, '\n')
+	}
+	if c.flags.outputFile == "" {
+		_, err := c.streams.Out.Write(outData)
+		if err != nil {
+			return fmt.Errorf("failed to write swagger to stdout: %w", err)
+		}
+		return nil
+	}
+	err = os.WriteFile(c.docPath(), outData, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write swagger doc: %w", err)
+	}
+	return nil
+}
+
+// docPath returns the file the doc is written to, compressed docs have .gz added to the output file.
+func (c *generateCommand) docPath() string {
+	if c.flags.compress && !strings.HasSuffix(c.flags.outputFile, ".gz") {
+		return c.flags.outputFile + ".gz"
+	}
+	return c.flags.outputFile
+}
+
+func gzipData(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to compress swagger doc: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress swagger doc: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// streamDoc encodes the swagger doc directly to the output file or stdout, compressing it on the way if requested.
+func (c *generateCommand) streamDoc(swagger *spec.Swagger) error {
+	if c.flags.outputFile == "" {
+		if c.flags.compress {
+			return c.streamCompressed(c.streams.Out, swagger)
+		}
+		if err := render.StreamJSON(c.streams.Out, swagger, c.flags.prettyPrint); err != nil {
+			return fmt.Errorf("failed to write swagger to stdout: %w", err)
+		}
+		_, err := c.streams.Out.Write([]byte{'\n'})
+		return err
+	}
+	out, err := os.OpenFile(c.docPath(), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create swagger doc: %w", err)
+	}
+	if c.flags.compress {
+		err = c.streamCompressed(out, swagger)
+	} else {
+		err = render.StreamJSON(out, swagger, c.flags.prettyPrint)
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (c *generateCommand) streamCompressed(out io.Writer, swagger *spec.Swagger) error {
+	writer := gzip.NewWriter(out)
+	if err := render.StreamJSON(writer, swagger, c.flags.prettyPrint); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to compress swagger doc: %w", err)
+	}
+	return nil
+}
+
+func (c *generateCommand) writeBadge(swagger *spec.Swagger) error {
+	badge, err := render.Badge(swagger)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(c.flags.badgeFile, badge, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write badge: %w", err)
+	}
+	return nil
+}
+
+// writeRBAC writes the example ClusterRoles for the documented kinds as a multi-document YAML file.
+func (c *generateCommand) writeRBAC(swagger *spec.Swagger) error {
+	roles, err := generator.ClusterRoles(swagger, c.flags.pathPrefix)
+	if err != nil {
+		return fmt.Errorf("failed to create ClusterRoles: %w", err)
+	}
+	var out []byte
+	for i, role := range roles {
+		data, err := yaml.Marshal(role)
+		if err != nil {
+			return fmt.Errorf("failed to marshal ClusterRole: %w", err)
+		}
+		if i != 0 {
+			out = append(out, []byte("---\n")...)
+		}
+		out = append(out, data...)
+	}
+	if err := os.WriteFile(c.flags.rbacFile, out, 0600); err != nil {
+		return fmt.Errorf("failed to write ClusterRoles: %w", err)
+	}
+	return nil
+}
+
+// writeMarkdownPages writes every markdown page to its own file in the output directory.
+func (c *generateCommand) writeMarkdownPages(swagger *spec.Swagger) error {
+	pages, err := render.Markdown(swagger)
+	if err != nil {
+		return fmt.Errorf("failed to render markdown: %w", err)
+	}
+	if err := os.MkdirAll(c.flags.outputFile, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	for name, page := range pages {
+		err := os.WriteFile(filepath.Join(c.flags.outputFile, name), page, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to write markdown page: %w", err)
+		}
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// writeFindings logs the findings or writes them to the findings file in the requested format.
+func (c *generateCommand) writeFindings(findings []generator.Finding) error {
+	switch c.flags.findingsFormat {
+	case findingsText:
+		for _, finding := range findings {
+			zap.S().Warnf("Lint %s", finding)
+		}
+		return nil
+	case findingsSARIF:
+		if c.flags.findingsFile == "" {
+			return fmt.Errorf("findings-file must be set to write sarif findings")
+		}
+		data, err := render.SARIF(findings, c.flags.outputFile)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(c.flags.findingsFile, data, 0600); err != nil {
+			return fmt.Errorf("failed to write findings: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown findings format '%s' must be one of [%s, %s]", c.flags.findingsFormat, findingsText, findingsSARIF)
+	}
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/KevinJoiner/crd-swagger/pkg/generator"
+)
+
+// runSummary is the summary file written for CI pipelines.
+type runSummary struct {
+	generator.Summary
+	// Output is the file the doc was written to, empty for stdout.
+	Output string `json:"output,omitempty"`
+	// ExitCode is the exit code of the run, see ExitCode.
+	ExitCode int `json:"exitCode"`
+	// Error is the error the run failed with.
+	Error string `json:"error,omitempty"`
+}
+
+// writeSummary writes the summary of the run that ended with err to the summary file.
+func (c *generateCommand) writeSummary(summary *runSummary, err error) error {
+	summary.ExitCode = ExitCode(err)
+	if err != nil {
+		summary.Error = err.Error()
+	}
+	if c.flags.outputFile != "" && (summary.ExitCode == 0 || summary.ExitCode == ExitMissingGroupKinds) {
+		summary.Output = c.docPath()
+	}
+	data, marshalErr := json.MarshalIndent(summary, "", "  ")
+	if marshalErr != nil {
+		return fmt.Errorf("failed to marshal summary: %w", marshalErr)
+	}
+	if writeErr := os.WriteFile(c.flags.summaryFile, append(data, '\n'), 0600); writeErr != nil {
+		return fmt.Errorf("failed to write summary: %w", writeErr)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/KevinJoiner/crd-swagger/pkg/generator"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// newValidateCommand returns the command that validates swagger docs.
+func newValidateCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate FILE...",
+		Short: "Validate swagger docs",
+		Long: `Checks swagger docs against the rules of the Swagger 2.0 specification and verifies that every $ref resolves.
+Exits non-zero when any doc is invalid.`,
+		Args: cobra.MinimumNArgs(1),
+		// invalid docs fail the command but are not a usage error
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runValidate(args)
+		},
+	}
+}
+
+func runValidate(files []string) error {
+	invalid := 0
+	for _, file := range files {
+		swagger, err := readSwagger(file)
+		if err != nil {
+			return err
+		}
+		findings, err := generator.ValidateSwagger(swagger)
+		if err != nil {
+			return err
+		}
+		for _, finding := range findings {
+			fmt.Printf("%s: %s\n", file, finding)
+		}
+		if len(findings) != 0 {
+			invalid++
+		}
+	}
+	if invalid != 0 {
+		return fmt.Errorf("%d of %d swagger docs are invalid", invalid, len(files))
+	}
+	return nil
+}
+
+// validateDoc fails when the generated swagger doc is invalid so broken docs are never written.
+func validateDoc(swagger *spec.Swagger) error {
+	findings, err := generator.ValidateSwagger(swagger)
+	if err != nil {
+		return err
+	}
+	for _, finding := range findings {
+		zap.S().Warn(finding.String())
+	}
+	if len(findings) != 0 {
+		return fmt.Errorf("generated swagger doc is invalid, found %d problems", len(findings))
+	}
+	return nil
+}
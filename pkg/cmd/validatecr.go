@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/KevinJoiner/crd-swagger/pkg/generator"
+	"github.com/spf13/cobra"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+type validateCRFlagVar struct {
+	specFile string
+	recurse  bool
+	server   string
+	token    string
+	username string
+	password string
+	caFile   string
+	insecure bool
+}
+
+// validateCRCommand holds the state of a validate-cr command.
+type validateCRCommand struct {
+	flags validateCRFlagVar
+}
+
+// newValidateCRCommand returns the command that validates custom resources against the schemas of their kinds.
+func newValidateCRCommand() *cobra.Command {
+	c := &validateCRCommand{}
+	cmd := &cobra.Command{
+		Use:   "validate-cr PATH",
+		Short: "Validate custom resources against the swagger doc",
+		Long: `Validates every resource in the YAML and JSON files at PATH, a file or a directory, against the schema of its kind.
+The schemas are read from a generated swagger doc or from the swagger doc served by a live API server.
+Each problem is reported with the JSON path of the field, fields the schema does not declare are reported as unknown.
+Exits non-zero when any resource is invalid.`,
+		Args: cobra.ExactArgs(1),
+		// invalid resources fail the command but are not a usage error
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.run(args[0])
+		},
+	}
+	cmd.Flags().StringVar(&c.flags.specFile, "spec", "", "generated swagger doc with the schemas to validate against")
+	cmd.Flags().BoolVarP(&c.flags.recurse, "recurse", "r", false, "if PATH is a directory recursively search for resources")
+	cmd.Flags().StringVar(&c.flags.server, "server", "", "address of a live API server to read the schemas from instead of a swagger doc")
+	cmd.Flags().StringVar(&c.flags.token, "token", "", "bearer token used to authenticate to the API server")
+	cmd.Flags().StringVar(&c.flags.username, "username", "", "username for basic authentication to the API server")
+	cmd.Flags().StringVar(&c.flags.password, "password", "", "password for basic authentication to the API server")
+	cmd.Flags().StringVar(&c.flags.caFile, "ca-file", "", "path to a cert file for the certificate authority of the API server")
+	cmd.Flags().BoolVar(&c.flags.insecure, "insecure-skip-tls-verify", false, "do not verify the API server's certificate")
+	return cmd
+}
+
+func (c *validateCRCommand) run(path string) error {
+	var swagger *spec.Swagger
+	var err error
+	switch {
+	case c.flags.specFile != "" && c.flags.server != "":
+		return fmt.Errorf("only one of spec or server can be set")
+	case c.flags.specFile != "":
+		swagger, err = readSwagger(c.flags.specFile)
+	case c.flags.server != "":
+		swagger, err = generator.ServerSwagger(generator.Options{
+			Server:                c.flags.server,
+			Token:                 c.flags.token,
+			Username:              c.flags.username,
+			Password:              c.flags.password,
+			CAFile:                c.flags.caFile,
+			InsecureSkipTLSVerify: c.flags.insecure,
+		})
+	default:
+		return fmt.Errorf("either spec or server must be set")
+	}
+	if err != nil {
+		return err
+	}
+
+	crErrors, checked, err := generator.ValidateCRs(swagger, path, c.flags.recurse)
+	if err != nil {
+		return err
+	}
+	invalid := map[string]bool{}
+	for _, crErr := range crErrors {
+		fmt.Println(crErr.String())
+		invalid[crErr.File+"/"+crErr.Object] = true
+	}
+	if len(invalid) != 0 {
+		return fmt.Errorf("%d of %d resources are invalid", len(invalid), checked)
+	}
+	fmt.Printf("all %d resources are valid\n", checked)
+	return nil
+}
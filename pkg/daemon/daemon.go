@@ -0,0 +1,129 @@
+// Package daemon serves swagger generation over the gRPC API described in daemon.proto so editors and IDE plugins
+// can regenerate the doc of the CRDs being edited using a cluster that is already running.
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/KevinJoiner/crd-swagger/pkg/generator"
+	"github.com/KevinJoiner/crd-swagger/pkg/render"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// ServiceName is the full name of the Generator service in daemon.proto.
+const ServiceName = "crdswagger.v1.Generator"
+
+// FileMetadataKey is the gRPC metadata key of the name of the file sent to Diagnose, it is the location of the
+// diagnostics in the returned SARIF log.
+const FileMetadataKey = "crd-swagger-file"
+
+// swaggerGenerator generates the swagger doc for the CRDs in a YAML or JSON document and diagnoses problems with
+// them, such as a generator.Session.
+type swaggerGenerator interface {
+	Generate(ctx context.Context, data []byte) (*spec.Swagger, error)
+	Diagnose(ctx context.Context, file string, data []byte) ([]generator.Diagnostic, error)
+}
+
+// serviceDesc describes the Generator service the way protoc-gen-go-grpc would, so the service is served without
+// generated code. The messages are protobuf well-known types.
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*swaggerGenerator)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Generate", Handler: unaryHandler("Generate", generate)},
+		{MethodName: "Diagnose", Handler: unaryHandler("Diagnose", diagnose)},
+	},
+	Metadata: "daemon.proto",
+}
+
+// Serve serves the Generator service on the listener with the generator until ctx is canceled.
+func Serve(ctx context.Context, listener net.Listener, gen swaggerGenerator) error {
+	server := grpc.NewServer()
+	server.RegisterService(&serviceDesc, gen)
+	go func() {
+		<-ctx.Done()
+		server.GracefulStop()
+	}()
+	if err := server.Serve(listener); err != nil {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+	return nil
+}
+
+// unaryHandler returns the handler of a method that takes and returns bytes the way protoc-gen-go-grpc generates it.
+func unaryHandler(method string, call func(context.Context, swaggerGenerator, *wrapperspb.BytesValue) (*wrapperspb.BytesValue, error)) func(interface{}, context.Context, func(interface{}) error, grpc.UnaryServerInterceptor) (interface{}, error) {
+	return func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+		in := new(wrapperspb.BytesValue)
+		if err := dec(in); err != nil {
+			return nil, err
+		}
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			return call(ctx, srv.(swaggerGenerator), req.(*wrapperspb.BytesValue))
+		}
+		if interceptor == nil {
+			return handler(ctx, in)
+		}
+		info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + ServiceName + "/" + method}
+		return interceptor(ctx, in, info, handler)
+	}
+}
+
+// generate generates the swagger doc for the CRDs in the request and returns it as JSON.
+func generate(ctx context.Context, gen swaggerGenerator, req *wrapperspb.BytesValue) (*wrapperspb.BytesValue, error) {
+	swagger, err := gen.Generate(ctx, req.GetValue())
+	if err != nil {
+		return nil, status.Error(errorCode(err), err.Error())
+	}
+	data, err := json.Marshal(swagger)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to marshal swagger doc: %v", err)
+	}
+	return wrapperspb.Bytes(data), nil
+}
+
+// diagnose diagnoses the CRDs in the request and returns the diagnostics as a SARIF log. The diagnostics are located
+// in the file named by the request's FileMetadataKey metadata.
+func diagnose(ctx context.Context, gen swaggerGenerator, req *wrapperspb.BytesValue) (*wrapperspb.BytesValue, error) {
+	var file string
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get(FileMetadataKey)) != 0 {
+		file = md.Get(FileMetadataKey)[0]
+	}
+	diagnostics, err := gen.Diagnose(ctx, file, req.GetValue())
+	if err != nil {
+		return nil, status.Error(errorCode(err), err.Error())
+	}
+	data, err := render.DiagnosticsSARIF(diagnostics)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+	return wrapperspb.Bytes(data), nil
+}
+
+// errorCode returns the status code of a generation error, errors from before generation started are caused by
+// the request.
+func errorCode(err error) codes.Code {
+	var missingErr *generator.MissingGroupKindsError
+	var stageErr *generator.StageError
+	switch {
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	case errors.As(err, &missingErr):
+		return codes.NotFound
+	case errors.As(err, &stageErr) && stageErr.Infrastructure():
+		return codes.Unavailable
+	case errors.As(err, &stageErr):
+		return codes.Internal
+	}
+	return codes.InvalidArgument
+}
@@ -0,0 +1,91 @@
+package generator
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// resourceAlias renames a kind or a group for the resource entries written before the rename.
+type resourceAlias struct {
+	from string
+	to   string
+}
+
+// loadAliases reads the aliases file, each line maps an old resource to its new name separated by whitespace.
+// Kinds are renamed with Kind.group entries, e.g. `Cluster.management.cattle.io Cluster.provisioning.cattle.io`,
+// and groups with *.group entries, e.g. `*.mgmt.cattle.io *.management.cattle.io`.
+// Blank lines and lines starting with # are ignored.
+func loadAliases(file string) ([]resourceAlias, error) {
+	if file == "" {
+		return nil, nil
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read aliases file: %w", err)
+	}
+	defer f.Close()
+
+	var aliases []resourceAlias
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid alias on line %d of '%s': must be an old and a new resource separated by whitespace", line, file)
+		}
+		alias := resourceAlias{from: fields[0], to: fields[1]}
+		if err := validateAlias(alias); err != nil {
+			return nil, fmt.Errorf("invalid alias on line %d of '%s': %w", line, file, err)
+		}
+		aliases = append(aliases, alias)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read aliases file '%s': %w", file, err)
+	}
+	return aliases, nil
+}
+
+func validateAlias(alias resourceAlias) error {
+	fromKind, fromGroup := splitResource(alias.from)
+	toKind, toGroup := splitResource(alias.to)
+	for _, part := range []string{fromGroup, toGroup} {
+		if strings.ContainsAny(part, "*?[") {
+			return fmt.Errorf("'%s %s' groups can not be patterns", alias.from, alias.to)
+		}
+	}
+	switch {
+	case strings.Contains(alias.from+alias.to, "/"):
+		return fmt.Errorf("'%s %s' can not be pinned to versions", alias.from, alias.to)
+	case fromKind == "" || toKind == "":
+		return fmt.Errorf("'%s %s' must be Kind.group or *.group entries", alias.from, alias.to)
+	case (fromKind == "*") != (toKind == "*"):
+		return fmt.Errorf("'%s %s' must rename either a group with *.group entries or a kind with Kind.group entries", alias.from, alias.to)
+	case fromKind != "*" && strings.ContainsAny(fromKind+toKind, "*?["):
+		return fmt.Errorf("'%s %s' kinds can not be patterns", alias.from, alias.to)
+	}
+	return nil
+}
+
+// aliasResource returns the resource entry renamed by the aliases. Kind aliases of the entry are used over
+// aliases of its group, in which case the kind, or kind pattern, of the entry is kept.
+func aliasResource(resource string, aliases []resourceAlias) (string, bool) {
+	resource, version := splitVersion(resource)
+	for _, alias := range aliases {
+		if alias.from == resource {
+			return pinVersion(alias.to, version), true
+		}
+	}
+	kind, group := splitResource(resource)
+	for _, alias := range aliases {
+		if _, fromGroup := splitResource(alias.from); strings.HasPrefix(alias.from, "*.") && fromGroup == group {
+			_, toGroup := splitResource(alias.to)
+			return pinVersion(kind+"."+toGroup, version), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,41 @@
+package generator
+
+import (
+	"net/url"
+	"regexp"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+var uidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// anonymize removes details from the swagger doc that identify the cluster it was generated from:
+// the server host, document level vendor extensions, and default or example values that are URLs or UIDs.
+func anonymize(swagger *spec.Swagger) {
+	swagger.Host = ""
+	swagger.Extensions = nil
+	if swagger.Info != nil {
+		swagger.Info.Extensions = nil
+	}
+	walkSchemas(swagger, func(_ string, schema *spec.Schema) {
+		if isIdentifyingValue(schema.Default) {
+			schema.Default = nil
+		}
+		if isIdentifyingValue(schema.Example) {
+			schema.Example = nil
+		}
+	})
+}
+
+// isIdentifyingValue reports if value is a string containing a URL with a host or a UID.
+func isIdentifyingValue(value interface{}) bool {
+	str, ok := value.(string)
+	if !ok {
+		return false
+	}
+	if uidRegex.MatchString(str) {
+		return true
+	}
+	parsed, err := url.Parse(str)
+	return err == nil && parsed.Host != ""
+}
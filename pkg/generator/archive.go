@@ -0,0 +1,148 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// ArchiveIndexFile is the name of the index of an archive directory.
+const ArchiveIndexFile = "index.json"
+
+// ArchiveEntry is a swagger doc stored in an archive.
+type ArchiveEntry struct {
+	// Version is the release the doc was generated for, e.g. v2.9.1.
+	Version string `json:"version"`
+	// File is the name of the doc in the archive directory.
+	File string `json:"file"`
+	// SHA256 is the hex encoded checksum of the doc.
+	SHA256 string `json:"sha256"`
+	// Added is when the doc was added to the archive.
+	Added time.Time `json:"added"`
+}
+
+// ArchiveIndex lists the docs of an archive in the order they were added.
+type ArchiveIndex struct {
+	Entries []ArchiveEntry `json:"entries"`
+}
+
+// Find returns the entry for the version.
+func (a *ArchiveIndex) Find(version string) (ArchiveEntry, bool) {
+	for _, entry := range a.Entries {
+		if entry.Version == version {
+			return entry, true
+		}
+	}
+	return ArchiveEntry{}, false
+}
+
+// LoadArchive reads the index of the archive in dir, an archive that does not exist yet is empty.
+func LoadArchive(dir string) (*ArchiveIndex, error) {
+	index := &ArchiveIndex{}
+	data, err := os.ReadFile(filepath.Join(dir, ArchiveIndexFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive index: %w", err)
+	}
+	if err := json.Unmarshal(data, index); err != nil {
+		return nil, fmt.Errorf("failed to parse archive index in '%s': %w", dir, err)
+	}
+	return index, nil
+}
+
+// AddToArchive copies the swagger doc in file into the archive in dir as version and records it in the index.
+// The archive is append-only, adding a version that is already archived is an error.
+func AddToArchive(dir, file, version string) (ArchiveEntry, error) {
+	if version == "" || strings.ContainsAny(version, `/\`) || version == "." || version == ".." {
+		return ArchiveEntry{}, fmt.Errorf("invalid archive version '%s'", version)
+	}
+	index, err := LoadArchive(dir)
+	if err != nil {
+		return ArchiveEntry{}, err
+	}
+	if _, ok := index.Find(version); ok {
+		return ArchiveEntry{}, fmt.Errorf("version '%s' is already archived, archived docs can not be replaced", version)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return ArchiveEntry{}, fmt.Errorf("failed to read swagger doc: %w", err)
+	}
+	if err := json.Unmarshal(data, &spec.Swagger{}); err != nil {
+		return ArchiveEntry{}, fmt.Errorf("failed to parse swagger doc '%s': %w", file, err)
+	}
+	added, err := BuildTime()
+	if err != nil {
+		return ArchiveEntry{}, err
+	}
+	sum := sha256.Sum256(data)
+	entry := ArchiveEntry{
+		Version: version,
+		File:    version + ".json",
+		SHA256:  hex.EncodeToString(sum[:]),
+		Added:   added,
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return ArchiveEntry{}, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	docPath := filepath.Join(dir, entry.File)
+	// O_EXCL keeps a doc left behind by an earlier failed add from being overwritten
+	docFile, err := os.OpenFile(docPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return ArchiveEntry{}, fmt.Errorf("failed to create archived doc: %w", err)
+	}
+	_, err = docFile.Write(data)
+	if closeErr := docFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return ArchiveEntry{}, fmt.Errorf("failed to write archived doc: %w", err)
+	}
+
+	index.Entries = append(index.Entries, entry)
+	indexData, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return ArchiveEntry{}, fmt.Errorf("failed to marshal archive index: %w", err)
+	}
+	// the index is replaced in a single rename so readers never see a partial index
+	tmp := filepath.Join(dir, "."+ArchiveIndexFile+".tmp")
+	if err := os.WriteFile(tmp, append(indexData, '\n'), 0644); err != nil {
+		return ArchiveEntry{}, fmt.Errorf("failed to write archive index: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, ArchiveIndexFile)); err != nil {
+		return ArchiveEntry{}, fmt.Errorf("failed to write archive index: %w", err)
+	}
+	return entry, nil
+}
+
+// ArchivedDoc returns the path of the doc archived for the version in dir after verifying its checksum.
+func ArchivedDoc(dir, version string) (string, error) {
+	index, err := LoadArchive(dir)
+	if err != nil {
+		return "", err
+	}
+	entry, ok := index.Find(version)
+	if !ok {
+		return "", fmt.Errorf("version '%s' is not in the archive '%s'", version, dir)
+	}
+	docPath := filepath.Join(dir, entry.File)
+	data, err := os.ReadFile(docPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read archived doc: %w", err)
+	}
+	if sum := sha256.Sum256(data); hex.EncodeToString(sum[:]) != entry.SHA256 {
+		return "", fmt.Errorf("archived doc '%s' does not match its checksum in the index", docPath)
+	}
+	return docPath, nil
+}
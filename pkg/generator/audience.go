@@ -0,0 +1,202 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/aggregator"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// AudiencePublic docs are published to everyone.
+	AudiencePublic = "public"
+	// AudiencePartner docs are shared with partners, they include the public docs.
+	AudiencePartner = "partner"
+	// AudienceInternal docs are only for internal use, they include the partner and public docs.
+	AudienceInternal = "internal"
+)
+
+// Audiences lists every audience from the widest to the narrowest.
+var Audiences = []string{AudiencePublic, AudiencePartner, AudienceInternal}
+
+// AudienceMap assigns the kinds and fields of the swagger doc to the audience allowed to see them.
+type AudienceMap struct {
+	// Default is the audience of kinds and fields that are not listed, public if unset.
+	Default string `json:"default,omitempty"`
+	// Kinds maps a kind, either Kind or group/Kind, to its audience.
+	Kinds map[string]string `json:"kinds,omitempty"`
+	// Fields maps a field, a kind followed by the field path, e.g. management.cattle.io/Cluster.spec.agentEnvVars,
+	// to its audience.
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// LoadAudienceMap reads and validates the audience map in the YAML file at path.
+func LoadAudienceMap(path string) (*AudienceMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audience map: %w", err)
+	}
+	audienceMap := &AudienceMap{}
+	if err := yaml.UnmarshalStrict(data, audienceMap); err != nil {
+		return nil, fmt.Errorf("failed to parse audience map '%s': %w", path, err)
+	}
+	if audienceMap.Default == "" {
+		audienceMap.Default = AudiencePublic
+	}
+	if err := validateAudience(audienceMap.Default); err != nil {
+		return nil, fmt.Errorf("invalid default audience: %w", err)
+	}
+	for _, rules := range []map[string]string{audienceMap.Kinds, audienceMap.Fields} {
+		for key, audience := range rules {
+			if err := validateAudience(audience); err != nil {
+				return nil, fmt.Errorf("invalid audience for '%s': %w", key, err)
+			}
+		}
+	}
+	for key := range audienceMap.Fields {
+		if _, fieldPath := splitAudienceField(key); fieldPath == "" {
+			return nil, fmt.Errorf("field '%s' must be a kind followed by a field path, e.g. Cluster.spec.name", key)
+		}
+	}
+	return audienceMap, nil
+}
+
+func validateAudience(audience string) error {
+	if audienceLevel(audience) < 0 {
+		return fmt.Errorf("unknown audience '%s' must be one of [%s]", audience, strings.Join(Audiences, ", "))
+	}
+	return nil
+}
+
+// audienceLevel returns the position of audience in Audiences, or -1 for an unknown audience.
+func audienceLevel(audience string) int {
+	for i := range Audiences {
+		if Audiences[i] == audience {
+			return i
+		}
+	}
+	return -1
+}
+
+// PartitionByAudience returns a copy of the swagger doc for every audience containing only the paths
+// and fields that audience is allowed to see.
+func PartitionByAudience(swagger *spec.Swagger, audienceMap *AudienceMap) (map[string]*spec.Swagger, error) {
+	data, err := json.Marshal(swagger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal swagger doc: %w", err)
+	}
+	docs := make(map[string]*spec.Swagger, len(Audiences))
+	for _, audience := range Audiences {
+		doc := &spec.Swagger{}
+		if err := json.Unmarshal(data, doc); err != nil {
+			return nil, fmt.Errorf("failed to copy swagger doc: %w", err)
+		}
+		filterAudience(doc, audienceMap, audienceLevel(audience))
+		docs[audience] = doc
+	}
+	return docs, nil
+}
+
+// filterAudience removes the paths and fields of the swagger doc above the audience level.
+func filterAudience(swagger *spec.Swagger, audienceMap *AudienceMap, level int) {
+	var keepPaths []string
+	if swagger.Paths != nil {
+		for _, pathName := range sortedKeys(swagger.Paths.Paths) {
+			visible := true
+			for _, gk := range groupKindsFromPath(swagger.Paths.Paths[pathName]) {
+				if audienceLevel(audienceMap.kindAudience(gk.Group, gk.Kind)) > level {
+					visible = false
+				}
+			}
+			if visible {
+				keepPaths = append(keepPaths, pathName)
+			}
+		}
+	}
+	aggregator.FilterSpecByPaths(swagger, keepPaths)
+
+	for _, key := range sortedKeys(audienceMap.Fields) {
+		if audienceLevel(audienceMap.Fields[key]) <= level {
+			continue
+		}
+		kind, fieldPath := splitAudienceField(key)
+		for _, name := range sortedKeys(swagger.Definitions) {
+			if !definitionHasKind(swagger.Definitions[name], kind) {
+				continue
+			}
+			def := swagger.Definitions[name]
+			if !removeField(&def, strings.Split(fieldPath, ".")) {
+				zap.S().Warnf("Field '%s' of the audience map was not found in definition %s.", key, name)
+			}
+			swagger.Definitions[name] = def
+		}
+	}
+}
+
+// kindAudience returns the audience of the kind, preferring a group qualified entry over a plain kind entry.
+func (a *AudienceMap) kindAudience(group, kind string) string {
+	if audience, ok := a.Kinds[group+"/"+kind]; ok {
+		return audience
+	}
+	if audience, ok := a.Kinds[kind]; ok {
+		return audience
+	}
+	return a.Default
+}
+
+// splitAudienceField splits a field key of the audience map into the kind and the dot separated field path.
+func splitAudienceField(key string) (string, string) {
+	slash := strings.LastIndex(key, "/")
+	dot := strings.Index(key[slash+1:], ".")
+	if dot < 0 {
+		return key, ""
+	}
+	return key[:slash+1+dot], key[slash+1+dot+1:]
+}
+
+// definitionHasKind reports whether the definition is for kind, either Kind or group/Kind.
+func definitionHasKind(def spec.Schema, kind string) bool {
+	var gvks []schema.GroupVersionKind
+	if err := def.Extensions.GetObject(extensionGVK, &gvks); err != nil {
+		return false
+	}
+	group, name, qualified := strings.Cut(kind, "/")
+	for _, gvk := range gvks {
+		if qualified && gvk.Group == group && gvk.Kind == name || !qualified && gvk.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// removeField removes the property at fieldPath from the schema, following list items along the way.
+// Returns false if the field does not exist.
+func removeField(props *spec.Schema, fieldPath []string) bool {
+	for props.Items != nil && props.Items.Schema != nil {
+		props = props.Items.Schema
+	}
+	prop, ok := props.Properties[fieldPath[0]]
+	if !ok {
+		return false
+	}
+	if len(fieldPath) > 1 {
+		removed := removeField(&prop, fieldPath[1:])
+		props.Properties[fieldPath[0]] = prop
+		return removed
+	}
+	delete(props.Properties, fieldPath[0])
+	required := props.Required[:0]
+	for _, name := range props.Required {
+		if name != fieldPath[0] {
+			required = append(required, name)
+		}
+	}
+	props.Required = required
+	return true
+}
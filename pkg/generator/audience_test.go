@@ -0,0 +1,83 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+func TestSplitAudienceField(t *testing.T) {
+	tests := []struct {
+		key       string
+		wantKind  string
+		wantField string
+	}{
+		{key: "Cluster", wantKind: "Cluster"},
+		{key: "Cluster.spec.internal", wantKind: "Cluster", wantField: "spec.internal"},
+		{key: "management.cattle.io/Cluster", wantKind: "management.cattle.io/Cluster"},
+		{key: "management.cattle.io/Cluster.spec.internal", wantKind: "management.cattle.io/Cluster", wantField: "spec.internal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			kind, field := splitAudienceField(tt.key)
+			if kind != tt.wantKind || field != tt.wantField {
+				t.Errorf("splitAudienceField(%s) = %s, %s, want %s, %s", tt.key, kind, field, tt.wantKind, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestRemoveField(t *testing.T) {
+	tests := []struct {
+		name         string
+		fieldPath    []string
+		want         bool
+		wantProps    []string
+		wantRequired []string
+	}{
+		{name: "top level", fieldPath: []string{"status"}, want: true, wantProps: []string{"spec"}, wantRequired: []string{"spec"}},
+		{name: "required", fieldPath: []string{"spec"}, want: true, wantProps: []string{"status"}, wantRequired: []string{}},
+		{name: "missing", fieldPath: []string{"other"}, want: false, wantProps: []string{"spec", "status"}, wantRequired: []string{"spec"}},
+		{name: "missing nested", fieldPath: []string{"spec", "other"}, want: false, wantProps: []string{"spec", "status"}, wantRequired: []string{"spec"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := spec.Schema{SchemaProps: spec.SchemaProps{
+				Required: []string{"spec"},
+				Properties: map[string]spec.Schema{
+					"spec":   *spec.StringProperty(),
+					"status": *spec.StringProperty(),
+				},
+			}}
+			if got := removeField(&schema, tt.fieldPath); got != tt.want {
+				t.Errorf("removeField(%v) = %v, want %v", tt.fieldPath, got, tt.want)
+			}
+			if got := sortedKeys(schema.Properties); !reflect.DeepEqual(got, tt.wantProps) {
+				t.Errorf("properties = %v, want %v", got, tt.wantProps)
+			}
+			if !reflect.DeepEqual(schema.Required, tt.wantRequired) {
+				t.Errorf("required = %v, want %v", schema.Required, tt.wantRequired)
+			}
+		})
+	}
+}
+
+func TestRemoveFieldInList(t *testing.T) {
+	item := spec.Schema{SchemaProps: spec.SchemaProps{Properties: map[string]spec.Schema{
+		"name":   *spec.StringProperty(),
+		"secret": *spec.StringProperty(),
+	}}}
+	schema := spec.Schema{SchemaProps: spec.SchemaProps{Properties: map[string]spec.Schema{
+		"spec": {SchemaProps: spec.SchemaProps{Properties: map[string]spec.Schema{
+			"users": *spec.ArrayProperty(&item),
+		}}},
+	}}}
+	if !removeField(&schema, []string{"spec", "users", "secret"}) {
+		t.Fatal("removeField() = false, want true")
+	}
+	users := schema.Properties["spec"].Properties["users"]
+	if got := sortedKeys(users.Items.Schema.Properties); !reflect.DeepEqual(got, []string{"name"}) {
+		t.Errorf("list item properties = %v, want [name]", got)
+	}
+}
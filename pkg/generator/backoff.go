@@ -0,0 +1,93 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// maxPollInterval caps the exponential backoff between polls of the cluster.
+	maxPollInterval = time.Second * 5
+	// pollJitter randomly lengthens each poll interval by up to this fraction so concurrent runs do not poll in step.
+	pollJitter = 0.2
+	// breakerThreshold is how many times in a row the same persistent error is retried before polling gives up.
+	breakerThreshold = 5
+)
+
+// poller polls the cluster with capped exponential backoff and jitter, and stops early when it keeps failing with an
+// error that waiting does not fix, such as rejected credentials or an untrusted certificate. Errors that clear up
+// while the cluster starts, such as refused connections or an unavailable server, are retried until the timeout.
+type poller struct {
+	timeout time.Duration
+	lastErr error
+	repeats int
+}
+
+func newPoller(timeout time.Duration) *poller {
+	return &poller{timeout: timeout}
+}
+
+// retry records an error of a failed poll. It returns an error to stop polling once the same persistent error has
+// been seen breakerThreshold times in a row, otherwise nil so the poll is retried.
+func (p *poller) retry(err error) error {
+	if p.lastErr != nil && p.lastErr.Error() == err.Error() {
+		p.repeats++
+	} else {
+		p.repeats = 1
+	}
+	p.lastErr = err
+	if hint, persistent := pollHint(err); persistent && p.repeats >= breakerThreshold {
+		return fmt.Errorf("gave up after the same error %d times in a row: %w (%s)", p.repeats, err, hint)
+	}
+	return nil
+}
+
+// poll calls condition until it is done, returns an error, or the timeout passes. Errors from timing out include
+// the last error passed to retry and a hint for fixing it.
+func (p *poller) poll(ctx context.Context, condition wait.ConditionWithContextFunc) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	backoff := wait.Backoff{Duration: waitInterval, Factor: 2, Jitter: pollJitter, Steps: math.MaxInt32, Cap: maxPollInterval}
+	err := backoff.DelayFunc().Until(timeoutCtx, true, false, condition)
+	if err == nil || ctx.Err() != nil || !wait.Interrupted(err) {
+		return err
+	}
+	if p.lastErr == nil {
+		return fmt.Errorf("timed out after %v: %w", p.timeout, err)
+	}
+	if hint, _ := pollHint(p.lastErr); hint != "" {
+		return fmt.Errorf("timed out after %v, last error: %w (%s)", p.timeout, p.lastErr, hint)
+	}
+	return fmt.Errorf("timed out after %v, last error: %w", p.timeout, p.lastErr)
+}
+
+// pollHint returns a hint for fixing an error returned while polling the cluster, and whether waiting will not fix it.
+func pollHint(err error) (hint string, persistent bool) {
+	var dnsErr *net.DNSError
+	switch {
+	case apierrors.IsUnauthorized(err):
+		return "the API server rejected the credentials, check the token or username and password", true
+	case apierrors.IsForbidden(err):
+		return "the credentials are not allowed to read the API, they need get on the /version and /openapi/v2 nonResourceURLs", true
+	case strings.Contains(err.Error(), "x509: "):
+		return "the API server's certificate is not trusted, set the CA file or skip TLS verification", true
+	case errors.As(err, &dnsErr) && dnsErr.IsNotFound:
+		return "the API server's host name does not resolve, check the server address", true
+	case apierrors.IsNotFound(err):
+		return "the server does not serve the Kubernetes API at this address, check the server address", true
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return "nothing is listening at the API server's address yet, check that the server is running", false
+	case apierrors.IsServiceUnavailable(err), apierrors.IsTooManyRequests(err), apierrors.IsTimeout(err), apierrors.IsServerTimeout(err):
+		return "the API server is still starting or is overloaded", false
+	}
+	return "", false
+}
@@ -0,0 +1,83 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+func TestPollHint(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		wantPersistent bool
+		wantHint       bool
+	}{
+		{name: "unauthorized", err: apierrors.NewUnauthorized("bad token"), wantPersistent: true, wantHint: true},
+		{name: "untrusted certificate", err: errors.New("x509: certificate signed by unknown authority"), wantPersistent: true, wantHint: true},
+		{name: "connection refused", err: fmt.Errorf("dial: %w", syscall.ECONNREFUSED), wantHint: true},
+		{name: "service unavailable", err: apierrors.NewServiceUnavailable("starting"), wantHint: true},
+		{name: "unknown", err: errors.New("boom")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hint, persistent := pollHint(tt.err)
+			if persistent != tt.wantPersistent || (hint != "") != tt.wantHint {
+				t.Errorf("pollHint() = %q, %v, want hint %v, persistent %v", hint, persistent, tt.wantHint, tt.wantPersistent)
+			}
+		})
+	}
+}
+
+func TestPollerRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		errs     []error
+		wantStop bool
+	}{
+		{
+			name:     "persistent error trips the breaker",
+			errs:     repeatErr(apierrors.NewUnauthorized("bad token"), breakerThreshold),
+			wantStop: true,
+		},
+		{
+			name: "persistent error below the threshold",
+			errs: repeatErr(apierrors.NewUnauthorized("bad token"), breakerThreshold-1),
+		},
+		{
+			name: "transient error is retried",
+			errs: repeatErr(fmt.Errorf("dial: %w", syscall.ECONNREFUSED), breakerThreshold*2),
+		},
+		{
+			name: "a different error resets the count",
+			errs: append(append(repeatErr(apierrors.NewUnauthorized("bad token"), breakerThreshold-1),
+				errors.New("boom")), repeatErr(apierrors.NewUnauthorized("bad token"), breakerThreshold-1)...),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newPoller(time.Minute)
+			var err error
+			for _, pollErr := range tt.errs {
+				if err = p.retry(pollErr); err != nil {
+					break
+				}
+			}
+			if (err != nil) != tt.wantStop {
+				t.Errorf("retry() error = %v, want stop %v", err, tt.wantStop)
+			}
+		})
+	}
+}
+
+func repeatErr(err error, n int) []error {
+	errs := make([]error, n)
+	for i := range errs {
+		errs[i] = err
+	}
+	return errs
+}
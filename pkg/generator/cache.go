@@ -0,0 +1,211 @@
+package generator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+	"go.uber.org/zap"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// cacheFormat is part of every cache key so changing how docs are cached invalidates old entries.
+const cacheFormat = "v1"
+
+// cacheKey returns the key the cluster's full swagger doc is cached under, or an empty string if the doc can not be cached.
+// The key covers the resolved image digest and everything installed into the cluster, so only docs from EngineDocker clusters
+// without manifests or charts are cached, and only once the image is available locally. Nothing is cached when the
+// container is kept since a cached doc would skip starting it.
+func cacheKey(ctx context.Context, opts *Options, crds []*apiextv1.CustomResourceDefinition) (string, error) {
+	if opts.CacheDir == "" || opts.Engine != EngineDocker || opts.Server != "" || opts.ClusterProvider != nil || opts.OpenAPIURL != "" ||
+		len(opts.ApplyManifests) != 0 || len(opts.Charts) != 0 || opts.VerifyDeterministic || opts.KeepContainer {
+		return "", nil
+	}
+	if err := resolveImage(opts); err != nil {
+		return "", err
+	}
+	imageID := localImageID(ctx, opts.Image)
+	if imageID == "" {
+		return "", nil
+	}
+
+	hash := sha256.New()
+	write := func(values ...string) {
+		for _, value := range values {
+			hash.Write([]byte(value))
+			hash.Write([]byte{0})
+		}
+	}
+	write(cacheFormat, imageID)
+	for _, list := range [][]string{opts.FeatureGates, opts.ContainerEnv} {
+		sorted := append([]string{}, list...)
+		sort.Strings(sorted)
+		write(sorted...)
+		write("")
+	}
+	sortedCRDs := append([]*apiextv1.CustomResourceDefinition{}, crds...)
+	sort.Slice(sortedCRDs, func(i, j int) bool { return sortedCRDs[i].Name < sortedCRDs[j].Name })
+	for _, crd := range sortedCRDs {
+		data, err := json.Marshal(crd.Spec)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal CRD '%s': %w", crd.Name, err)
+		}
+		write(crd.Name, string(data))
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// localImageID returns the ID (digest) of the image if it is available locally, otherwise an empty string.
+func localImageID(ctx context.Context, image string) string {
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		zap.S().Debugf("Not caching swagger doc, failed to create docker client: %v", err)
+		return ""
+	}
+	defer cli.Close()
+	timeoutCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	info, _, err := cli.ImageInspectWithRaw(timeoutCtx, image)
+	if err != nil {
+		if !errdefs.IsNotFound(err) {
+			zap.S().Debugf("Not caching swagger doc, failed to inspect image '%s': %v", image, err)
+		}
+		return ""
+	}
+	return info.ID
+}
+
+// readCache returns the cached swagger doc for key, or nil if there is none.
+func readCache(dir, key string) (*spec.Swagger, error) {
+	path := filepath.Join(dir, key+".json")
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cached swagger doc: %w", err)
+	}
+	// the modification time records the last use so garbage collection removes the least recently used docs
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+	var swagger spec.Swagger
+	if err := json.Unmarshal(data, &swagger); err != nil {
+		return nil, fmt.Errorf("failed to decode cached swagger doc: %w", err)
+	}
+	return &swagger, nil
+}
+
+// writeCache stores the swagger doc under key. The doc is written to a temporary file first so
+// concurrent runs never read a partially written doc.
+func writeCache(dir, key string, swagger *spec.Swagger) error {
+	data, err := json.Marshal(swagger)
+	if err != nil {
+		return fmt.Errorf("failed to marshal swagger doc: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create cache dir '%s': %w", dir, err)
+	}
+	file, err := os.CreateTemp(dir, key+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create cache file: %w", err)
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	if err := os.Rename(file.Name(), filepath.Join(dir, key+".json")); err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	return nil
+}
+
+// CacheEntry is a swagger doc in the cache.
+type CacheEntry struct {
+	// Key is the cache key of the doc.
+	Key string
+	// Size is the size of the doc in bytes.
+	Size int64
+	// LastUsed is when the doc was last written or read.
+	LastUsed time.Time
+}
+
+// ListCache returns the entries of the cache in dir, least recently used first.
+func ListCache(dir string) ([]CacheEntry, error) {
+	files, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache dir '%s': %w", dir, err)
+	}
+	var entries []CacheEntry
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cache entry: %w", err)
+		}
+		entries = append(entries, CacheEntry{Key: strings.TrimSuffix(file.Name(), ".json"), Size: info.Size(), LastUsed: info.ModTime()})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].LastUsed.Before(entries[j].LastUsed) })
+	return entries, nil
+}
+
+// PruneCache removes the entries of the cache in dir that have not been used for maxAge, then removes the least
+// recently used entries until the cache is at most maxSize bytes. A zero maxAge or maxSize disables that limit.
+// Returns the removed entries.
+func PruneCache(dir string, maxAge time.Duration, maxSize int64) ([]CacheEntry, error) {
+	entries, err := ListCache(dir)
+	if err != nil {
+		return nil, err
+	}
+	var size int64
+	for _, entry := range entries {
+		size += entry.Size
+	}
+	var removed []CacheEntry
+	for _, entry := range entries {
+		expired := maxAge != 0 && time.Since(entry.LastUsed) > maxAge
+		oversized := maxSize != 0 && size > maxSize
+		if !expired && !oversized {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, entry.Key+".json")); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return removed, fmt.Errorf("failed to remove cache entry: %w", err)
+		}
+		size -= entry.Size
+		removed = append(removed, entry)
+	}
+	return removed, nil
+}
+
+// PurgeCache removes every entry of the cache in dir. Returns the removed entries.
+func PurgeCache(dir string) ([]CacheEntry, error) {
+	entries, err := ListCache(dir)
+	if err != nil {
+		return nil, err
+	}
+	for i, entry := range entries {
+		if err := os.Remove(filepath.Join(dir, entry.Key+".json")); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return entries[:i], fmt.Errorf("failed to remove cache entry: %w", err)
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,66 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPruneCache(t *testing.T) {
+	now := time.Now()
+	entries := []struct {
+		key      string
+		size     int
+		lastUsed time.Time
+	}{
+		{key: "old", size: 10, lastUsed: now.Add(-48 * time.Hour)},
+		{key: "recent", size: 20, lastUsed: now.Add(-2 * time.Hour)},
+		{key: "new", size: 30, lastUsed: now},
+	}
+	tests := []struct {
+		name        string
+		maxAge      time.Duration
+		maxSize     int64
+		wantRemoved []string
+	}{
+		{name: "no limits"},
+		{name: "max age", maxAge: 24 * time.Hour, wantRemoved: []string{"old"}},
+		{name: "max size removes least recently used", maxSize: 40, wantRemoved: []string{"old", "recent"}},
+		{name: "max size already met", maxSize: 60},
+		{name: "both limits", maxAge: time.Hour, maxSize: 100, wantRemoved: []string{"old", "recent"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, entry := range entries {
+				file := filepath.Join(dir, entry.key+".json")
+				if err := os.WriteFile(file, make([]byte, entry.size), 0600); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Chtimes(file, entry.lastUsed, entry.lastUsed); err != nil {
+					t.Fatal(err)
+				}
+			}
+			removed, err := PruneCache(dir, tt.maxAge, tt.maxSize)
+			if err != nil {
+				t.Fatalf("PruneCache() error = %v", err)
+			}
+			var gotRemoved []string
+			for _, entry := range removed {
+				gotRemoved = append(gotRemoved, entry.Key)
+			}
+			if !reflect.DeepEqual(gotRemoved, tt.wantRemoved) {
+				t.Errorf("PruneCache() removed %v, want %v", gotRemoved, tt.wantRemoved)
+			}
+			left, err := ListCache(dir)
+			if err != nil {
+				t.Fatalf("ListCache() error = %v", err)
+			}
+			if len(left)+len(removed) != len(entries) {
+				t.Errorf("ListCache() = %v after removing %v", left, removed)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package generator
+
+import (
+	"encoding/json"
+	"sort"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// canonicalize sorts every list in the swagger doc whose order has no meaning, so docs with the same content
+// are encoded the same. Maps are already encoded with sorted keys. Tags, consumes, produces, schemes, required
+// properties, parameters, and x-kubernetes-group-version-kind lists are sorted. Enums and allOf, anyOf, and oneOf
+// members are kept in order since their order is shown to readers.
+func canonicalize(swagger *spec.Swagger) {
+	sortTags(swagger.Tags)
+	sort.Strings(swagger.Consumes)
+	sort.Strings(swagger.Produces)
+	sort.Strings(swagger.Schemes)
+	sortGVKExtension(swagger.Extensions)
+	if swagger.Paths != nil {
+		for name, item := range swagger.Paths.Paths {
+			sortParameters(item.Parameters)
+			for _, op := range pathOperations(&item) {
+				if *op == nil {
+					continue
+				}
+				sort.Strings((*op).Tags)
+				sort.Strings((*op).Consumes)
+				sort.Strings((*op).Produces)
+				sort.Strings((*op).Schemes)
+				sortParameters((*op).Parameters)
+				sortGVKExtension((*op).Extensions)
+			}
+			swagger.Paths.Paths[name] = item
+		}
+	}
+	walkSchemas(swagger, func(_ string, schema *spec.Schema) {
+		sort.Strings(schema.Required)
+		sortGVKExtension(schema.Extensions)
+	})
+}
+
+func sortTags(tags []spec.Tag) {
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+}
+
+// sortParameters sorts the parameters by location then name, references to shared parameters go last.
+func sortParameters(params []spec.Parameter) {
+	sort.SliceStable(params, func(i, j int) bool {
+		a, b := params[i], params[j]
+		if aRef, bRef := a.Ref.String() != "", b.Ref.String() != ""; aRef != bRef {
+			return bRef
+		}
+		if a.In != b.In {
+			return a.In < b.In
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Ref.String() < b.Ref.String()
+	})
+}
+
+// sortGVKExtension sorts the GroupVersionKinds of the x-kubernetes-group-version-kind extension by their encoding.
+func sortGVKExtension(extensions spec.Extensions) {
+	gvks, ok := extensions[extensionGVK].([]interface{})
+	if !ok {
+		return
+	}
+	encoded := make(map[int]string, len(gvks))
+	for i, gvk := range gvks {
+		data, _ := json.Marshal(gvk)
+		encoded[i] = string(data)
+	}
+	indexes := make([]int, len(gvks))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool { return encoded[indexes[i]] < encoded[indexes[j]] })
+	sorted := make([]interface{}, len(gvks))
+	for i, index := range indexes {
+		sorted[i] = gvks[index]
+	}
+	extensions[extensionGVK] = sorted
+}
@@ -0,0 +1,64 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	helmChartAPIVersion = "helm.cattle.io/v1"
+	helmChartKind       = "HelmChart"
+	// helmChartNamespace is the namespace k3s' helm-controller watches for HelmCharts.
+	helmChartNamespace = "kube-system"
+)
+
+// helmChartObjects converts charts in the form REPO_URL/NAME[@VERSION] to HelmChart objects installed by
+// k3s' helm-controller. The version defaults to version, or the latest version when both are empty.
+func helmChartObjects(charts []string, version string) ([]runtime.Object, error) {
+	if version != "" && len(charts) != 1 {
+		return nil, fmt.Errorf("a chart version can only be set when installing a single chart, use REPO_URL/NAME@VERSION instead")
+	}
+	objs := make([]runtime.Object, 0, len(charts))
+	for _, chart := range charts {
+		chartVersion := version
+		if i := strings.LastIndex(chart, "@"); i > strings.LastIndex(chart, "/") {
+			chart, chartVersion = chart[:i], chart[i+1:]
+		}
+		i := strings.LastIndex(chart, "/")
+		if i <= 0 || i == len(chart)-1 {
+			return nil, fmt.Errorf("invalid chart '%s' must be REPO_URL/NAME[@VERSION]", chart)
+		}
+		repo, name := chart[:i], chart[i+1:]
+		spec := map[string]interface{}{
+			"chart":           name,
+			"targetNamespace": name,
+			"createNamespace": true,
+		}
+		if strings.HasPrefix(repo, "oci://") {
+			spec["chart"] = chart
+		} else {
+			spec["repo"] = repo
+		}
+		if chartVersion != "" {
+			spec["version"] = chartVersion
+		}
+		objs = append(objs, &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": helmChartAPIVersion,
+			"kind":       helmChartKind,
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": helmChartNamespace,
+			},
+			"spec": spec,
+		}})
+	}
+	return objs, nil
+}
+
+// helmChartJobName returns the name of the job helm-controller runs to install a HelmChart.
+func helmChartJobName(chartName string) string {
+	return "helm-install-" + chartName
+}
@@ -0,0 +1,161 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// docRefs are the definitions, parameters, and responses of a swagger doc used by some of its paths.
+type docRefs struct {
+	definitions map[string]bool
+	parameters  map[string]bool
+	responses   map[string]bool
+}
+
+// ChunkSwagger splits the swagger doc into docs with at most maxDefinitions definitions each. The paths of a kind
+// are kept together and each doc holds every definition, parameter, and response its paths reference, so shared
+// definitions such as ObjectMeta are repeated in each doc instead of referenced across docs.
+// Docs without paths are split by the definitions of each kind. The doc is returned as is if it is small enough.
+func ChunkSwagger(swagger *spec.Swagger, maxDefinitions int) ([]*spec.Swagger, error) {
+	if maxDefinitions <= 0 || len(swagger.Definitions) <= maxDefinitions {
+		return []*spec.Swagger{swagger}, nil
+	}
+
+	// group the paths, or for docs without paths the definitions, by kind
+	units := map[string][]interface{}{}
+	unitPaths := map[string][]string{}
+	if swagger.Paths != nil && len(swagger.Paths.Paths) != 0 {
+		for _, pathName := range sortedKeys(swagger.Paths.Paths) {
+			item := swagger.Paths.Paths[pathName]
+			kind := pathKind(item)
+			units[kind] = append(units[kind], item)
+			unitPaths[kind] = append(unitPaths[kind], pathName)
+		}
+	} else {
+		for _, name := range sortedKeys(swagger.Definitions) {
+			def := swagger.Definitions[name]
+			if kind := definitionKind(def); kind != "" {
+				units[kind] = append(units[kind], spec.Schema{SchemaProps: spec.SchemaProps{Ref: spec.MustCreateRef(definitionRefPrefix + name)}})
+			}
+		}
+	}
+
+	var chunks []*spec.Swagger
+	var current *docRefs
+	var currentKinds []string
+	flush := func() {
+		if current != nil {
+			chunks = append(chunks, chunkDoc(swagger, current, currentKinds, unitPaths))
+		}
+		current, currentKinds = nil, nil
+	}
+	for _, kind := range sortedKeys(units) {
+		refs, err := referencedBy(swagger, units[kind])
+		if err != nil {
+			return nil, err
+		}
+		if len(refs.definitions) > maxDefinitions {
+			name := kind
+			if name == "" {
+				name = "paths without a kind"
+			}
+			return nil, fmt.Errorf("%s needs %d definitions, more than the %d allowed in a single doc", name, len(refs.definitions), maxDefinitions)
+		}
+		if current != nil && len(mergeRefs(current, refs).definitions) > maxDefinitions {
+			flush()
+		}
+		if current == nil {
+			current = refs
+		} else {
+			current = mergeRefs(current, refs)
+		}
+		currentKinds = append(currentKinds, kind)
+	}
+	flush()
+	return chunks, nil
+}
+
+// referencedBy returns everything in the swagger doc that the values reference, directly or transitively.
+func referencedBy(swagger *spec.Swagger, values []interface{}) (*docRefs, error) {
+	refs := &docRefs{definitions: map[string]bool{}, parameters: map[string]bool{}, responses: map[string]bool{}}
+	queue := values
+	for len(queue) != 0 {
+		value := queue[0]
+		queue = queue[1:]
+		found, err := localRefs(value, "#/")
+		if err != nil {
+			return nil, fmt.Errorf("failed to find references: %w", err)
+		}
+		for _, ref := range found {
+			section, name, _ := strings.Cut(ref, "/")
+			switch section {
+			case "definitions":
+				if def, ok := swagger.Definitions[name]; ok && !refs.definitions[name] {
+					refs.definitions[name] = true
+					queue = append(queue, def)
+				}
+			case "parameters":
+				if param, ok := swagger.Parameters[name]; ok && !refs.parameters[name] {
+					refs.parameters[name] = true
+					queue = append(queue, param)
+				}
+			case "responses":
+				if response, ok := swagger.Responses[name]; ok && !refs.responses[name] {
+					refs.responses[name] = true
+					queue = append(queue, response)
+				}
+			}
+		}
+	}
+	return refs, nil
+}
+
+func mergeRefs(a, b *docRefs) *docRefs {
+	merged := &docRefs{definitions: map[string]bool{}, parameters: map[string]bool{}, responses: map[string]bool{}}
+	for _, refs := range []*docRefs{a, b} {
+		for name := range refs.definitions {
+			merged.definitions[name] = true
+		}
+		for name := range refs.parameters {
+			merged.parameters[name] = true
+		}
+		for name := range refs.responses {
+			merged.responses[name] = true
+		}
+	}
+	return merged
+}
+
+// chunkDoc returns a copy of the swagger doc with only the paths of the kinds and what they reference.
+func chunkDoc(swagger *spec.Swagger, refs *docRefs, kinds []string, unitPaths map[string][]string) *spec.Swagger {
+	chunk := *swagger
+	chunk.Paths = &spec.Paths{Paths: map[string]spec.PathItem{}}
+	if swagger.Paths != nil {
+		chunk.Paths.VendorExtensible = swagger.Paths.VendorExtensible
+	}
+	for _, kind := range kinds {
+		for _, pathName := range unitPaths[kind] {
+			chunk.Paths.Paths[pathName] = swagger.Paths.Paths[pathName]
+		}
+	}
+	chunk.Definitions = spec.Definitions{}
+	for name := range refs.definitions {
+		chunk.Definitions[name] = swagger.Definitions[name]
+	}
+	chunk.Parameters, chunk.Responses = nil, nil
+	for name := range refs.parameters {
+		if chunk.Parameters == nil {
+			chunk.Parameters = map[string]spec.Parameter{}
+		}
+		chunk.Parameters[name] = swagger.Parameters[name]
+	}
+	for name := range refs.responses {
+		if chunk.Responses == nil {
+			chunk.Responses = map[string]spec.Response{}
+		}
+		chunk.Responses[name] = swagger.Responses[name]
+	}
+	return &chunk
+}
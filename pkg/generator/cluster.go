@@ -0,0 +1,791 @@
+package generator
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/docker/go-connections/nat"
+	"github.com/rancher/wrangler/v2/pkg/crd"
+	"go.uber.org/zap"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const (
+	// EngineDocker runs kube-apiserver in a k3s docker container.
+	EngineDocker = "docker"
+	// EngineEnvtest runs kube-apiserver and etcd as local processes using controller-runtime's envtest.
+	EngineEnvtest = "envtest"
+
+	defaultK3sPort = "6443"
+	// readyzPath is kube-apiserver's readiness endpoint.
+	readyzPath = "/readyz"
+	// DefaultImage is the k3s image run by EngineDocker when no image is provided.
+	DefaultImage = "rancher/k3s:v1.27.5-k3s1"
+
+	// DefaultContainerName is the name of the container run by EngineDocker when no name is provided.
+	DefaultContainerName = "crd-swagger"
+	// keptNameSuffixLength is the length of the random suffix added to the names of kept containers.
+	keptNameSuffixLength = 5
+)
+
+const (
+	// K3sRegistriesPath is where k3s reads its registries.yaml.
+	K3sRegistriesPath = "/etc/rancher/k3s/registries.yaml"
+	// K3sCABundlePath is where the K3sCABundle is mounted, registries.yaml can reference it with tls.ca_file.
+	K3sCABundlePath = "/etc/rancher/k3s/registry-ca.pem"
+)
+
+// k3sWritablePaths are the paths k3s writes to that are mounted as volumes when the root filesystem is read-only.
+var k3sWritablePaths = []string{"/run", "/var/run", "/tmp", "/etc/rancher", "/var/lib/rancher", "/var/lib/kubelet", "/var/lib/cni", "/var/log"}
+
+// k3sCapabilities are the capabilities added to an unprivileged cluster container, k3s needs them to mount its
+// cgroups and volumes, set up iptables rules for its network, and raise the limits of its processes.
+var k3sCapabilities = []string{"SYS_ADMIN", "NET_ADMIN", "NET_RAW", "SYS_RESOURCE", "SYS_PTRACE"}
+
+// errEmptyKubeconfig is returned when the kubeconfig is copied from the container before k3s has written it.
+var errEmptyKubeconfig = errors.New("k3s kubeConfig is empty")
+
+// cluster is a kube-apiserver that CRDs can be installed into and a swagger doc retrieved from.
+type cluster interface {
+	start(ctx context.Context) error
+	stop(ctx context.Context) error
+	ensureCRD(ctx context.Context, crds []*apiextv1.CustomResourceDefinition) error
+	getSwagger(ctx context.Context) (*spec.Swagger, error)
+	applyManifests(ctx context.Context, objs []runtime.Object) error
+}
+
+// newCluster returns the cluster implementation for the requested engine.
+func newCluster(opts *Options, timer *phaseTimer) (cluster, error) {
+	client := apiClient{readyTimeout: opts.ClusterReadyTimeout, discoveryTimeout: opts.DiscoveryTimeout, manifestTimeout: opts.ManifestTimeout}
+	if opts.OpenAPIURL != "" {
+		if len(opts.ApplyManifests) != 0 || len(opts.Charts) != 0 {
+			return nil, fmt.Errorf("manifests and charts can not be applied when using an openapi URL")
+		}
+		return &openAPIURLSource{url: opts.OpenAPIURL, opts: opts}, nil
+	}
+	if opts.Server != "" || opts.ClusterProvider != nil {
+		return &serverCluster{apiClient: client, opts: opts}, nil
+	}
+	if err := resolveImage(opts); err != nil {
+		return nil, err
+	}
+	switch opts.Engine {
+	case EngineDocker:
+		switch opts.RestartPolicy {
+		case "no", "on-failure", "unless-stopped", "always":
+		default:
+			return nil, fmt.Errorf("unknown restart policy '%s' must be one of [no, on-failure, unless-stopped, always]", opts.RestartPolicy)
+		}
+		for _, env := range opts.ContainerEnv {
+			if !strings.Contains(env, "=") {
+				return nil, fmt.Errorf("invalid container environment variable '%s' must be KEY=VALUE", env)
+			}
+		}
+		if opts.Privileged && (opts.SeccompProfile != "" || opts.AppArmorProfile != "") {
+			return nil, fmt.Errorf("seccomp and apparmor profiles are ignored by privileged containers")
+		}
+		for _, file := range []*string{&opts.K3sRegistries, &opts.K3sCABundle} {
+			if *file == "" {
+				continue
+			}
+			// bind mounts require an absolute path
+			absPath, err := filepath.Abs(*file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get absolute path of '%s': %w", *file, err)
+			}
+			if _, err := os.Stat(absPath); err != nil {
+				return nil, fmt.Errorf("failed to find file to mount into the cluster container: %w", err)
+			}
+			*file = absPath
+		}
+		return &dockerCluster{apiClient: client, opts: opts, timer: timer, port: opts.ClusterPort}, nil
+	case EngineEnvtest:
+		if len(opts.ContainerEnv) != 0 {
+			return nil, fmt.Errorf("container environment variables can only be set with the %s engine", EngineDocker)
+		}
+		if opts.K3sRegistries != "" || opts.K3sCABundle != "" {
+			return nil, fmt.Errorf("k3s registries and CA bundles can only be mounted with the %s engine", EngineDocker)
+		}
+		if len(opts.Charts) != 0 {
+			return nil, fmt.Errorf("charts can only be installed with the %s engine, envtest has no helm-controller", EngineDocker)
+		}
+		return &envtestCluster{apiClient: client, timer: timer, featureGates: opts.FeatureGates}, nil
+	default:
+		return nil, fmt.Errorf("unknown engine '%s' must be one of [%s, %s]", opts.Engine, EngineDocker, EngineEnvtest)
+	}
+}
+
+// apiClient holds the operations shared by all clusters once a clientset is available.
+type apiClient struct {
+	cs  *clientset.Clientset
+	cfg *rest.Config
+	// readyTimeout is how long to wait for the cluster to start
+	readyTimeout time.Duration
+	// discoveryTimeout is how long to wait for installed CRDs to be established
+	discoveryTimeout time.Duration
+	// manifestTimeout is how long to wait for applied manifests to be ready
+	manifestTimeout time.Duration
+	// healthCheck is called while waiting on the cluster and stops the wait if it returns an error.
+	healthCheck func(ctx context.Context) error
+}
+
+type dockerCluster struct {
+	apiClient
+	opts  *Options
+	timer *phaseTimer
+	port  string
+	// containerHost is the container's IP address when kube-apiserver is dialed directly instead of through a host port
+	containerHost string
+	containerID   string
+	// restartCount is the number of restarts the container had before this run started it
+	restartCount int
+	cli          *client.Client
+	// startFailed is whether start failed, the container is removed even when it is kept or reused since a
+	// container that never became ready is of no later use and would be in the way of the next run
+	startFailed bool
+}
+
+func (d *dockerCluster) start(ctx context.Context) (err error) {
+	defer func() { d.startFailed = err != nil }()
+	d.cli, err = client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return fmt.Errorf("failed to create docker client %w", err)
+	}
+	d.timer.addLogFields(zap.String("image", d.opts.Image))
+	reused := false
+	if d.opts.ReuseContainer {
+		reused, err = d.findContainer(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	if !reused {
+		if d.port == "" {
+			if d.port, err = freePort(); err != nil {
+				return err
+			}
+		}
+		d.timer.start(phasePull)
+		if d.opts.ImageTar != "" {
+			err = d.loadImage(ctx)
+		} else {
+			err = d.pullK3sImage(ctx)
+		}
+		if err != nil {
+			return err
+		}
+		d.timer.done(phasePull)
+		d.timer.start(phaseContainerStart)
+		if err = d.createContainer(ctx, true); err != nil {
+			return err
+		}
+	}
+	if reused {
+		d.timer.start(phaseContainerStart)
+	}
+	err = d.startContainer(ctx)
+	if err != nil && !reused && isPortBindError(err) {
+		zap.S().Warnf("Failed to publish kube-apiserver on host port %s, dialing the container directly instead: %v", d.port, err)
+		err = d.recreateUnpublished(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	if d.containerHost == "" && d.port == "" {
+		// a reused container that was created without a published port
+		if err = d.recreateUnpublished(ctx); err != nil {
+			return err
+		}
+	}
+	d.timer.addLogFields(zap.String("containerID", d.containerID))
+	d.timer.done(phaseContainerStart)
+	d.timer.start(phaseClusterReady)
+	configData, err := d.getKubeCfgFromContainer(ctx)
+	if err != nil {
+		d.dumpLogs()
+		return d.checkContainerExited(ctx, err)
+	}
+	restCfg, err := createRESTConfig(configData, d.containerHost, d.port)
+	if err != nil {
+		return err
+	}
+	if d.opts.PersistCredentials {
+		if err := persistKubeConfig(configData, restCfg.Host); err != nil {
+			return err
+		}
+	}
+
+	d.cfg = restCfg
+	d.cs, err = clientset.NewForConfig(restCfg)
+	if err != nil {
+		return fmt.Errorf("failed to create new clientset: %w", err)
+	}
+	d.healthCheck = d.checkRestartLoop
+
+	if err := d.waitForCluster(ctx); err != nil {
+		d.dumpLogs()
+		return d.checkContainerExited(ctx, err)
+	}
+	d.timer.done(phaseClusterReady)
+
+	return nil
+}
+
+func (d *dockerCluster) stop(ctx context.Context) error {
+	if d.cli == nil {
+		// the docker client could not be created
+		return nil
+	}
+	defer d.cli.Close()
+	if (d.opts.KeepContainer || d.opts.ReuseContainer) && !d.startFailed {
+		zap.S().Infof("Leaving container '%s' running for later use.", d.opts.ContainerName)
+		return nil
+	}
+	if d.containerID == "" {
+		// the cluster failed to start before its container was created
+		return nil
+	}
+	// cleanup cluster container
+	err := d.cli.ContainerStop(ctx, d.containerID, container.StopOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to stop container: %w", err)
+	}
+	err = d.cli.ContainerRemove(ctx, d.containerID, types.ContainerRemoveOptions{RemoveVolumes: true})
+	if err != nil {
+		return fmt.Errorf("failed to remove container: %w", err)
+	}
+	return nil
+}
+
+func (d *dockerCluster) pullK3sImage(ctx context.Context) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, d.opts.PullTimeout)
+	defer cancel()
+	auth, err := encodedRegistryAuth(d.opts.Image, d.opts.RegistryAuth)
+	if err != nil {
+		return err
+	}
+	reader, err := d.cli.ImagePull(timeoutCtx, d.opts.Image, types.ImagePullOptions{RegistryAuth: auth})
+	if err != nil {
+		return fmt.Errorf("failed to pull image: %w", err)
+	}
+	pullOutput := d.opts.PullOutput
+	if pullOutput == nil {
+		pullOutput = io.Discard
+	}
+	_, err = io.Copy(pullOutput, reader)
+	if err != nil {
+		return fmt.Errorf("failed to read from image pull: %w", err)
+	}
+	return reader.Close()
+}
+
+// loadImage loads the cluster image from a tarball created by docker save instead of pulling it.
+func (d *dockerCluster) loadImage(ctx context.Context) error {
+	file, err := os.Open(d.opts.ImageTar)
+	if err != nil {
+		return fmt.Errorf("failed to open image tarball '%s': %w", d.opts.ImageTar, err)
+	}
+	defer file.Close()
+	timeoutCtx, cancel := context.WithTimeout(ctx, d.opts.PullTimeout)
+	defer cancel()
+	resp, err := d.cli.ImageLoad(timeoutCtx, file, d.opts.PullOutput == nil)
+	if err != nil {
+		return fmt.Errorf("failed to load image tarball '%s': %w", d.opts.ImageTar, err)
+	}
+	defer resp.Body.Close()
+	loadOutput := d.opts.PullOutput
+	if loadOutput == nil {
+		loadOutput = io.Discard
+	}
+	_, err = io.Copy(loadOutput, resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read from image load: %w", err)
+	}
+	return nil
+}
+
+func (d *dockerCluster) createContainer(ctx context.Context, publish bool) error {
+	securityOpts, err := containerSecurityOpts(d.opts)
+	if err != nil {
+		return err
+	}
+	var mounts []mount.Mount
+	if d.opts.ReadOnlyRootFS {
+		// anonymous volumes are used instead of tmpfs since docker can not copy the kubeconfig out of a tmpfs
+		for _, path := range k3sWritablePaths {
+			mounts = append(mounts, mount.Mount{Type: mount.TypeVolume, Target: path})
+		}
+	}
+	if d.opts.K3sRegistries != "" {
+		mounts = append(mounts, mount.Mount{Type: mount.TypeBind, Source: d.opts.K3sRegistries, Target: K3sRegistriesPath, ReadOnly: true})
+	}
+	if d.opts.K3sCABundle != "" {
+		mounts = append(mounts, mount.Mount{Type: mount.TypeBind, Source: d.opts.K3sCABundle, Target: K3sCABundlePath, ReadOnly: true})
+	}
+	hostConfig := &container.HostConfig{
+		Privileged:     d.opts.Privileged,
+		RestartPolicy:  container.RestartPolicy{Name: d.opts.RestartPolicy},
+		ReadonlyRootfs: d.opts.ReadOnlyRootFS,
+		SecurityOpt:    securityOpts,
+	}
+	if !d.opts.Privileged {
+		// a privileged container already has every capability, device, and a writable cgroup filesystem.
+		// Otherwise k3s gets its own cgroup namespace with the cgroup filesystem mounted writable to create the cgroups
+		// of its processes in, and /dev/kmsg which the kubelet reads kernel messages from.
+		hostConfig.CapAdd = k3sCapabilities
+		hostConfig.CgroupnsMode = container.CgroupnsModePrivate
+		mounts = append(mounts, mount.Mount{Type: mount.TypeBind, Source: "/sys/fs/cgroup", Target: "/sys/fs/cgroup"})
+		hostConfig.Devices = []container.DeviceMapping{{PathOnHost: "/dev/kmsg", PathInContainer: "/dev/kmsg", CgroupPermissions: "r"}}
+	}
+	hostConfig.Mounts = mounts
+	if publish {
+		hostConfig.PortBindings = nat.PortMap{nat.Port(defaultK3sPort): {{HostIP: "127.0.0.1", HostPort: d.port}}}
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	resp, err := d.cli.ContainerCreate(timeoutCtx,
+		&container.Config{
+			Image:      d.opts.Image,
+			Entrypoint: []string{"/bin/k3s", "server"},
+			Cmd:        k3sArgs(d.opts),
+			Env:        d.opts.ContainerEnv,
+			ExposedPorts: nat.PortSet{
+				defaultK3sPort: struct{}{},
+			},
+		},
+		hostConfig, nil, nil, d.opts.ContainerName)
+	if err != nil {
+		return fmt.Errorf("failed to create k3s container: %w", err)
+	}
+	d.containerID = resp.ID
+	return nil
+}
+
+// containerSecurityOpts returns the docker security options for the hardening options.
+// Seccomp profiles are read from a file since docker expects the profile contents.
+func containerSecurityOpts(opts *Options) ([]string, error) {
+	var securityOpts []string
+	if opts.SeccompProfile != "" {
+		profile := opts.SeccompProfile
+		if profile != "unconfined" {
+			data, err := os.ReadFile(profile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read seccomp profile '%s': %w", profile, err)
+			}
+			profile = string(data)
+		}
+		securityOpts = append(securityOpts, "seccomp="+profile)
+	}
+	if opts.AppArmorProfile != "" {
+		securityOpts = append(securityOpts, "apparmor="+opts.AppArmorProfile)
+	}
+	if opts.NoNewPrivileges {
+		securityOpts = append(securityOpts, "no-new-privileges")
+	}
+	return securityOpts, nil
+}
+
+// k3sArgs returns the arguments for k3s server.
+func k3sArgs(opts *Options) []string {
+	var args []string
+	if len(opts.FeatureGates) != 0 {
+		args = append(args, "--kube-apiserver-arg=feature-gates="+strings.Join(opts.FeatureGates, ","))
+	}
+	return args
+}
+
+// freePort asks the OS for a free port on the loopback interface.
+func freePort() (string, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", fmt.Errorf("failed to find a free port: %w", err)
+	}
+	defer listener.Close()
+	_, port, err := net.SplitHostPort(listener.Addr().String())
+	if err != nil {
+		return "", fmt.Errorf("failed to parse free port: %w", err)
+	}
+	zap.S().Infof("Binding kube-apiserver to free port %s.", port)
+	return port, nil
+}
+
+// defaultContainerName returns the name of the container when no name is provided. A container that is kept but not
+// reused gets a random suffix since no later run removes it and the next run would fail to create its container.
+func defaultContainerName(opts *Options) string {
+	if opts.KeepContainer && !opts.ReuseContainer {
+		return DefaultContainerName + "-" + rand.String(keptNameSuffixLength)
+	}
+	return DefaultContainerName
+}
+
+// findContainer looks for a container from a previous run and uses it if one exists. A container running another
+// image, e.g. after the Kubernetes version was changed, is removed so a new one is created.
+func (d *dockerCluster) findContainer(ctx context.Context) (bool, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	info, err := d.cli.ContainerInspect(timeoutCtx, d.opts.ContainerName)
+	if errdefs.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to inspect container '%s': %w", d.opts.ContainerName, err)
+	}
+	if info.Config != nil && info.Config.Image != d.opts.Image {
+		zap.S().Infof("Existing container '%s' runs image %s instead of %s, recreating it.", d.opts.ContainerName, info.Config.Image, d.opts.Image)
+		err := d.cli.ContainerRemove(ctx, info.ID, types.ContainerRemoveOptions{RemoveVolumes: true, Force: true})
+		if err != nil {
+			return false, fmt.Errorf("failed to remove container '%s': %w", d.opts.ContainerName, err)
+		}
+		return false, nil
+	}
+	// use the host port the existing container was created with, if it has none the container is dialed directly
+	d.port = ""
+	if info.HostConfig != nil {
+		if bindings := info.HostConfig.PortBindings[nat.Port(defaultK3sPort)]; len(bindings) > 0 {
+			d.port = bindings[0].HostPort
+		}
+	}
+	zap.S().Infof("Reusing existing container '%s'.", d.opts.ContainerName)
+	d.containerID = info.ID
+	d.restartCount = info.RestartCount
+	return true, nil
+}
+
+// recreateUnpublished replaces the container with one that does not publish a host port and
+// dials kube-apiserver using the container's IP address. This is used on hosts where the port can not be bound.
+func (d *dockerCluster) recreateUnpublished(ctx context.Context) error {
+	if d.containerHost == "" && d.port != "" {
+		err := d.cli.ContainerRemove(ctx, d.containerID, types.ContainerRemoveOptions{RemoveVolumes: true, Force: true})
+		if err != nil {
+			return fmt.Errorf("failed to remove container: %w", err)
+		}
+		if err := d.createContainer(ctx, false); err != nil {
+			return err
+		}
+		if err := d.startContainer(ctx); err != nil {
+			return err
+		}
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	info, err := d.cli.ContainerInspect(timeoutCtx, d.containerID)
+	if err != nil {
+		return fmt.Errorf("failed to inspect container: %w", err)
+	}
+	if info.NetworkSettings != nil {
+		d.containerHost = info.NetworkSettings.IPAddress
+		for _, network := range info.NetworkSettings.Networks {
+			if d.containerHost == "" && network != nil {
+				d.containerHost = network.IPAddress
+			}
+		}
+	}
+	if d.containerHost == "" {
+		return fmt.Errorf("failed to find the IP address of the k3s container")
+	}
+	d.port = defaultK3sPort
+	zap.S().Infof("Dialing kube-apiserver at the container address %s.", net.JoinHostPort(d.containerHost, d.port))
+	return nil
+}
+
+// isPortBindError reports if err is docker failing to publish a port on the host.
+func isPortBindError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "port is already allocated") || strings.Contains(msg, "address already in use") ||
+		strings.Contains(msg, "cannot assign requested address") || (strings.Contains(msg, "permission denied") && strings.Contains(msg, "bind"))
+}
+
+func (d *dockerCluster) startContainer(ctx context.Context) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	if err := d.cli.ContainerStart(timeoutCtx, d.containerID, types.ContainerStartOptions{}); err != nil {
+		return fmt.Errorf("failed to start k3s container: %w", err)
+	}
+	return nil
+}
+
+// checkRestartLoop returns an error if docker has restarted the container since it was started.
+// The container is expected to stay up during generation so any restart means k3s is crashing.
+func (d *dockerCluster) checkRestartLoop(ctx context.Context) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	info, err := d.cli.ContainerInspect(timeoutCtx, d.containerID)
+	if err != nil {
+		return fmt.Errorf("failed to inspect container: %w", err)
+	}
+	if info.State != nil && info.State.Restarting {
+		return fmt.Errorf("k3s container is stuck restarting after %d restarts", info.RestartCount)
+	}
+	if info.RestartCount > d.restartCount {
+		return fmt.Errorf("k3s container restarted %d times while waiting for the cluster", info.RestartCount-d.restartCount)
+	}
+	return nil
+}
+
+// checkContainerExited adds an explanation to a startup error if the container is no longer running.
+func (d *dockerCluster) checkContainerExited(ctx context.Context, startErr error) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	info, err := d.cli.ContainerInspect(timeoutCtx, d.containerID)
+	if err != nil || info.State == nil || info.State.Running {
+		return startErr
+	}
+	if d.opts.Privileged {
+		return fmt.Errorf("k3s container exited with code %d: %w", info.State.ExitCode, startErr)
+	}
+	return fmt.Errorf("k3s container exited with code %d, k3s may need to run with --privileged on this host: %w", info.State.ExitCode, startErr)
+}
+
+// dumpLogs saves the container's logs to Options.DebugLogsDir, or logs their last lines, so users can see why k3s
+// or what was installed into it failed. Failing to get the logs is only logged since the failure is reported anyway.
+func (d *dockerCluster) dumpLogs() {
+	// the generation context may be canceled or past its deadline by now
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	logOpts := types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true, Timestamps: true}
+	if d.opts.DebugLogsDir == "" {
+		logOpts.Tail = strconv.Itoa(containerLogTail)
+	}
+	reader, err := d.cli.ContainerLogs(timeoutCtx, d.containerID, logOpts)
+	if err != nil {
+		zap.S().Warnf("Failed to get the k3s container logs: %v", err)
+		return
+	}
+	defer reader.Close()
+	if d.opts.DebugLogsDir != "" {
+		file, err := saveContainerLogs(d.opts.DebugLogsDir, d.opts.ContainerName, reader)
+		if err != nil {
+			zap.S().Warnf("Failed to save the k3s container logs: %v", err)
+			return
+		}
+		zap.S().Warnf("Saved the k3s container logs to '%s'.", file)
+		return
+	}
+	var logs bytes.Buffer
+	if _, err := stdcopy.StdCopy(&logs, &logs, reader); err != nil {
+		zap.S().Warnf("Failed to read the k3s container logs: %v", err)
+	}
+	zap.S().Warnf("Last %d lines of the k3s container logs, pass --debug-logs-dir to save all of them:\n%s", containerLogTail, strings.TrimRight(logs.String(), "\n"))
+}
+
+// dumpLogsOnFailure dumps the logs of a docker cluster when a phase after its start failed because of the cluster,
+// e.g. a chart installed into it never became ready. Failures while starting dump the logs themselves.
+func dumpLogsOnFailure(ctx context.Context, c cluster, timer *phaseTimer, err error) {
+	d, ok := c.(*dockerCluster)
+	if !ok || err == nil || ctx.Err() != nil || !infrastructurePhases[timer.current] {
+		return
+	}
+	d.dumpLogs()
+}
+
+// saveContainerLogs writes the multiplexed container logs to a new file in dir and returns the file.
+func saveContainerLogs(dir, containerName string, logs io.Reader) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create debug logs dir: %w", err)
+	}
+	name := filepath.Join(dir, fmt.Sprintf("%s-%s.log", containerName, time.Now().UTC().Format("20060102T150405Z")))
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return "", fmt.Errorf("failed to create logs file: %w", err)
+	}
+	defer file.Close()
+	if _, err := stdcopy.StdCopy(file, file, logs); err != nil {
+		return "", fmt.Errorf("failed to write logs file: %w", err)
+	}
+	return name, nil
+}
+
+func (d *dockerCluster) getKubeCfgFromContainer(ctx context.Context) ([]byte, error) {
+	var reader io.ReadCloser
+	var err error
+	configFunc := func(context.Context) (bool, error) {
+		timeoutCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+		defer cancel()
+		reader, _, err = d.cli.CopyFromContainer(timeoutCtx, d.containerID, kubePath)
+		if err == nil {
+			return true, nil
+		}
+		if !errdefs.IsNotFound(err) {
+			return false, fmt.Errorf("failed to get kubeconfig from container: %w", err)
+		}
+		if err := d.checkRestartLoop(ctx); err != nil {
+			return false, err
+		}
+		zap.S().Info("waiting for k3s kubeconfig...")
+		return false, nil
+	}
+	err = wait.PollUntilContextTimeout(ctx, waitInterval, d.readyTimeout, true, configFunc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubeconfig from container after %v: %w", d.readyTimeout, err)
+	}
+
+	tarReader := tar.NewReader(reader)
+	defer reader.Close()
+	_, err = tarReader.Next()
+	if errors.Is(err, io.EOF) {
+		return nil, errEmptyKubeconfig
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to untar k3s kubeconfig: %w", err)
+	}
+	configData, err := io.ReadAll(tarReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read k3s kubeconfig: %w", err)
+	}
+	return configData, nil
+}
+
+// createRESTConfig creates a rest config from the k3s kubeconfig that dials host and port.
+// If host is empty the host from the kubeconfig is kept.
+func createRESTConfig(kubeConfig []byte, host, port string) (*rest.Config, error) {
+	restCfg, err := clientcmd.RESTConfigFromKubeConfig(kubeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create restconfig: %w", err)
+	}
+	k3sURL, err := url.Parse(restCfg.Host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cluster URL: %w", err)
+	}
+	if host == "" {
+		host, _, err = net.SplitHostPort(k3sURL.Host)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse cluster host: %w", err)
+		}
+	}
+	k3sURL.Host = net.JoinHostPort(host, port)
+	restCfg.Host = k3sURL.String()
+	return restCfg, nil
+}
+
+// persistKubeConfig writes the cluster's kubeconfig, pointed at host, to a new private directory
+// so the cluster can be accessed after generation. The file is only readable by the current user.
+func persistKubeConfig(kubeConfig []byte, host string) error {
+	config, err := clientcmd.Load(kubeConfig)
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	for _, cluster := range config.Clusters {
+		cluster.Server = host
+	}
+	dir, err := os.MkdirTemp("", "crd-swagger-")
+	if err != nil {
+		return fmt.Errorf("failed to create kubeconfig directory: %w", err)
+	}
+	kubeConfigPath := filepath.Join(dir, "kubeconfig.yaml")
+	// clientcmd.WriteToFile creates the file with 0600 permissions
+	if err := clientcmd.WriteToFile(*config, kubeConfigPath); err != nil {
+		return fmt.Errorf("failed to write kubeconfig: %w", err)
+	}
+	zap.S().Infof("Wrote cluster kubeconfig to '%s'.", kubeConfigPath)
+	return nil
+}
+
+func (d *apiClient) waitForCluster(ctx context.Context) error {
+	// wait for the cluster to become available before creating CRDs
+	poller := newPoller(d.readyTimeout)
+	discFunc := func(context.Context) (bool, error) {
+		if d.healthCheck != nil {
+			if err := d.healthCheck(ctx); err != nil {
+				return false, err
+			}
+		}
+		err := d.checkReady(ctx)
+		if err == nil {
+			return true, nil
+		}
+		zap.L().Info("waiting for cluster...")
+		return false, poller.retry(err)
+	}
+	err := poller.poll(ctx, discFunc)
+	if err != nil {
+		return fmt.Errorf("cluster failed to start: %w", err)
+	}
+	return nil
+}
+
+// checkReady returns an error unless kube-apiserver's /readyz reports that every readiness check passed, so CRDs
+// are not installed while the API server is only partly initialized. Servers that do not serve /readyz to the
+// credentials are checked by getting their version instead.
+func (d *apiClient) checkReady(ctx context.Context) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	body, err := d.cs.Discovery().RESTClient().Get().AbsPath(readyzPath).Do(timeoutCtx).Raw()
+	if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
+		_, err = d.cs.Discovery().ServerVersion()
+		return err
+	}
+	if err == nil {
+		return nil
+	}
+	// the body lists every check as [+]name ok or [-]name failed
+	var failed []string
+	for _, line := range strings.Split(string(body), "\n") {
+		if check, ok := strings.CutPrefix(line, "[-]"); ok && len(strings.Fields(check)) != 0 {
+			failed = append(failed, strings.Fields(check)[0])
+		}
+	}
+	if len(failed) != 0 {
+		return fmt.Errorf("kube-apiserver is not ready, failed checks [%s]", strings.Join(failed, ", "))
+	}
+	return err
+}
+
+// getClusterSwagger request an openapiv2 document from the cluster and converts it to a spec.Swagger doc for filtering.
+// The discovery client does not take a context so the request is not canceled with ctx.
+func (d *apiClient) getSwagger(context.Context) (*spec.Swagger, error) {
+	protoSwagger, err := d.cs.Discovery().OpenAPISchema()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get swagger from cluster: %w", err)
+	}
+	var swagger spec.Swagger
+	ok, err := swagger.FromGnostic(protoSwagger)
+	if err != nil || !ok {
+		return nil, fmt.Errorf("failed to convert protoSwagger struct: %w", err)
+	}
+	return &swagger, nil
+}
+
+// applyManifests applies the objects to the cluster and waits for them to be ready.
+func (d *apiClient) applyManifests(ctx context.Context, objs []runtime.Object) error {
+	return applyObjects(ctx, d.cfg, objs, d.manifestTimeout)
+}
+
+// ensureCRD adds the CRDs to the cluster and waits for each of their statuses to be ready.
+// The namespaces of conversion webhook services are created first.
+func (d *apiClient) ensureCRD(ctx context.Context, crds []*apiextv1.CustomResourceDefinition) error {
+	if err := d.ensureNamespaces(ctx, conversionNamespaces(crds)); err != nil {
+		return err
+	}
+	crdClient := d.cs.ApiextensionsV1().CustomResourceDefinitions()
+	err := crd.BatchCreateCRDs(ctx, crdClient, labels.Everything(), d.discoveryTimeout, crds)
+	if err != nil {
+		return fmt.Errorf("failed to batch create: %w", err)
+	}
+	return nil
+}
@@ -1,9 +1,10 @@
-package cmd
+package generator
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -49,11 +50,12 @@ func addGKFromOp(operation *spec.Operation, gks map[v1.GroupKind]bool) error {
 	return nil
 }
 
-func crdsFromInput(path string) (map[string]*apiextv1.CustomResourceDefinition, error) {
+func crdsFromInput(ctx context.Context, opts *Options) (map[string]*apiextv1.CustomResourceDefinition, error) {
 	allCRDs := map[string]*apiextv1.CustomResourceDefinition{}
+	path, recurse := opts.CRDSource, opts.Recurse
 
-	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
-		return allCRDs, crdsFromURL(path, allCRDs)
+	if isURL(path) {
+		return allCRDs, crdsFromURL(ctx, opts, path, allCRDs)
 	}
 	statInfo, err := os.Stat(path)
 	if err != nil {
@@ -62,11 +64,15 @@ func crdsFromInput(path string) (map[string]*apiextv1.CustomResourceDefinition,
 	if !statInfo.IsDir() {
 		return allCRDs, crdFromFile(path, allCRDs)
 	}
-	return allCRDs, crdsFromDir(path, allCRDs)
+	return allCRDs, crdsFromDir(path, recurse, allCRDs)
+}
+
+func isURL(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
 }
 
 // crdsFromDir recursively traverses the embedded yaml directory and find all CRD yamls.
-func crdsFromDir(dirName string, allCRDs map[string]*apiextv1.CustomResourceDefinition) error {
+func crdsFromDir(dirName string, recurse bool, allCRDs map[string]*apiextv1.CustomResourceDefinition) error {
 	// read all entries in the directory
 	crdFiles, err := os.ReadDir(dirName)
 	if err != nil {
@@ -82,11 +88,11 @@ func crdsFromDir(dirName string, allCRDs map[string]*apiextv1.CustomResourceDefi
 			}
 			continue
 		}
-		if !cmdFlags.recurse {
+		if !recurse {
 			continue
 		}
 		// if the entry is the dir recurse into that folder to get all crds
-		err := crdsFromDir(fullPath, allCRDs)
+		err := crdsFromDir(fullPath, recurse, allCRDs)
 		if err != nil {
 			return err
 		}
@@ -109,15 +115,29 @@ func crdFromFile(fullPath string, allCRDs map[string]*apiextv1.CustomResourceDef
 }
 
 func crdFromReader(reader io.Reader, allCRDs map[string]*apiextv1.CustomResourceDefinition) error {
-	crdObjs, err := yaml.UnmarshalWithJSONDecoder[*apiextv1.CustomResourceDefinition](reader)
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("failed to read yaml: %w", err)
+	}
+	crdObjs, err := yaml.UnmarshalWithJSONDecoder[*apiextv1.CustomResourceDefinition](bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed decode yaml: %w", err)
+	}
+	// the documents are decoded again for the fields the CRD types do not have
+	rawObjs, err := yaml.UnmarshalWithJSONDecoder[*selectableFieldsCRD](bytes.NewReader(data))
 	if err != nil {
 		return fmt.Errorf("failed decode yaml: %w", err)
 	}
-	for _, crdObj := range crdObjs {
+	for i, crdObj := range crdObjs {
 		if crdObj.Kind != crdKind {
 			// if the yaml is not a CRD skip it
 			continue
 		}
+		if i < len(rawObjs) {
+			if err := setSelectableFields(crdObj, rawObjs[i]); err != nil {
+				return err
+			}
+		}
 		if _, ok := allCRDs[crdObj.Name]; ok {
 			return fmt.Errorf("%w for '%s", errDuplicate, crdObj.Name)
 		}
@@ -126,13 +146,12 @@ func crdFromReader(reader io.Reader, allCRDs map[string]*apiextv1.CustomResource
 	return nil
 }
 
-func crdsFromURL(url string, allCRDs map[string]*apiextv1.CustomResourceDefinition) error {
-	resp, err := http.Get(url)
+func crdsFromURL(ctx context.Context, opts *Options, url string, allCRDs map[string]*apiextv1.CustomResourceDefinition) error {
+	data, err := fetchInput(ctx, opts, url)
 	if err != nil {
 		return fmt.Errorf("failed to get request YAML: %w", err)
 	}
-	defer resp.Body.Close()
-	err = crdFromReader(resp.Body, allCRDs)
+	err = crdFromReader(bytes.NewReader(data), allCRDs)
 	if err != nil {
 		return fmt.Errorf("failed to convert response: %w", err)
 	}
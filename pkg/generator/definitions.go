@@ -0,0 +1,94 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const definitionRefPrefix = "#/definitions/"
+
+// keepDefinitionsOnly removes every path, parameter, and response from the swagger doc and every definition
+// that is not for one of the desired GroupKinds or referenced by one, directly or transitively.
+func keepDefinitionsOnly(swagger *spec.Swagger, desiredGroupKinds map[v1.GroupKind]bool, versions map[v1.GroupKind]map[string]bool) error {
+	keep := map[string]bool{}
+	var queue []string
+	for name, def := range swagger.Definitions {
+		var gvks []schema.GroupVersionKind
+		if err := def.Extensions.GetObject(extensionGVK, &gvks); err != nil {
+			continue
+		}
+		for _, gvk := range gvks {
+			gk := v1.GroupKind{Group: gvk.Group, Kind: gvk.Kind}
+			if pinned, ok := versions[gk]; ok && !pinned[gvk.Version] {
+				continue
+			}
+			if _, ok := desiredGroupKinds[gk]; ok {
+				keep[name] = true
+				queue = append(queue, name)
+				break
+			}
+		}
+	}
+	for len(queue) != 0 {
+		name := queue[0]
+		queue = queue[1:]
+		refs, err := localRefs(swagger.Definitions[name], definitionRefPrefix)
+		if err != nil {
+			return fmt.Errorf("failed to find references of definition '%s': %w", name, err)
+		}
+		for _, ref := range refs {
+			if _, ok := swagger.Definitions[ref]; ok && !keep[ref] {
+				keep[ref] = true
+				queue = append(queue, ref)
+			}
+		}
+	}
+
+	for name := range swagger.Definitions {
+		if !keep[name] {
+			delete(swagger.Definitions, name)
+		}
+	}
+	// paths is required by the specification so it is left empty
+	swagger.Paths = &spec.Paths{Paths: map[string]spec.PathItem{}}
+	swagger.Parameters = nil
+	swagger.Responses = nil
+	return nil
+}
+
+// localRefs returns the names of everything with the ref prefix that the value references,
+// e.g. the definitions referenced by a schema for definitionRefPrefix.
+func localRefs(value interface{}, prefix string) ([]string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	var refs []string
+	var walk func(value interface{})
+	walk = func(value interface{}) {
+		switch value := value.(type) {
+		case map[string]interface{}:
+			if ref, ok := value["$ref"].(string); ok && strings.HasPrefix(ref, prefix) {
+				refs = append(refs, strings.TrimPrefix(ref, prefix))
+			}
+			for _, child := range value {
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range value {
+				walk(child)
+			}
+		}
+	}
+	walk(doc)
+	return refs, nil
+}
@@ -0,0 +1,116 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// maxReportedDifferences is the number of differing paths and definitions listed when a run is not deterministic.
+const maxReportedDifferences = 10
+
+// generateDeterministic generates the swagger doc twice from the same cluster and returns an error
+// if the two docs differ.
+func generateDeterministic(ctx context.Context, opts *Options, cluster cluster, timer *phaseTimer, crds []*apiextv1.CustomResourceDefinition) (*spec.Swagger, error) {
+	// a doc is returned along with the error when missing GroupKinds are ignored
+	first, missingErr := generateFromCluster(ctx, opts, cluster, timer, crds)
+	if first == nil {
+		return nil, missingErr
+	}
+	zap.S().Info("Generating the swagger doc a second time to verify it is deterministic.")
+	second, err := generateFromCluster(ctx, opts, cluster, timer, crds)
+	if second == nil {
+		return nil, err
+	}
+	diffs, err := swaggerDifferences(first, second)
+	if err != nil {
+		return nil, err
+	}
+	if len(diffs) != 0 {
+		if len(diffs) > maxReportedDifferences {
+			diffs = append(diffs[:maxReportedDifferences], fmt.Sprintf("and %d more", len(diffs)-maxReportedDifferences))
+		}
+		return nil, fmt.Errorf("swagger doc is not deterministic, generation passes differ in [%s]", strings.Join(diffs, ", "))
+	}
+	zap.S().Info("Both generation passes produced the same swagger doc.")
+	return first, missingErr
+}
+
+// swaggerDifferences returns the paths and definitions that differ between the two docs.
+// Docs are compared by their JSON encoding which sorts map keys so only real differences are reported.
+func swaggerDifferences(a, b *spec.Swagger) ([]string, error) {
+	var diffs []string
+	addDiffs := func(prefix string, aItems, bItems map[string]interface{}) error {
+		keys := map[string]interface{}{}
+		for key := range aItems {
+			keys[key] = nil
+		}
+		for key := range bItems {
+			keys[key] = nil
+		}
+		for _, key := range sortedKeys(keys) {
+			equal, err := jsonEqual(aItems[key], bItems[key])
+			if err != nil {
+				return err
+			}
+			if !equal {
+				diffs = append(diffs, prefix+key)
+			}
+		}
+		return nil
+	}
+	if err := addDiffs("path ", swaggerPaths(a), swaggerPaths(b)); err != nil {
+		return nil, err
+	}
+	if err := addDiffs("definition ", swaggerDefinitions(a), swaggerDefinitions(b)); err != nil {
+		return nil, err
+	}
+	if len(diffs) == 0 {
+		// the difference is outside of the paths and definitions
+		equal, err := jsonEqual(a, b)
+		if err != nil {
+			return nil, err
+		}
+		if !equal {
+			diffs = append(diffs, "document fields")
+		}
+	}
+	return diffs, nil
+}
+
+func swaggerPaths(swagger *spec.Swagger) map[string]interface{} {
+	items := map[string]interface{}{}
+	if swagger.Paths != nil {
+		for name, item := range swagger.Paths.Paths {
+			items[name] = item
+		}
+	}
+	return items
+}
+
+func swaggerDefinitions(swagger *spec.Swagger) map[string]interface{} {
+	items := make(map[string]interface{}, len(swagger.Definitions))
+	for name, schema := range swagger.Definitions {
+		items[name] = schema
+	}
+	return items
+}
+
+// jsonEqual reports if a and b have the same JSON encoding.
+func jsonEqual(a, b interface{}) (bool, error) {
+	aData, err := json.Marshal(a)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal swagger doc: %w", err)
+	}
+	bData, err := json.Marshal(b)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal swagger doc: %w", err)
+	}
+	return bytes.Equal(aData, bData), nil
+}
@@ -0,0 +1,236 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DiagnosticRejected is the rule of diagnostics for CRDs the API server refused to install.
+	DiagnosticRejected = "crd-rejected"
+	// DiagnosticNonStructural is the rule of diagnostics for schemas the API server refused, such as non-structural
+	// schemas.
+	DiagnosticNonStructural = "non-structural-schema"
+)
+
+// Diagnostic is a problem with a CRD located in the file the CRD was read from.
+type Diagnostic struct {
+	Finding
+	// File is the file the CRD was read from.
+	File string
+	// Line and Column are the position of the problem in File, starting at 1.
+	Line   int
+	Column int
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s", d.File, d.Line, d.Column, d.Finding)
+}
+
+// DiagnosePath diagnoses the CRDs in the YAML and JSON files at path, a file or a directory, see Diagnose.
+func (s *Session) DiagnosePath(ctx context.Context, path string, recurse bool) ([]Diagnostic, error) {
+	files, err := resourceFiles(path, recurse)
+	if err != nil {
+		return nil, err
+	}
+	var diagnostics []Diagnostic
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file '%s': %w", file, err)
+		}
+		fileDiagnostics, err := s.Diagnose(ctx, file, data)
+		if err != nil {
+			return nil, err
+		}
+		diagnostics = append(diagnostics, fileDiagnostics...)
+	}
+	return diagnostics, nil
+}
+
+// Diagnose installs each CRD in the YAML documents of data into the session's cluster and returns a diagnostic for
+// every problem the API server reports installing it and for every field without a description. Each diagnostic is
+// located at the field it is about, file is the file data was read from. The CRDs that are installed stay in the
+// cluster like the CRDs of Generate.
+func (s *Session) Diagnose(ctx context.Context, file string, data []byte) ([]Diagnostic, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	// the documents are parsed as nodes since the CRD decoder does not keep the positions of fields
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	var diagnostics []Diagnostic
+	for {
+		doc := &yaml.Node{}
+		err := decoder.Decode(doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse '%s': %w", file, err)
+		}
+		root := doc
+		if doc.Kind == yaml.DocumentNode && len(doc.Content) != 0 {
+			root = doc.Content[0]
+		}
+		if kind := mappingValue(root, "kind"); kind == nil || kind.Value != crdKind {
+			continue
+		}
+		crdData, err := yaml.Marshal(root)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CRD in '%s': %w", file, err)
+		}
+		crdMap := map[string]*apiextv1.CustomResourceDefinition{}
+		if err := crdFromReader(bytes.NewReader(crdData), crdMap); err != nil {
+			return nil, fmt.Errorf("failed to read CRD in '%s': %w", file, err)
+		}
+		for _, crd := range crdMap {
+			rejected, err := s.install(ctx, crd, file, root)
+			if err != nil {
+				return nil, err
+			}
+			diagnostics = append(diagnostics, rejected...)
+			diagnostics = append(diagnostics, missingDescriptions(crd, file, root)...)
+		}
+	}
+	return diagnostics, nil
+}
+
+// install installs the CRD, the problems the API server reports are returned as diagnostics located in the CRD's
+// document root. Other failures, such as a cluster that can not be reached, are returned as an error.
+func (s *Session) install(ctx context.Context, crd *apiextv1.CustomResourceDefinition, file string, root *yaml.Node) ([]Diagnostic, error) {
+	err := installCRDs(ctx, &s.opts, s.cluster, []*apiextv1.CustomResourceDefinition{crd})
+	var statusErr *apierrors.StatusError
+	if err == nil {
+		return nil, nil
+	} else if !errors.As(err, &statusErr) {
+		return nil, fmt.Errorf("failed to install CRD '%s': %w", crd.Name, err)
+	}
+	var causes []v1.StatusCause
+	if details := statusErr.ErrStatus.Details; details != nil {
+		causes = details.Causes
+	}
+	if len(causes) == 0 {
+		causes = []v1.StatusCause{{Message: statusErr.Error()}}
+	}
+	diagnostics := make([]Diagnostic, 0, len(causes))
+	for _, cause := range causes {
+		rule := DiagnosticRejected
+		if strings.Contains(cause.Field, "openAPIV3Schema") {
+			rule = DiagnosticNonStructural
+		}
+		finding := Finding{Rule: rule, Path: cause.Field, Message: cause.Message}
+		diagnostics = append(diagnostics, newDiagnostic(finding, file, fieldNode(root, cause.Field)))
+	}
+	return diagnostics, nil
+}
+
+// missingDescriptions returns a diagnostic located at every field of the CRD's schemas without a description, see
+// CheckDocs.
+func missingDescriptions(crd *apiextv1.CustomResourceDefinition, file string, root *yaml.Node) []Diagnostic {
+	var diagnostics []Diagnostic
+	var check func(path string, schema *yaml.Node, field bool)
+	check = func(path string, schema *yaml.Node, field bool) {
+		if schema == nil || schema.Kind != yaml.MappingNode {
+			return
+		}
+		if properties := mappingValue(schema, "properties"); properties != nil && properties.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(properties.Content); i += 2 {
+				key, child := properties.Content[i], properties.Content[i+1]
+				// metadata is the shared ObjectMeta which is documented by Kubernetes
+				if !field && key.Value == "metadata" {
+					continue
+				}
+				childPath := path + "/" + key.Value
+				if mappingValue(child, "description") == nil {
+					finding := Finding{Rule: LintMissingDescription, Path: childPath, Message: "field has no doc comment, its description is empty"}
+					diagnostics = append(diagnostics, newDiagnostic(finding, file, key))
+				}
+				check(childPath, child, true)
+			}
+		}
+		// the items and values of lists and maps are described by the field holding them
+		check(path+"[]", mappingValue(schema, "items"), true)
+		check(path+"{}", mappingValue(schema, "additionalProperties"), true)
+	}
+	versions := mappingValue(mappingValue(root, "spec"), "versions")
+	if versions == nil {
+		return nil
+	}
+	for _, version := range versions.Content {
+		name := mappingValue(version, "name")
+		if name == nil {
+			continue
+		}
+		schema := mappingValue(mappingValue(version, "schema"), "openAPIV3Schema")
+		check(crd.Spec.Group+"/"+name.Value+"/"+crd.Spec.Names.Kind, schema, false)
+	}
+	return diagnostics
+}
+
+func newDiagnostic(finding Finding, file string, node *yaml.Node) Diagnostic {
+	return Diagnostic{Finding: finding, File: file, Line: node.Line, Column: node.Column}
+}
+
+// mappingValue returns the value of the key in the mapping node, or nil if node is not a mapping with the key.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// fieldNode returns the node to locate the field path of an API server error at in the CRD's document, e.g.
+// spec.versions[0].schema.openAPIV3Schema.properties[spec].type. Fields of mappings are located at their key.
+// Fields that are not in the document, such as a missing required field, are located at the closest field holding
+// them.
+func fieldNode(root *yaml.Node, field string) *yaml.Node {
+	node, position := root, root
+	for field != "" {
+		var name string
+		if strings.HasPrefix(field, "[") {
+			end := strings.Index(field, "]")
+			if end < 0 {
+				break
+			}
+			name, field = field[1:end], field[end+1:]
+		} else if end := strings.IndexAny(field, ".["); end >= 0 {
+			name, field = field[:end], field[end:]
+		} else {
+			name, field = field, ""
+		}
+		field = strings.TrimPrefix(field, ".")
+		var next, nextPosition *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == name {
+					next, nextPosition = node.Content[i+1], node.Content[i]
+				}
+			}
+		case yaml.SequenceNode:
+			if i, err := strconv.Atoi(name); err == nil && i >= 0 && i < len(node.Content) {
+				next, nextPosition = node.Content[i], node.Content[i]
+			}
+		}
+		if next == nil {
+			break
+		}
+		node, position = next, nextPosition
+	}
+	return position
+}
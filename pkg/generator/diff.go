@@ -0,0 +1,250 @@
+package generator
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const (
+	// ChangePathAdded is a path only in the new doc.
+	ChangePathAdded = "path-added"
+	// ChangePathRemoved is a path only in the old doc.
+	ChangePathRemoved = "path-removed"
+	// ChangeOperationAdded is an operation only in the new doc.
+	ChangeOperationAdded = "operation-added"
+	// ChangeOperationRemoved is an operation only in the old doc.
+	ChangeOperationRemoved = "operation-removed"
+	// ChangeDefinitionAdded is a definition only in the new doc.
+	ChangeDefinitionAdded = "definition-added"
+	// ChangeDefinitionRemoved is a definition only in the old doc.
+	ChangeDefinitionRemoved = "definition-removed"
+	// ChangeFieldAdded is an optional field only in the new doc.
+	ChangeFieldAdded = "field-added"
+	// ChangeFieldRemoved is an optional field only in the old doc.
+	ChangeFieldRemoved = "field-removed"
+	// ChangeRequiredFieldRemoved is a required field only in the old doc.
+	ChangeRequiredFieldRemoved = "required-field-removed"
+	// ChangeFieldRequired is a field that is required in the new doc but not in the old doc.
+	ChangeFieldRequired = "field-required"
+	// ChangeTypeChanged is a schema whose type, format, or $ref differs between the docs.
+	ChangeTypeChanged = "type-changed"
+	// ChangeEnumNarrowed is an enum that allows fewer values in the new doc.
+	ChangeEnumNarrowed = "enum-narrowed"
+	// ChangeEnumWidened is an enum that allows more values in the new doc.
+	ChangeEnumWidened = "enum-widened"
+)
+
+// breakingChanges are the changes that break clients of the old doc.
+var breakingChanges = map[string]bool{
+	ChangePathRemoved:          true,
+	ChangeOperationRemoved:     true,
+	ChangeDefinitionRemoved:    true,
+	ChangeFieldRemoved:         true,
+	ChangeRequiredFieldRemoved: true,
+	ChangeFieldRequired:        true,
+	ChangeTypeChanged:          true,
+	ChangeEnumNarrowed:         true,
+}
+
+// Change is a difference between two swagger docs.
+type Change struct {
+	// Rule is the kind of change, e.g. path-removed.
+	Rule string
+	// Path is the location of the change in the swagger doc, e.g. definitions/io.cattle.Foo/properties/spec.
+	Path string
+	// Message describes the change.
+	Message string
+	// Kind is the Kind.group of the path or definition that changed, empty for shared definitions such as ObjectMeta.
+	Kind string
+}
+
+// Breaking reports whether the change breaks clients of the old doc.
+func (c Change) Breaking() bool {
+	return breakingChanges[c.Rule]
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s (%s)", c.Path, c.Message, c.Rule)
+}
+
+// DiffSwagger returns the changes to the paths and definitions from the old swagger doc to the new swagger doc.
+func DiffSwagger(oldSwagger, newSwagger *spec.Swagger) []Change {
+	var changes []Change
+	// kind is set to the kind of each path and definition before it is compared
+	var kind string
+	add := func(rule, path, format string, args ...interface{}) {
+		changes = append(changes, Change{Rule: rule, Path: path, Message: fmt.Sprintf(format, args...), Kind: kind})
+	}
+
+	oldPaths, newPaths := map[string]spec.PathItem{}, map[string]spec.PathItem{}
+	if oldSwagger.Paths != nil {
+		oldPaths = oldSwagger.Paths.Paths
+	}
+	if newSwagger.Paths != nil {
+		newPaths = newSwagger.Paths.Paths
+	}
+	for _, pathName := range sortedKeys(oldPaths) {
+		kind = pathKind(oldPaths[pathName])
+		newItem, ok := newPaths[pathName]
+		if !ok {
+			add(ChangePathRemoved, "paths"+pathName, "path removed")
+			continue
+		}
+		oldItem := oldPaths[pathName]
+		oldOps, newOps := pathOperations(&oldItem), pathOperations(&newItem)
+		for _, method := range sortedKeys(oldOps) {
+			oldOp, newOp := *oldOps[method], *newOps[method]
+			opPath := "paths" + pathName + "/" + strings.ToLower(method)
+			if oldOp != nil && newOp == nil {
+				add(ChangeOperationRemoved, opPath, "%s operation removed", method)
+			}
+			if oldOp == nil && newOp != nil {
+				add(ChangeOperationAdded, opPath, "%s operation added", method)
+			}
+		}
+	}
+	for _, pathName := range sortedKeys(newPaths) {
+		if _, ok := oldPaths[pathName]; !ok {
+			kind = pathKind(newPaths[pathName])
+			add(ChangePathAdded, "paths"+pathName, "path added")
+		}
+	}
+
+	for _, name := range sortedKeys(oldSwagger.Definitions) {
+		newDef, ok := newSwagger.Definitions[name]
+		if !ok {
+			kind = definitionKind(oldSwagger.Definitions[name])
+			add(ChangeDefinitionRemoved, "definitions/"+name, "definition removed")
+			continue
+		}
+		oldDef := oldSwagger.Definitions[name]
+		if kind = definitionKind(oldDef); kind == "" {
+			kind = definitionKind(newDef)
+		}
+		diffSchema("definitions/"+name, &oldDef, &newDef, add)
+	}
+	for _, name := range sortedKeys(newSwagger.Definitions) {
+		if _, ok := oldSwagger.Definitions[name]; !ok {
+			kind = definitionKind(newSwagger.Definitions[name])
+			add(ChangeDefinitionAdded, "definitions/"+name, "definition added")
+		}
+	}
+	return changes
+}
+
+// diffSchema compares the old and new schema at path along with their nested schemas.
+func diffSchema(path string, oldSchema, newSchema *spec.Schema, add func(rule, path, format string, args ...interface{})) {
+	if oldSchema.Ref.String() != newSchema.Ref.String() {
+		add(ChangeTypeChanged, path, "$ref changed from '%s' to '%s'", oldSchema.Ref.String(), newSchema.Ref.String())
+		return
+	}
+	if !reflect.DeepEqual(oldSchema.Type, newSchema.Type) || oldSchema.Format != newSchema.Format {
+		add(ChangeTypeChanged, path, "type changed from %s to %s", schemaTypeName(oldSchema), schemaTypeName(newSchema))
+		return
+	}
+	diffEnum(path, oldSchema.Enum, newSchema.Enum, add)
+
+	for _, name := range sortedKeys(oldSchema.Properties) {
+		propPath := path + "/properties/" + name
+		newProp, ok := newSchema.Properties[name]
+		if !ok {
+			if containsString(oldSchema.Required, name) {
+				add(ChangeRequiredFieldRemoved, propPath, "required field removed")
+			} else {
+				add(ChangeFieldRemoved, propPath, "field removed")
+			}
+			continue
+		}
+		oldProp := oldSchema.Properties[name]
+		diffSchema(propPath, &oldProp, &newProp, add)
+	}
+	for _, name := range sortedKeys(newSchema.Properties) {
+		if _, ok := oldSchema.Properties[name]; !ok {
+			add(ChangeFieldAdded, path+"/properties/"+name, "field added")
+		}
+	}
+	for _, name := range newSchema.Required {
+		if _, ok := oldSchema.Properties[name]; ok && !containsString(oldSchema.Required, name) {
+			add(ChangeFieldRequired, path+"/properties/"+name, "field is now required")
+		}
+	}
+
+	if oldSchema.Items != nil && oldSchema.Items.Schema != nil && newSchema.Items != nil && newSchema.Items.Schema != nil {
+		diffSchema(path+"/items", oldSchema.Items.Schema, newSchema.Items.Schema, add)
+	}
+	if oldSchema.AdditionalProperties != nil && oldSchema.AdditionalProperties.Schema != nil &&
+		newSchema.AdditionalProperties != nil && newSchema.AdditionalProperties.Schema != nil {
+		diffSchema(path+"/additionalProperties", oldSchema.AdditionalProperties.Schema, newSchema.AdditionalProperties.Schema, add)
+	}
+}
+
+// diffEnum compares the allowed values of two enums, an empty enum allows every value.
+func diffEnum(path string, oldEnum, newEnum []interface{}, add func(rule, path, format string, args ...interface{})) {
+	if len(newEnum) == 0 {
+		if len(oldEnum) != 0 {
+			add(ChangeEnumWidened, path, "enum removed, any value is allowed")
+		}
+		return
+	}
+	if len(oldEnum) == 0 {
+		add(ChangeEnumNarrowed, path, "enum added, only %v are allowed", newEnum)
+		return
+	}
+	var removed, added []interface{}
+	for _, value := range oldEnum {
+		if !enumContains(newEnum, value) {
+			removed = append(removed, value)
+		}
+	}
+	for _, value := range newEnum {
+		if !enumContains(oldEnum, value) {
+			added = append(added, value)
+		}
+	}
+	if len(removed) != 0 {
+		add(ChangeEnumNarrowed, path, "enum values %v removed", removed)
+	}
+	if len(added) != 0 {
+		add(ChangeEnumWidened, path, "enum values %v added", added)
+	}
+}
+
+// pathKind returns the Kind.group the path is for, or an empty string if it is not for a kind.
+func pathKind(pathItem spec.PathItem) string {
+	var kinds []string
+	for _, gk := range groupKindsFromPath(pathItem) {
+		if gk.Kind != "" {
+			kinds = append(kinds, gk.String())
+		}
+	}
+	if len(kinds) == 0 {
+		return ""
+	}
+	sort.Strings(kinds)
+	return kinds[0]
+}
+
+// definitionKind returns the Kind.group of the definition, or an empty string if it is not the definition of a kind.
+func definitionKind(def spec.Schema) string {
+	var gvks []schema.GroupVersionKind
+	if err := def.Extensions.GetObject(extensionGVK, &gvks); err != nil || len(gvks) == 0 {
+		return ""
+	}
+	return gvks[0].GroupKind().String()
+}
+
+func schemaTypeName(schema *spec.Schema) string {
+	name := strings.Join(schema.Type, "|")
+	if name == "" {
+		name = "any"
+	}
+	if schema.Format != "" {
+		name += "(" + schema.Format + ")"
+	}
+	return name
+}
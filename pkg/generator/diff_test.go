@@ -0,0 +1,93 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffSwagger(t *testing.T) {
+	tests := []struct {
+		name      string
+		old       string
+		new       string
+		wantRules []string
+		breaking  bool
+	}{
+		{
+			name:      "field added",
+			old:       `{"Foo": {"properties": {"a": {"type": "string"}}}}`,
+			new:       `{"Foo": {"properties": {"a": {"type": "string"}, "b": {"type": "string"}}}}`,
+			wantRules: []string{ChangeFieldAdded},
+		},
+		{
+			name:      "field removed",
+			old:       `{"Foo": {"properties": {"a": {"type": "string"}, "b": {"type": "string"}}}}`,
+			new:       `{"Foo": {"properties": {"a": {"type": "string"}}}}`,
+			wantRules: []string{ChangeFieldRemoved},
+			breaking:  true,
+		},
+		{
+			name:      "required field removed",
+			old:       `{"Foo": {"required": ["a"], "properties": {"a": {"type": "string"}}}}`,
+			new:       `{"Foo": {}}`,
+			wantRules: []string{ChangeRequiredFieldRemoved},
+			breaking:  true,
+		},
+		{
+			name:      "field required",
+			old:       `{"Foo": {"properties": {"a": {"type": "string"}}}}`,
+			new:       `{"Foo": {"required": ["a"], "properties": {"a": {"type": "string"}}}}`,
+			wantRules: []string{ChangeFieldRequired},
+			breaking:  true,
+		},
+		{
+			name:      "type changed in list items",
+			old:       `{"Foo": {"properties": {"a": {"type": "array", "items": {"type": "string"}}}}}`,
+			new:       `{"Foo": {"properties": {"a": {"type": "array", "items": {"type": "integer"}}}}}`,
+			wantRules: []string{ChangeTypeChanged},
+			breaking:  true,
+		},
+		{
+			name:      "enum widened",
+			old:       `{"Foo": {"type": "string", "enum": ["a"]}}`,
+			new:       `{"Foo": {"type": "string", "enum": ["a", "b"]}}`,
+			wantRules: []string{ChangeEnumWidened},
+		},
+		{
+			name:      "enum narrowed and widened",
+			old:       `{"Foo": {"type": "string", "enum": ["a", "b"]}}`,
+			new:       `{"Foo": {"type": "string", "enum": ["a", "c"]}}`,
+			wantRules: []string{ChangeEnumNarrowed, ChangeEnumWidened},
+			breaking:  true,
+		},
+		{
+			name:      "definitions added and removed",
+			old:       `{"Foo": {}}`,
+			new:       `{"Bar": {}}`,
+			wantRules: []string{ChangeDefinitionRemoved, ChangeDefinitionAdded},
+			breaking:  true,
+		},
+		{
+			name: "unchanged",
+			old:  `{"Foo": {"properties": {"a": {"type": "string"}}}}`,
+			new:  `{"Foo": {"properties": {"a": {"type": "string"}}}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := DiffSwagger(parseDefinitions(t, tt.old), parseDefinitions(t, tt.new))
+			var rules []string
+			breaking := false
+			for _, change := range changes {
+				rules = append(rules, change.Rule)
+				breaking = breaking || change.Breaking()
+			}
+			if !reflect.DeepEqual(rules, tt.wantRules) {
+				t.Errorf("DiffSwagger() = %v, want rules %v", changes, tt.wantRules)
+			}
+			if breaking != tt.breaking {
+				t.Errorf("DiffSwagger() breaking = %v, want %v", breaking, tt.breaking)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// timingBarWidth is the width of the bar drawn for the slowest GroupKind in the discovery timings.
+const timingBarWidth = 40
+
+// groupKindTiming is how long a GroupKind took to appear in the cluster's swagger doc.
+type groupKindTiming struct {
+	GroupKind v1.GroupKind
+	Duration  time.Duration
+}
+
+// waitForGroupKinds polls the cluster's swagger doc until it has a path for every desired GroupKind and returns the doc.
+// The time each GroupKind took to appear is measured from start and logged once all have appeared.
+// When the doc was fetched but some GroupKinds never appeared, the last fetched doc is returned along with a
+// *MissingGroupKindsError so the caller can continue without them.
+func waitForGroupKinds(ctx context.Context, cluster cluster, desiredGroupKinds map[v1.GroupKind]bool, start time.Time, opts *Options) (*spec.Swagger, error) {
+	found := make(map[v1.GroupKind]time.Duration, len(desiredGroupKinds))
+	var swagger *spec.Swagger
+	// fetched is whether the last poll got the doc, so a timeout was spent waiting on the GroupKinds
+	// rather than on the cluster
+	fetched := false
+	poller := newPoller(opts.DiscoveryTimeout)
+	pollFunc := func(context.Context) (bool, error) {
+		var err error
+		swagger, err = cluster.getSwagger(ctx)
+		if err != nil {
+			zap.S().Debugf("Failed to get swagger doc while waiting for CRDs: %v", err)
+			fetched = false
+			return false, poller.retry(err)
+		}
+		fetched = true
+		if swagger.Paths == nil {
+			return false, nil
+		}
+		elapsed := time.Since(start)
+		for _, pathItem := range swagger.Paths.Paths {
+			for _, gk := range groupKindsFromPath(pathItem) {
+				if _, ok := desiredGroupKinds[gk]; !ok {
+					continue
+				}
+				if _, ok := found[gk]; !ok {
+					found[gk] = elapsed
+				}
+			}
+		}
+		return len(found) == len(desiredGroupKinds), nil
+	}
+	err := poller.poll(ctx, pollFunc)
+	if err != nil {
+		var missing []v1.GroupKind
+		for gk := range desiredGroupKinds {
+			if _, ok := found[gk]; !ok {
+				missing = append(missing, gk)
+			}
+		}
+		if fetched && ctx.Err() == nil {
+			return swagger, fmt.Errorf("CRDs were not added to the swagger doc after %v: %w", opts.DiscoveryTimeout, &MissingGroupKindsError{GroupKinds: missingGroupKinds(missing, swagger)})
+		}
+		return nil, fmt.Errorf("CRDs were not added to the swagger doc [%s]: %w", strings.Join(missingGroupKinds(missing, swagger), ", "), err)
+	}
+
+	// kinds that were already served before the CRDs were installed are found immediately,
+	// give the cluster time to update their schemas before using the doc
+	if remaining := syncTime - time.Since(start); remaining > 0 {
+		time.Sleep(remaining)
+		if swagger, err = cluster.getSwagger(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	timings := make([]groupKindTiming, 0, len(found))
+	for gk, duration := range found {
+		timings = append(timings, groupKindTiming{GroupKind: gk, Duration: duration})
+	}
+	logGroupKindTimings(timings, opts.SlowThreshold)
+	return swagger, nil
+}
+
+// logGroupKindTimings logs a bar chart of how long each GroupKind took to appear, slowest first.
+// GroupKinds that took longer than threshold, or more than three times the median when at least
+// three GroupKinds were installed, are logged as warnings.
+func logGroupKindTimings(timings []groupKindTiming, threshold time.Duration) {
+	if len(timings) == 0 {
+		return
+	}
+	sort.Slice(timings, func(i, j int) bool {
+		if timings[i].Duration == timings[j].Duration {
+			return timings[i].GroupKind.String() < timings[j].GroupKind.String()
+		}
+		return timings[i].Duration > timings[j].Duration
+	})
+	median := timings[len(timings)/2].Duration
+	slowest := timings[0].Duration
+	nameWidth := 0
+	for _, timing := range timings {
+		if n := len(timing.GroupKind.String()); n > nameWidth {
+			nameWidth = n
+		}
+	}
+
+	zap.S().Info("GroupKind discovery timings:")
+	for _, timing := range timings {
+		bar := 1
+		if slowest > 0 {
+			bar = int(int64(timingBarWidth) * int64(timing.Duration) / int64(slowest))
+		}
+		line := fmt.Sprintf("  %-*s %8v |%s", nameWidth, timing.GroupKind.String(), timing.Duration.Round(time.Millisecond), strings.Repeat("#", bar))
+		if (threshold > 0 && timing.Duration > threshold) || (len(timings) >= 3 && timing.Duration > 3*median) {
+			zap.S().Warnf("%s  (outlier)", line)
+			continue
+		}
+		zap.S().Info(line)
+	}
+}
@@ -0,0 +1,103 @@
+package generator
+
+import (
+	"context"
+	"sort"
+
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+// LintMissingDescription flags fields without a description, for Go types these are fields without a doc comment.
+const LintMissingDescription = "missing-description"
+
+// DocsSummary counts the documented fields of a single group version, the CRD equivalent of a Go API package.
+type DocsSummary struct {
+	// Package is the group version of the fields, e.g. management.cattle.io/v3.
+	Package string
+	// Fields is the number of fields in the package.
+	Fields int
+	// Missing is the number of fields without a description.
+	Missing int
+}
+
+// Coverage returns the percentage of fields in the package with a description.
+func (s DocsSummary) Coverage() float64 {
+	if s.Fields == 0 {
+		return 100
+	}
+	return float64(s.Fields-s.Missing) / float64(s.Fields) * 100
+}
+
+// CheckDocs finds the fields of the input CRDs that have no description, which are left empty in the swagger doc.
+// When opts.GoPackages is set the CRDs are first generated from the Go types with controller-gen.
+// Returns a summary per group version sorted by name along with a finding for every undocumented field.
+func CheckDocs(opts Options) ([]DocsSummary, []Finding, error) {
+	opts.setDefaults()
+	cleanup, err := useGoPackages(&opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer cleanup()
+	crds, err := loadCRDs(context.Background(), &opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	summaries := map[string]*DocsSummary{}
+	var findings []Finding
+	for _, crd := range crds {
+		for i := range crd.Spec.Versions {
+			version := &crd.Spec.Versions[i]
+			if version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+				continue
+			}
+			pkg := crd.Spec.Group + "/" + version.Name
+			summary := summaries[pkg]
+			if summary == nil {
+				summary = &DocsSummary{Package: pkg}
+				summaries[pkg] = summary
+			}
+			root := version.Schema.OpenAPIV3Schema
+			for _, name := range sortedKeys(root.Properties) {
+				// metadata is the shared ObjectMeta which is documented by Kubernetes
+				if name == "metadata" {
+					continue
+				}
+				child := root.Properties[name]
+				checkFieldDocs(pkg+"/"+crd.Spec.Names.Kind+"/"+name, &child, summary, &findings)
+			}
+		}
+	}
+
+	result := make([]DocsSummary, 0, len(summaries))
+	for _, pkg := range sortedKeys(summaries) {
+		result = append(result, *summaries[pkg])
+	}
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Path < findings[j].Path })
+	return result, findings, nil
+}
+
+// checkFieldDocs counts the field at path and all of its nested fields in summary,
+// adding a finding for every field without a description.
+func checkFieldDocs(path string, props *apiextv1.JSONSchemaProps, summary *DocsSummary, findings *[]Finding) {
+	summary.Fields++
+	if props.Description == "" {
+		summary.Missing++
+		*findings = append(*findings, Finding{Rule: LintMissingDescription, Path: path, Message: "field has no doc comment, its description is empty"})
+	}
+	checkNestedFieldDocs(path, props, summary, findings)
+}
+
+func checkNestedFieldDocs(path string, props *apiextv1.JSONSchemaProps, summary *DocsSummary, findings *[]Finding) {
+	for _, name := range sortedKeys(props.Properties) {
+		child := props.Properties[name]
+		checkFieldDocs(path+"/"+name, &child, summary, findings)
+	}
+	// the items and values of lists and maps are described by the field holding them
+	if props.Items != nil && props.Items.Schema != nil {
+		checkNestedFieldDocs(path+"[]", props.Items.Schema, summary, findings)
+	}
+	if props.AdditionalProperties != nil && props.AdditionalProperties.Schema != nil {
+		checkNestedFieldDocs(path+"{}", props.AdditionalProperties.Schema, summary, findings)
+	}
+}
@@ -0,0 +1,58 @@
+package generator
+
+import (
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const bearerSecurityName = "BearerToken"
+
+// setDocumentFields sets the document level fields that describe the API and how to reach it.
+func setDocumentFields(swagger *spec.Swagger, opts *Options) {
+	setInfo(swagger, opts.Info)
+	if opts.Host != "" {
+		swagger.Host = opts.Host
+	}
+	if opts.BasePath != "" {
+		swagger.BasePath = opts.BasePath
+	}
+	if len(opts.Schemes) != 0 {
+		swagger.Schemes = opts.Schemes
+	}
+	if opts.BearerAuth {
+		if swagger.SecurityDefinitions == nil {
+			swagger.SecurityDefinitions = spec.SecurityDefinitions{}
+		}
+		swagger.SecurityDefinitions[bearerSecurityName] = &spec.SecurityScheme{SecuritySchemeProps: spec.SecuritySchemeProps{
+			Type:        "apiKey",
+			Name:        "authorization",
+			In:          "header",
+			Description: "Bearer token authentication, e.g. 'Bearer <token>'",
+		}}
+		swagger.Security = []map[string][]string{{bearerSecurityName: {}}}
+	}
+}
+
+// setInfo overrides the fields of the swagger doc's info block that are set in info.
+func setInfo(swagger *spec.Swagger, info spec.InfoProps) {
+	if swagger.Info == nil {
+		swagger.Info = &spec.Info{}
+	}
+	if info.Title != "" {
+		swagger.Info.Title = info.Title
+	}
+	if info.Version != "" {
+		swagger.Info.Version = info.Version
+	}
+	if info.Description != "" {
+		swagger.Info.Description = info.Description
+	}
+	if info.TermsOfService != "" {
+		swagger.Info.TermsOfService = info.TermsOfService
+	}
+	if info.Contact != nil {
+		swagger.Info.Contact = info.Contact
+	}
+	if info.License != nil {
+		swagger.Info.License = info.License
+	}
+}
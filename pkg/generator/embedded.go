@@ -0,0 +1,174 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const (
+	// upstreamDefinitionPrefix starts the names of the Kubernetes definitions embedded types are compared with.
+	upstreamDefinitionPrefix = "io.k8s."
+	// minEmbeddedFields is the fewest fields a schema needs to be compared with the upstream definitions,
+	// smaller schemas share their few field names with too many types.
+	minEmbeddedFields = 4
+	// DefaultMinSimilarity is the default fraction of fields a schema shares with an upstream definition
+	// to be treated as a copy of it.
+	DefaultMinSimilarity = 0.7
+)
+
+// EmbeddedType is a schema in a definition that is a copy of an upstream Kubernetes type, such as a PodSpec
+// vendored into a CRD, whose schema differs from the upstream type.
+type EmbeddedType struct {
+	// Path is the location of the copy in the swagger doc, e.g. definitions/io.cattle.Foo/properties/spec/properties/template.
+	Path string
+	// Upstream is the name of the upstream definition, e.g. io.k8s.api.core.v1.PodTemplateSpec.
+	Upstream string
+	// Similarity is the fraction of the fields of the copy and the upstream type that both have.
+	Similarity float64
+	// Differences describes how the copy differs from the upstream type, their paths are relative to Path.
+	Differences []Change
+}
+
+func (e EmbeddedType) String() string {
+	lines := []string{fmt.Sprintf("%s shadows %s (%.0f%% of fields shared):", e.Path, e.Upstream, e.Similarity*100)}
+	for _, diff := range e.Differences {
+		lines = append(lines, fmt.Sprintf("  %s: %s", diff.Path, diff.Message))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// CheckEmbedded finds the schemas in the definitions of swagger that share at least minSimilarity of their fields
+// with a Kubernetes definition of upstream, and returns the ones that differ from it. Only the outermost copy is
+// compared, e.g. a PodTemplateSpec copy is reported with the differences of the PodSpec it contains.
+// Definitions of swagger that are Kubernetes types themselves and references to definitions are not copies.
+func CheckEmbedded(swagger, upstream *spec.Swagger, minSimilarity float64) []EmbeddedType {
+	upstreamFields := map[string][]string{}
+	for name, def := range upstream.Definitions {
+		if strings.HasPrefix(name, upstreamDefinitionPrefix) && len(def.Properties) >= minEmbeddedFields {
+			upstreamFields[name] = sortedKeys(def.Properties)
+		}
+	}
+	checker := embeddedChecker{
+		upstreamFields: upstreamFields,
+		inliner:        newRefInliner(upstream, false),
+		minSimilarity:  minSimilarity,
+	}
+	for _, name := range sortedKeys(swagger.Definitions) {
+		if strings.HasPrefix(name, upstreamDefinitionPrefix) {
+			continue
+		}
+		def := swagger.Definitions[name]
+		// the definition itself is the kind, not an embedded copy
+		checker.checkNested("definitions/"+name, &def)
+	}
+	return checker.embedded
+}
+
+type embeddedChecker struct {
+	upstreamFields map[string][]string
+	inliner        *refInliner
+	minSimilarity  float64
+	embedded       []EmbeddedType
+}
+
+// check compares the schema with the upstream definition it is most similar to, or checks its nested schemas
+// if it is not a copy of one.
+func (c *embeddedChecker) check(path string, schema *spec.Schema) {
+	if schema.Ref.String() != "" {
+		return
+	}
+	upstreamName, similarity := c.mostSimilar(schema)
+	if similarity < c.minSimilarity {
+		c.checkNested(path, schema)
+		return
+	}
+	ref := spec.MustCreateRef(definitionRefPrefix + upstreamName)
+	upstreamSchema := c.inliner.schema(spec.Schema{SchemaProps: spec.SchemaProps{Ref: ref}})
+	alignIntOrString(&upstreamSchema, schema)
+	var diffs []Change
+	diffSchema("", &upstreamSchema, schema, func(rule, diffPath, format string, args ...interface{}) {
+		diffs = append(diffs, Change{Rule: rule, Path: diffPath, Message: embeddedMessage(rule, fmt.Sprintf(format, args...))})
+	})
+	if len(diffs) != 0 {
+		c.embedded = append(c.embedded, EmbeddedType{Path: path, Upstream: upstreamName, Similarity: similarity, Differences: diffs})
+	}
+}
+
+// checkNested checks the properties, items, and additional properties of the schema.
+func (c *embeddedChecker) checkNested(path string, schema *spec.Schema) {
+	for _, name := range sortedKeys(schema.Properties) {
+		prop := schema.Properties[name]
+		c.check(path+"/properties/"+name, &prop)
+	}
+	if schema.Items != nil && schema.Items.Schema != nil {
+		c.check(path+"/items", schema.Items.Schema)
+	}
+	if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+		c.check(path+"/additionalProperties", schema.AdditionalProperties.Schema)
+	}
+}
+
+// mostSimilar returns the upstream definition whose fields are most similar to the schema's, measured as the
+// fields both have out of the fields either has. Ties go to the first name in lexical order.
+func (c *embeddedChecker) mostSimilar(schema *spec.Schema) (string, float64) {
+	if len(schema.Properties) < minEmbeddedFields {
+		return "", 0
+	}
+	best, bestSimilarity := "", 0.0
+	for _, name := range sortedKeys(c.upstreamFields) {
+		fields := c.upstreamFields[name]
+		shared := 0
+		for _, field := range fields {
+			if _, ok := schema.Properties[field]; ok {
+				shared++
+			}
+		}
+		similarity := float64(shared) / float64(len(fields)+len(schema.Properties)-shared)
+		if similarity > bestSimilarity {
+			best, bestSimilarity = name, similarity
+		}
+	}
+	return best, bestSimilarity
+}
+
+// alignIntOrString sets the type of the upstream schemas to the copy's where the copy declares an int-or-string
+// value, CRD schemas use x-kubernetes-int-or-string where upstream types use the int-or-string format or a string.
+func alignIntOrString(upstream, copied *spec.Schema) {
+	if isIntOrString(copied) && (isIntOrString(upstream) || upstream.Type.Contains("string")) {
+		upstream.Type, upstream.Format = copied.Type, copied.Format
+		return
+	}
+	for name, prop := range copied.Properties {
+		if upstreamProp, ok := upstream.Properties[name]; ok {
+			alignIntOrString(&upstreamProp, &prop)
+			upstream.Properties[name] = upstreamProp
+		}
+	}
+	if upstream.Items != nil && upstream.Items.Schema != nil && copied.Items != nil && copied.Items.Schema != nil {
+		alignIntOrString(upstream.Items.Schema, copied.Items.Schema)
+	}
+	if upstream.AdditionalProperties != nil && upstream.AdditionalProperties.Schema != nil &&
+		copied.AdditionalProperties != nil && copied.AdditionalProperties.Schema != nil {
+		alignIntOrString(upstream.AdditionalProperties.Schema, copied.AdditionalProperties.Schema)
+	}
+}
+
+func isIntOrString(schema *spec.Schema) bool {
+	intOrString, _ := schema.Extensions.GetBool("x-kubernetes-int-or-string")
+	return intOrString || schema.Format == "int-or-string"
+}
+
+// embeddedMessage rewords the diff messages for a copy compared with its upstream type.
+func embeddedMessage(rule, message string) string {
+	switch rule {
+	case ChangeFieldRemoved, ChangeRequiredFieldRemoved:
+		return "upstream field is missing from the copy"
+	case ChangeFieldAdded:
+		return "field is not in the upstream type"
+	case ChangeFieldRequired:
+		return "field is required but optional in the upstream type"
+	}
+	return message
+}
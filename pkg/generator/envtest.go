@@ -0,0 +1,58 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"go.uber.org/zap"
+	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// envtestCluster runs kube-apiserver and etcd as local processes using controller-runtime's envtest.
+// The binaries are located using the KUBEBUILDER_ASSETS environment variable.
+type envtestCluster struct {
+	apiClient
+	timer *phaseTimer
+	env   *envtest.Environment
+	// featureGates are passed to kube-apiserver
+	featureGates []string
+}
+
+func (e *envtestCluster) start(ctx context.Context) error {
+	// envtest logs through controller-runtime's logger which is not used by this application
+	ctrllog.SetLogger(logr.Discard())
+	e.timer.start(phaseClusterReady)
+	e.env = &envtest.Environment{}
+	if len(e.featureGates) != 0 {
+		e.env.ControlPlane.GetAPIServer().Configure().Append("feature-gates", strings.Join(e.featureGates, ","))
+	}
+	restCfg, err := e.env.Start()
+	if err != nil {
+		return fmt.Errorf("failed to start envtest control plane (is KUBEBUILDER_ASSETS set?): %w", err)
+	}
+	e.cfg = restCfg
+	e.cs, err = clientset.NewForConfig(restCfg)
+	if err != nil {
+		return fmt.Errorf("failed to create new clientset: %w", err)
+	}
+	if err := e.waitForCluster(ctx); err != nil {
+		return err
+	}
+	e.timer.done(phaseClusterReady)
+	return nil
+}
+
+func (e *envtestCluster) stop(_ context.Context) error {
+	if e.env == nil {
+		return nil
+	}
+	zap.S().Info("Stopping envtest control plane.")
+	if err := e.env.Stop(); err != nil {
+		return fmt.Errorf("failed to stop envtest control plane: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package generator
+
+import (
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const extensionAction = "x-kubernetes-action"
+
+// pathOperations returns pointers to every operation field of the path item keyed by HTTP method.
+func pathOperations(item *spec.PathItem) map[string]**spec.Operation {
+	return map[string]**spec.Operation{
+		"GET": &item.Get, "PUT": &item.Put, "POST": &item.Post, "DELETE": &item.Delete,
+		"OPTIONS": &item.Options, "HEAD": &item.Head, "PATCH": &item.Patch,
+	}
+}
+
+// operationVerb returns the Kubernetes verb of an operation.
+// The deprecated watchlist action is reported as the watch verb.
+func operationVerb(op *spec.Operation) string {
+	verb, _ := op.Extensions.GetString(extensionAction)
+	if verb == "watchlist" {
+		return "watch"
+	}
+	return verb
+}
+
+// filterVerbs removes operations from the listed paths that are not in verbs (when verbs is not empty)
+// or are in excludeVerbs. The paths that still have at least one operation are returned.
+func filterVerbs(swagger *spec.Swagger, paths []string, verbs, excludeVerbs []string) []string {
+	if len(verbs) == 0 && len(excludeVerbs) == 0 {
+		return paths
+	}
+	keepPaths := make([]string, 0, len(paths))
+	for _, pathName := range paths {
+		item := swagger.Paths.Paths[pathName]
+		remaining := 0
+		for _, op := range pathOperations(&item) {
+			if *op == nil {
+				continue
+			}
+			verb := operationVerb(*op)
+			if (len(verbs) != 0 && !containsString(verbs, verb)) || containsString(excludeVerbs, verb) {
+				*op = nil
+				continue
+			}
+			remaining++
+		}
+		swagger.Paths.Paths[pathName] = item
+		if remaining > 0 {
+			keepPaths = append(keepPaths, pathName)
+		}
+	}
+	return keepPaths
+}
+
+// pathSubresource returns the subresource a path is for, e.g. status for .../foos/{name}/status,
+// or an empty string if the path is not for a subresource.
+func pathSubresource(pathName string) string {
+	_, subresource, found := strings.Cut(pathName, "/{name}/")
+	if !found {
+		return ""
+	}
+	return subresource
+}
+
+// filterSubresources removes paths for subresources in excludeSubresources.
+// If subresourcesOnly is set all paths that are not for a subresource are removed as well.
+func filterSubresources(paths []string, excludeSubresources []string, subresourcesOnly bool) []string {
+	if len(excludeSubresources) == 0 && !subresourcesOnly {
+		return paths
+	}
+	keepPaths := make([]string, 0, len(paths))
+	for _, pathName := range paths {
+		subresource := pathSubresource(pathName)
+		if subresource == "" && subresourcesOnly {
+			continue
+		}
+		if subresource != "" && containsString(excludeSubresources, subresource) {
+			continue
+		}
+		keepPaths = append(keepPaths, pathName)
+	}
+	return keepPaths
+}
@@ -0,0 +1,107 @@
+package generator
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+func verbOperation(verb string) *spec.Operation {
+	return &spec.Operation{VendorExtensible: spec.VendorExtensible{Extensions: spec.Extensions{extensionAction: verb}}}
+}
+
+func TestPathSubresource(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/apis/a.io/v1/foos", want: ""},
+		{path: "/apis/a.io/v1/namespaces/{namespace}/foos/{name}", want: ""},
+		{path: "/apis/a.io/v1/namespaces/{namespace}/foos/{name}/status", want: "status"},
+		{path: "/apis/a.io/v1/foos/{name}/scale", want: "scale"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := pathSubresource(tt.path); got != tt.want {
+				t.Errorf("pathSubresource(%s) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterSubresources(t *testing.T) {
+	paths := []string{"/apis/a.io/v1/foos", "/apis/a.io/v1/foos/{name}", "/apis/a.io/v1/foos/{name}/status", "/apis/a.io/v1/foos/{name}/scale"}
+	tests := []struct {
+		name             string
+		exclude          []string
+		subresourcesOnly bool
+		want             []string
+	}{
+		{name: "no filter", want: paths},
+		{name: "exclude status", exclude: []string{"status"}, want: []string{"/apis/a.io/v1/foos", "/apis/a.io/v1/foos/{name}", "/apis/a.io/v1/foos/{name}/scale"}},
+		{name: "subresources only", subresourcesOnly: true, want: []string{"/apis/a.io/v1/foos/{name}/status", "/apis/a.io/v1/foos/{name}/scale"}},
+		{name: "subresources only without scale", exclude: []string{"scale"}, subresourcesOnly: true, want: []string{"/apis/a.io/v1/foos/{name}/status"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filterSubresources(paths, tt.exclude, tt.subresourcesOnly); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filterSubresources() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterVerbs(t *testing.T) {
+	tests := []struct {
+		name         string
+		verbs        []string
+		excludeVerbs []string
+		wantPaths    []string
+		wantVerbs    map[string][]string
+	}{
+		{
+			name:      "no filter",
+			wantPaths: []string{"/foos", "/foos/{name}"},
+			wantVerbs: map[string][]string{"/foos": {"list", "watch"}, "/foos/{name}": {"delete", "get"}},
+		},
+		{
+			name:      "only get and list",
+			verbs:     []string{"get", "list"},
+			wantPaths: []string{"/foos", "/foos/{name}"},
+			wantVerbs: map[string][]string{"/foos": {"list"}, "/foos/{name}": {"get"}},
+		},
+		{
+			name:         "watchlist is excluded as watch",
+			excludeVerbs: []string{"watch", "list"},
+			wantPaths:    []string{"/foos/{name}"},
+			wantVerbs:    map[string][]string{"/foos": nil, "/foos/{name}": {"delete", "get"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			swagger := &spec.Swagger{SwaggerProps: spec.SwaggerProps{Paths: &spec.Paths{Paths: map[string]spec.PathItem{
+				"/foos":        {PathItemProps: spec.PathItemProps{Get: verbOperation("list"), Post: verbOperation("watchlist")}},
+				"/foos/{name}": {PathItemProps: spec.PathItemProps{Get: verbOperation("get"), Delete: verbOperation("delete")}},
+			}}}}
+			gotPaths := filterVerbs(swagger, []string{"/foos", "/foos/{name}"}, tt.verbs, tt.excludeVerbs)
+			if !reflect.DeepEqual(gotPaths, tt.wantPaths) {
+				t.Errorf("filterVerbs() = %v, want %v", gotPaths, tt.wantPaths)
+			}
+			for pathName, want := range tt.wantVerbs {
+				item := swagger.Paths.Paths[pathName]
+				var got []string
+				for _, op := range pathOperations(&item) {
+					if *op != nil {
+						got = append(got, operationVerb(*op))
+					}
+				}
+				sort.Strings(got)
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("verbs of %s = %v, want %v", pathName, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,732 @@
+// Package generator creates swagger (openapiv2) documents for CRDs by installing them into a cluster
+// and filtering the cluster's swagger document down to the paths and definitions used by the CRDs.
+package generator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/kube-openapi/pkg/aggregator"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const (
+	kubePath       = "/etc/rancher/k3s/k3s.yaml"
+	crdKind        = "CustomResourceDefinition"
+	requestTimeout = time.Second * 5
+	waitInterval   = time.Millisecond * 500
+	waitTime       = time.Second * 15
+	pullTime       = time.Minute * 10
+	syncTime       = time.Second * 2
+	extensionGVK   = "x-kubernetes-group-version-kind"
+	// containerLogTail is how many lines of the cluster container's logs are logged when the cluster fails
+	containerLogTail = 100
+)
+
+var errDuplicate = fmt.Errorf("duplicate CRD")
+
+// Options configures how a swagger document is generated.
+type Options struct {
+	// CRDSource is the location of the input CRDs, either a file path, a directory, or a remote file URL.
+	CRDSource string
+	// Recurse searches subdirectories for CRDs when CRDSource is a local directory.
+	Recurse bool
+	// GoPackages generates the input CRDs from the kubebuilder annotated Go types in these packages, e.g. ./pkg/apis/...,
+	// using controller-gen instead of reading them from CRDSource.
+	GoPackages string
+	// ControllerGen is the controller-gen binary run for GoPackages. Defaults to controller-gen from the PATH.
+	ControllerGen string
+	// InstallOrder is a file of Kind.group entries, each line is a stage of the CRDs it matches that is installed
+	// and ready before the next line's CRDs, CRDs matching no line are installed last. By default the CRDs using
+	// a conversion webhook are installed after the rest.
+	InstallOrder string
+	// ResourcesFile lists the Kind.group of the input CRDs and built-in resources to document, one per line, see LoadResources.
+	// The kind and group are glob patterns, e.g. *.management.cattle.io or Cluster.*. All CRDs are documented if empty.
+	// CRDSource may be left empty when only built-in resources are listed. The file may also be a URL, or a YAML or JSON
+	// manifest with options per resource.
+	ResourcesFile string
+
+	// AliasesFile renames the groups and kinds of resources file entries that match nothing, so resources files
+	// written before a group or kind was renamed keep working. See loadAliases for the format.
+	AliasesFile string
+
+	// Server is the address of an existing API server to install the CRDs into instead of starting a cluster.
+	// The CRDs are left installed in the API server.
+	Server string
+	// ClusterProvider returns the rest config of an existing cluster to install the CRDs into instead of starting a cluster,
+	// for tools embedding the generator that already have a connection. It takes precedence over Server.
+	ClusterProvider func(ctx context.Context) (*rest.Config, error)
+	// OpenAPIURL is the URL of an openapiv2 document served by an API server the CRDs are already installed in.
+	// When set no cluster is started and the document is filtered using the GroupKinds of the CRDs.
+	OpenAPIURL string
+	// Token is the bearer token used to authenticate to Server or OpenAPIURL.
+	Token string
+	// Username and Password are used for basic authentication to Server or OpenAPIURL.
+	Username string
+	Password string
+	// CAFile is the path to the certificate authority used to verify Server or OpenAPIURL.
+	CAFile string
+	// InsecureSkipTLSVerify does not verify the certificate of Server or OpenAPIURL.
+	InsecureSkipTLSVerify bool
+
+	// Engine is the backend used to run kube-apiserver, either EngineDocker or EngineEnvtest.
+	// Defaults to EngineDocker.
+	Engine string
+	// Image is the k3s image run by EngineDocker. Defaults to DefaultImage.
+	Image string
+	// KubernetesVersion selects the k3s image run by EngineDocker for a Kubernetes version, e.g. v1.26 or v1.26.9.
+	// It can not be used with Image.
+	KubernetesVersion string
+	// RegistryAuth is the username:password used to pull Image. If empty the credentials
+	// stored by docker login in the docker config file are used when present.
+	RegistryAuth string
+	// ImageTar is a tarball created by docker save that the image is loaded from instead of being pulled.
+	ImageTar string
+	// ClusterPort is the host port kube-apiserver is bound to when using EngineDocker.
+	// If empty a free port is selected so parallel runs on the same host do not collide.
+	ClusterPort string
+	// ContainerName is the name of the cluster container. Defaults to DefaultContainerName.
+	ContainerName string
+	// defaultedName is whether ContainerName was set by setDefaults, so each retry can pick a name of its own
+	defaultedName bool
+	// KeepContainer leaves the cluster container running after generation. Unless the container is also reused or
+	// ContainerName is set, every kept container gets a name of its own so the next run does not conflict with it.
+	KeepContainer bool
+	// ReuseContainer reuses the cluster container from a previous run if one exists and leaves it running afterwards.
+	ReuseContainer bool
+	// DebugLogsDir is the directory the cluster container's logs are saved to when the cluster fails, the last lines
+	// of the logs are logged instead when empty.
+	DebugLogsDir string
+	// RestartPolicy is the docker restart policy of the cluster container, one of no, on-failure, unless-stopped, or always.
+	// Defaults to no so containers left behind by an unclean exit are not restarted.
+	RestartPolicy string
+	// Privileged runs the cluster container in privileged mode for hosts where k3s can not start without it.
+	// Unprivileged containers get the capabilities, cgroup namespace, and cgroup mount k3s needs instead.
+	Privileged bool
+	// ReadOnlyRootFS runs the cluster container with a read-only root filesystem, the paths k3s writes to are volumes
+	// removed with the container.
+	ReadOnlyRootFS bool
+	// SeccompProfile is the path to a seccomp profile JSON file, or unconfined, applied to the cluster container.
+	SeccompProfile string
+	// AppArmorProfile is the name of the AppArmor profile applied to the cluster container.
+	AppArmorProfile string
+	// NoNewPrivileges stops processes in the cluster container from gaining new privileges.
+	NoNewPrivileges bool
+	// PersistCredentials writes the cluster's kubeconfig to a private temporary file that is left in place after generation.
+	// By default the kubeconfig is only kept in memory.
+	PersistCredentials bool
+	// ContainerEnv is a list of KEY=VALUE environment variables set in the cluster container of EngineDocker.
+	ContainerEnv []string
+	// K3sRegistries is a k3s registries.yaml mounted into the cluster container of EngineDocker to configure
+	// the mirrors and credentials k3s pulls its system images from.
+	K3sRegistries string
+	// K3sCABundle is a PEM CA bundle mounted into the cluster container of EngineDocker at K3sCABundlePath
+	// so registries.yaml can trust mirrors signed by a private CA.
+	K3sCABundle string
+	// FeatureGates is a list of Name=true|false Kubernetes feature gates enabled in kube-apiserver.
+	// Some APIs are only served when a feature gate is enabled.
+	FeatureGates []string
+	// ApplyManifests are YAML files, directories, or remote file URLs of objects applied to the cluster after the CRDs
+	// are installed, such as APIServices or operators that register APIs at runtime.
+	ApplyManifests []string
+	// Charts are Helm charts in the form REPO_URL/NAME[@VERSION] installed with k3s' helm-controller after the CRDs
+	// are installed. The HelmCharts are applied and waited on the same as ApplyManifests.
+	Charts []string
+	// ChartVersion is the version of the chart when a single chart is installed.
+	ChartVersion string
+	// ManifestTimeout is how long to wait for ApplyManifests to be applied and ready. Defaults to 2 minutes.
+	ManifestTimeout time.Duration
+	// PullOutput receives the progress of the image pull, if nil the progress is discarded.
+	PullOutput io.Writer
+
+	// SlowThreshold logs a warning for any phase of generation that takes longer than the threshold.
+	// A threshold of zero disables the warnings.
+	SlowThreshold time.Duration
+
+	// Verbs limits the operations of the kept paths to the listed Kubernetes verbs (get, list, watch, create, ...).
+	// All verbs are kept if empty.
+	Verbs []string
+	// ExcludeVerbs removes operations for the listed Kubernetes verbs from the kept paths.
+	ExcludeVerbs []string
+
+	// DefinitionsOnly removes every path and keeps just the definitions of the desired GroupKinds
+	// along with the definitions they reference, for client generators that only need the models.
+	DefinitionsOnly bool
+
+	// ExcludeSubresources removes the paths for the listed subresources (status, scale, ...).
+	ExcludeSubresources []string
+	// SubresourcesOnly removes every path that is not for a subresource.
+	SubresourcesOnly bool
+
+	// Host, BasePath, and Schemes set the document fields describing where the API is served.
+	Host     string
+	BasePath string
+	Schemes  []string
+	// BearerAuth adds a bearer token security definition that applies to every operation.
+	BearerAuth bool
+	// Info overrides the non-empty fields of the document's info block, which otherwise
+	// holds the title and version of the cluster's API server.
+	Info spec.InfoProps
+	// PathPrefix is prepended to every kept path, e.g. /k8s/clusters/{clusterId} for clusters proxied by Rancher.
+	// A required path parameter is added for each {param} in the prefix.
+	PathPrefix string
+
+	// StripDescriptions removes every description from the doc to shrink it.
+	StripDescriptions bool
+
+	// StripExtensions removes the vendor extensions whose names match any of the patterns, e.g. x-kubernetes-*.
+	StripExtensions []string
+
+	// Anonymize removes details that identify the cluster the doc was generated from
+	// such as server URLs, UIDs, and document level vendor extensions.
+	Anonymize bool
+	// PullTimeout is how long to wait for the cluster image to be pulled or loaded. Defaults to 10 minutes.
+	PullTimeout time.Duration
+	// ClusterReadyTimeout is how long to wait for the cluster to be ready. Defaults to 15 seconds.
+	ClusterReadyTimeout time.Duration
+	// DiscoveryTimeout is how long to wait for installed CRDs to be established and added to the swagger doc. Defaults to 15 seconds.
+	DiscoveryTimeout time.Duration
+	// Retries is how many times generation is retried from the start when it fails with a known flaky failure, such as
+	// an image pull cut off by the registry.
+	Retries int
+
+	// MaxParallelClusters is how many clusters GenerateMatrix runs at once so many versions do not overwhelm the
+	// Docker daemon. Defaults to one cluster per 2 CPUs and 1GiB of memory of the host.
+	MaxParallelClusters int
+	// MaxParallelFilters is how many of GenerateMatrix's docs are filtered at once. Defaults to the host's CPUs.
+	MaxParallelFilters int
+	// filterLimiter is shared by the generations of a matrix to apply MaxParallelFilters.
+	filterLimiter limiter
+
+	// FlattenAllOf merges allOf members into a single object schema where it is safe to do so.
+	FlattenAllOf bool
+
+	// AnnotateMinVersions sets the x-min-kubernetes-version extension on each CRD definition to the oldest
+	// Kubernetes version that serves every CRD feature the schema uses, such as CEL validation rules.
+	AnnotateMinVersions bool
+
+	// IgnoreMissing generates the doc for the GroupKinds that were found instead of failing when some never appear
+	// in the cluster's swagger doc or resources file entries match nothing. The missing GroupKinds are reported with a *MissingGroupKindsError returned
+	// alongside the doc. Docs of clusters that are missing GroupKinds are not written to the cache.
+	IgnoreMissing bool
+
+	// RBACAnnotations sets the x-required-rbac extension on each Kubernetes operation to the apiGroup, resource,
+	// and verb of the RBAC rule needed to call it.
+	RBACAnnotations bool
+
+	// StevePaths adds paths for Rancher's Steve API (/v1/{type}) for each CRD alongside the Kubernetes paths.
+	StevePaths bool
+
+	// PostProcessors are executables the filtered doc is passed through in order, see PluginAPIVersion for the protocol.
+	PostProcessors []string
+
+	// LogFields adds the phase in progress, and the image and container ID of docker clusters, as fields to the
+	// entries of the global zap logger while generating, for structured logs. The global logger is replaced while
+	// generating, so the fields are not added by GenerateMatrix which generates the versions concurrently.
+	LogFields bool
+
+	// CacheDir is where the cluster's full swagger doc is cached, keyed by the image digest and the installed CRDs,
+	// so later runs that only change filtering skip starting a cluster. Remote CRD and resources file URLs are
+	// cached in its inputs directory. Caching is disabled if empty.
+	CacheDir string
+
+	// OfflineInputs reads remote CRD and resources file URLs only from their copies cached in CacheDir, failing
+	// if one was never cached. Remote inputs are otherwise revalidated on every run, see fetchInput.
+	OfflineInputs bool
+
+	// VerifyDeterministic generates the doc twice using the same cluster and fails if the two docs differ.
+	VerifyDeterministic bool
+
+	// ResolveRefs inlines every $ref so the doc is self-contained, except for references to recursive definitions.
+	ResolveRefs bool
+
+	// Progress is called from the goroutine running Generate when each phase of generation starts and is done,
+	// so tools embedding the generator can show their own progress. Errors of a phase are returned as a *StageError.
+	Progress func(Progress)
+
+	// Summary, if set, is filled in with a description of the run, including runs that fail.
+	Summary *Summary
+
+	// Canonical sorts every list in the doc whose order has no meaning, such as tags and parameters, so the doc
+	// only changes when its content does.
+	Canonical bool
+}
+
+func (o *Options) setDefaults() {
+	if o.ControllerGen == "" {
+		o.ControllerGen = defaultControllerGen
+	}
+	if o.Engine == "" {
+		o.Engine = EngineDocker
+	}
+	if o.Image == "" && o.KubernetesVersion == "" {
+		o.Image = DefaultImage
+	}
+	if o.PullTimeout == 0 {
+		o.PullTimeout = pullTime
+	}
+	if o.ClusterReadyTimeout == 0 {
+		o.ClusterReadyTimeout = waitTime
+	}
+	if o.ManifestTimeout == 0 {
+		o.ManifestTimeout = manifestTime
+	}
+	if o.DiscoveryTimeout == 0 {
+		o.DiscoveryTimeout = waitTime
+	}
+	if o.ContainerName == "" {
+		o.ContainerName = defaultContainerName(o)
+		o.defaultedName = true
+	}
+	if o.RestartPolicy == "" {
+		o.RestartPolicy = "no"
+	}
+}
+
+// Generate installs the CRDs found at opts.CRDSource into a new cluster and returns the cluster's
+// swagger document filtered to only the paths and definitions used by those CRDs.
+func Generate(ctx context.Context, opts Options) (swagger *spec.Swagger, err error) {
+	opts.setDefaults()
+	if opts.Summary != nil {
+		start := time.Now()
+		defer func() { opts.Summary.finish(&opts, swagger, start) }()
+	}
+	if opts.OpenAPIURL == "" && opts.Server == "" && opts.ClusterProvider == nil {
+		// resolved before generate copies the options so the summary records the image
+		if err := resolveImage(&opts); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Retries == 0 {
+		return generate(ctx, opts)
+	}
+	return generateWithRetries(ctx, opts)
+}
+
+// generate runs a single attempt of Generate.
+func generate(ctx context.Context, opts Options) (swagger *spec.Swagger, err error) {
+	cleanup, err := useGoPackages(&opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	crds, err := loadCRDs(ctx, &opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Summary != nil {
+		opts.Summary.recordRequested(groupKindsOf(crds))
+	}
+
+	timer := newPhaseTimer(&opts)
+	defer timer.restoreLogger()
+	defer timer.summary()
+	defer func() {
+		if swagger != nil {
+			timer.finish()
+		}
+		err = timer.stageError(err)
+	}()
+	key, err := cacheKey(ctx, &opts, crds)
+	if err != nil {
+		return nil, err
+	}
+	if key != "" {
+		cached, err := readCache(opts.CacheDir, key)
+		if err != nil {
+			zap.S().Warnf("Ignoring cached swagger doc: %v", err)
+		}
+		if cached != nil {
+			zap.S().Info("Using the cached swagger doc of the cluster instead of starting a cluster.")
+			return filterSwagger(ctx, &opts, cached, timer, crds)
+		}
+	}
+
+	cluster, err := startCluster(ctx, &opts, timer)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		dumpLogsOnFailure(ctx, cluster, timer, err)
+		stopErr := cluster.stop(ctx)
+		if err == nil {
+			err = stopErr
+		}
+	}()
+
+	if opts.VerifyDeterministic {
+		return generateDeterministic(ctx, &opts, cluster, timer, crds)
+	}
+	swagger, err = fetchSwagger(ctx, &opts, cluster, timer, crds)
+	if err != nil {
+		return nil, err
+	}
+	if key == "" {
+		// the image may only be available now that the cluster was started
+		key, _ = cacheKey(ctx, &opts, crds)
+	}
+	if key != "" && !opts.IgnoreMissing {
+		if err := writeCache(opts.CacheDir, key, swagger); err != nil {
+			zap.S().Warnf("Failed to cache swagger doc: %v", err)
+		}
+	}
+	return filterSwagger(ctx, &opts, swagger, timer, crds)
+}
+
+// useGoPackages generates the CRDs for opts.GoPackages, if set, and uses them as the CRD source.
+// The returned cleanup removes the generated CRDs.
+func useGoPackages(opts *Options) (func(), error) {
+	if opts.GoPackages == "" {
+		return func() {}, nil
+	}
+	if opts.CRDSource != "" {
+		return nil, fmt.Errorf("only one of a CRD source or Go packages can be set")
+	}
+	dir, err := crdsFromGoPackages(opts.GoPackages, opts.ControllerGen)
+	if err != nil {
+		return nil, err
+	}
+	opts.CRDSource = dir
+	return func() { _ = os.RemoveAll(dir) }, nil
+}
+
+// MissingGroupKindsError reports the GroupKinds of the input CRDs that have no paths in the cluster's swagger doc.
+type MissingGroupKindsError struct {
+	// GroupKinds describes each missing GroupKind along with close matches the cluster does serve.
+	GroupKinds []string
+}
+
+func (e *MissingGroupKindsError) Error() string {
+	return fmt.Sprintf("failed to find paths for GroupKinds [%s]", strings.Join(e.GroupKinds, ", "))
+}
+
+// loadCRDs gets the CRDs requested by the user.
+func loadCRDs(ctx context.Context, opts *Options) ([]*apiextv1.CustomResourceDefinition, error) {
+	if opts.CRDSource == "" && opts.ResourcesFile != "" {
+		// only built-in resources are documented
+		return nil, nil
+	}
+	if opts.CRDSource == "" {
+		return nil, fmt.Errorf("no CRD source set, either CRD files, Go packages, or a resources file are required")
+	}
+	zap.S().Info("Gathering CustomResourceDefinitions from source.")
+	crdMap, err := crdsFromInput(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CRDs: %w", err)
+	}
+	if len(crdMap) == 0 {
+		return nil, fmt.Errorf("no CRDs found at '%s'", opts.CRDSource)
+	}
+	// CRDs are sorted by name so they are installed and reported in the same order on every run
+	crds := make([]*apiextv1.CustomResourceDefinition, 0, len(crdMap))
+	for _, name := range sortedKeys(crdMap) {
+		crds = append(crds, crdMap[name])
+	}
+	if opts.ResourcesFile == "" {
+		return crds, nil
+	}
+	resources, _, err := loadResources(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	aliases, err := loadAliases(opts.AliasesFile)
+	if err != nil {
+		return nil, err
+	}
+	resources, _ = splitVirtual(resources)
+	return selectResources(crds, resources, aliases), nil
+}
+
+// startCluster creates and starts the cluster for the configured engine.
+func startCluster(ctx context.Context, opts *Options, timer *phaseTimer) (cluster, error) {
+	cluster, err := newCluster(opts, timer)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case opts.OpenAPIURL != "":
+	case opts.ClusterProvider != nil:
+		zap.S().Info("Connecting to the provided API server.")
+	case opts.Server != "":
+		zap.S().Infof("Connecting to API server %s.", opts.Server)
+	default:
+		zap.S().Infof("Starting cluster using the %s engine.", opts.Engine)
+	}
+	timer.reset()
+	if opts.OpenAPIURL == "" && (opts.Server != "" || opts.ClusterProvider != nil) {
+		// started clusters time their own phases
+		timer.start(phaseClusterReady)
+	}
+	err = cluster.start(ctx)
+	if err != nil {
+		// the container or processes started before the failure would outlive the run and be in the way of the next one
+		if stopErr := cluster.stop(ctx); stopErr != nil {
+			zap.S().Warnf("Failed to tear down the cluster that failed to start: %v", stopErr)
+		}
+		return nil, fmt.Errorf("failed to start cluster: %w", err)
+	}
+	if timer.current == phaseClusterReady {
+		timer.done(phaseClusterReady)
+	}
+	return cluster, nil
+}
+
+// generateFromCluster installs the CRDs into a running cluster and returns the filtered swagger doc.
+func generateFromCluster(ctx context.Context, opts *Options, cluster cluster, timer *phaseTimer, crds []*apiextv1.CustomResourceDefinition) (*spec.Swagger, error) {
+	swagger, err := fetchSwagger(ctx, opts, cluster, timer, crds)
+	if err != nil {
+		return nil, err
+	}
+	opts.filterLimiter.acquire()
+	defer opts.filterLimiter.release()
+	return filterSwagger(ctx, opts, swagger, timer, crds)
+}
+
+// groupKindsOf converts the list of crds to a map of GroupKind.
+// The boolean value is used to identify if the desired GK was found in the paths.
+func groupKindsOf(crds []*apiextv1.CustomResourceDefinition) map[v1.GroupKind]bool {
+	groupKinds := make(map[v1.GroupKind]bool, len(crds))
+	for _, crd := range crds {
+		gk := v1.GroupKind{
+			Group: crd.Spec.Group,
+			Kind:  crd.Spec.Names.Kind,
+		}
+		// add the CRDs GK to the map and initialize it to notFound aka false
+		groupKinds[gk] = false
+	}
+	return groupKinds
+}
+
+// fetchSwagger installs the CRDs into a running cluster and returns the cluster's full swagger doc once it includes them.
+func fetchSwagger(ctx context.Context, opts *Options, cluster cluster, timer *phaseTimer, crds []*apiextv1.CustomResourceDefinition) (*spec.Swagger, error) {
+	timer.reset()
+	var swagger *spec.Swagger
+	if opts.OpenAPIURL == "" {
+		zap.S().Info("Installing CRDs into the cluster.")
+		timer.start(phaseCRDInstall)
+		start := time.Now()
+		err := installCRDs(ctx, opts, cluster, crds)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create CRDs: %w", err)
+		}
+		timer.done(phaseCRDInstall)
+
+		if len(opts.ApplyManifests) != 0 || len(opts.Charts) != 0 {
+			zap.S().Info("Applying manifests to the cluster.")
+			timer.start(phaseManifests)
+			objs, err := helmChartObjects(opts.Charts, opts.ChartVersion)
+			if err != nil {
+				return nil, err
+			}
+			for _, manifest := range opts.ApplyManifests {
+				manifestObjs, err := manifestsFromInput(manifest)
+				if err != nil {
+					return nil, err
+				}
+				objs = append(objs, manifestObjs...)
+			}
+			if err := cluster.applyManifests(ctx, objs); err != nil {
+				return nil, err
+			}
+			timer.done(phaseManifests)
+		}
+
+		// wait for k8s to add the newly installed CRDs to the swagger doc
+		timer.start(phaseDiscovery)
+		swagger, err = waitForGroupKinds(ctx, cluster, groupKindsOf(crds), start, opts)
+		var missingErr *MissingGroupKindsError
+		if err != nil && !(opts.IgnoreMissing && errors.As(err, &missingErr)) {
+			return nil, err
+		}
+		if err != nil {
+			zap.S().Warnf("Continuing without the missing GroupKinds: %v", err)
+		}
+		timer.done(phaseDiscovery)
+	} else {
+		var err error
+		timer.start(phaseOpenAPIFetch)
+		swagger, err = cluster.getSwagger(ctx)
+		if err != nil {
+			return nil, err
+		}
+		timer.done(phaseOpenAPIFetch)
+	}
+	return swagger, nil
+}
+
+// filterSwagger filters the cluster's full swagger doc down to the paths and definitions used by the CRDs.
+func filterSwagger(ctx context.Context, opts *Options, swagger *spec.Swagger, timer *phaseTimer, crds []*apiextv1.CustomResourceDefinition) (*spec.Swagger, error) {
+	timer.start(phaseFilter)
+	zap.S().Info("Creating new Swagger doc.")
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	desiredGroupKinds := groupKindsOf(crds)
+	var virtualPaths []string
+	// versions are the versions of the GroupKinds the resources file pins to a version
+	var versions map[v1.GroupKind]map[string]bool
+	// entries are the per-resource options of a resources manifest
+	var entries []resourceEntry
+	// partialErr reports the GroupKinds and resources left out of the doc with ignore-missing
+	var partialErr error
+	if opts.ResourcesFile != "" {
+		var resources []string
+		var err error
+		resources, entries, err = loadResources(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		aliases, err := loadAliases(opts.AliasesFile)
+		if err != nil {
+			return nil, err
+		}
+		resources, virtual := splitVirtual(resources)
+		versions, err = addResourceGroupKinds(swagger, resources, aliases, desiredGroupKinds)
+		if err != nil {
+			var missingErr *MissingGroupKindsError
+			if !(opts.IgnoreMissing && errors.As(err, &missingErr)) {
+				return nil, err
+			}
+			zap.S().Warnf("Generating the swagger doc without the missing resources: %v", err)
+			partialErr = err
+		}
+		virtualPaths = addVirtualGroupKinds(swagger, virtual, desiredGroupKinds)
+	}
+	keepPaths, err := getDesiredPaths(swagger, desiredGroupKinds)
+	keepPaths = appendMissingPaths(keepPaths, virtualPaths)
+	if opts.Summary != nil {
+		opts.Summary.recordGroupKinds(desiredGroupKinds)
+	}
+	var missingErr *MissingGroupKindsError
+	if errors.As(err, &missingErr) && opts.IgnoreMissing && len(keepPaths) != 0 {
+		zap.S().Warnf("Generating the swagger doc without the missing GroupKinds: %v", err)
+		partialErr = errors.Join(partialErr, err)
+	} else if err != nil {
+		return nil, err
+	}
+	keepPaths, err = filterPinnedVersions(swagger, keepPaths, versions)
+	if errors.As(err, &missingErr) && opts.IgnoreMissing && len(keepPaths) != 0 {
+		zap.S().Warnf("Generating the swagger doc without the missing versions: %v", err)
+		partialErr = errors.Join(partialErr, err)
+	} else if err != nil {
+		return nil, err
+	}
+
+	if opts.DefinitionsOnly {
+		if err := keepDefinitionsOnly(swagger, desiredGroupKinds, versions); err != nil {
+			return nil, err
+		}
+	} else {
+		keepPaths = applyResourceEntries(swagger, keepPaths, entries)
+		keepPaths = filterSubresources(keepPaths, opts.ExcludeSubresources, opts.SubresourcesOnly)
+		keepPaths = filterVerbs(swagger, keepPaths, opts.Verbs, opts.ExcludeVerbs)
+
+		// remove all paths that are not for the desired CRDs
+		aggregator.FilterSpecByPaths(swagger, keepPaths)
+	}
+
+	if opts.RBACAnnotations {
+		annotateRBAC(swagger)
+	}
+	if opts.StevePaths && !opts.SubresourcesOnly && !opts.DefinitionsOnly {
+		addStevePaths(swagger, crds, opts.Verbs, opts.ExcludeVerbs)
+	}
+	annotateSelectableFields(swagger, crds)
+	if opts.AnnotateMinVersions {
+		annotateMinVersions(swagger, crds)
+	}
+	if opts.FlattenAllOf {
+		for _, lossy := range flattenAllOf(swagger) {
+			zap.S().Warnf("Lossy allOf merge %s", lossy)
+		}
+	}
+	if opts.Anonymize {
+		anonymize(swagger)
+	}
+	if opts.StripDescriptions || len(opts.StripExtensions) != 0 {
+		if err := stripDocs(swagger, opts.StripDescriptions, opts.StripExtensions); err != nil {
+			return nil, err
+		}
+	}
+	if err := prefixPaths(swagger, opts.PathPrefix); err != nil {
+		return nil, err
+	}
+	setDocumentFields(swagger, opts)
+	timer.done(phaseFilter)
+
+	if len(opts.PostProcessors) != 0 {
+		timer.start(phasePostProcess)
+		swagger, err = runPostProcessors(ctx, swagger, opts, desiredGroupKinds)
+		if err != nil {
+			return nil, err
+		}
+		timer.done(phasePostProcess)
+	}
+	if opts.ResolveRefs {
+		if err := resolveRefs(swagger); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Canonical {
+		canonicalize(swagger)
+	}
+
+	if partialErr != nil {
+		return swagger, partialErr
+	}
+	return swagger, nil
+}
+
+// appendMissingPaths appends the paths that are not already in keepPaths.
+func appendMissingPaths(keepPaths, paths []string) []string {
+	kept := make(map[string]bool, len(keepPaths))
+	for _, pathName := range keepPaths {
+		kept[pathName] = true
+	}
+	for _, pathName := range paths {
+		if !kept[pathName] {
+			keepPaths = append(keepPaths, pathName)
+			kept[pathName] = true
+		}
+	}
+	return keepPaths
+}
+
+// getDesiredPaths gets a list of paths to keep by checking if the path specified in the swagger doc references any of the desiredGroupKinds.
+func getDesiredPaths(swagger *spec.Swagger, desiredGroupKinds map[v1.GroupKind]bool) ([]string, error) {
+	if swagger.Paths == nil {
+		return nil, fmt.Errorf("cluster's swagger doc has no paths set")
+	}
+	var keepPaths []string
+	for pathName, pathItem := range swagger.Paths.Paths {
+		gks := groupKindsFromPath(pathItem)
+		for i := range gks {
+			if _, ok := desiredGroupKinds[gks[i]]; ok {
+				keepPaths = append(keepPaths, pathName)
+				desiredGroupKinds[gks[i]] = true // set the GK as found
+				break
+			}
+		}
+	}
+	var missing []v1.GroupKind
+	for gk, foundPath := range desiredGroupKinds {
+		if !foundPath {
+			missing = append(missing, gk)
+		}
+	}
+	if len(missing) != 0 {
+		return keepPaths, &MissingGroupKindsError{GroupKinds: missingGroupKinds(missing, swagger)}
+	}
+	return keepPaths, nil
+}
@@ -0,0 +1,31 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+const defaultControllerGen = "controller-gen"
+
+// crdsFromGoPackages runs controller-gen to generate the CRDs for the Go types in packages, e.g. ./pkg/apis/...,
+// and returns the temporary directory the CRDs were written to. The caller must remove the directory.
+func crdsFromGoPackages(packages, controllerGen string) (string, error) {
+	dir, err := os.MkdirTemp("", "crd-swagger-gen-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create directory for generated CRDs: %w", err)
+	}
+	zap.S().Infof("Generating CRDs from the Go types in %s.", packages)
+	var stderr bytes.Buffer
+	cmd := exec.Command(controllerGen, "crd", "paths="+packages, "output:crd:dir="+dir)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		_ = os.RemoveAll(dir)
+		return "", fmt.Errorf("failed to run %s (is it installed?): %w: %s", controllerGen, err, strings.TrimSpace(stderr.String()))
+	}
+	return dir, nil
+}
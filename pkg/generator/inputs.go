@@ -0,0 +1,172 @@
+package generator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// inputCacheDir is the directory in the cache dir that remote inputs are cached in.
+	inputCacheDir = "inputs"
+	// inputTimeout limits how long downloading a remote input may take so an unresponsive server does not hang the run.
+	inputTimeout = time.Minute
+)
+
+// inputClient downloads remote inputs.
+var inputClient = &http.Client{Timeout: inputTimeout}
+
+// inputMeta is what is recorded about a cached remote input to revalidate it.
+type inputMeta struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+// fetchInput returns the contents of a remote input such as a CRD or resources file URL. When opts.CacheDir is set
+// the contents are cached by URL and revalidated with the ETag and Last-Modified headers of the cached copy.
+// The cached copy is used with a warning when the server can not be reached or fails, and is the only copy used
+// when opts.OfflineInputs is set.
+func fetchInput(ctx context.Context, opts *Options, url string) ([]byte, error) {
+	if opts.CacheDir == "" {
+		if opts.OfflineInputs {
+			return nil, fmt.Errorf("can not read '%s' offline without a cache dir", url)
+		}
+		data, _, _, err := downloadInput(ctx, url, nil)
+		return data, err
+	}
+	dir := filepath.Join(opts.CacheDir, inputCacheDir)
+	hash := sha256.Sum256([]byte(url))
+	key := hex.EncodeToString(hash[:])
+	cached, meta, err := readCachedInput(dir, key)
+	if err != nil {
+		return nil, err
+	}
+	if opts.OfflineInputs {
+		if cached == nil {
+			return nil, fmt.Errorf("no cached copy of '%s', run once without offline inputs to cache it", url)
+		}
+		zap.S().Debugf("Using cached copy of %s.", url)
+		return cached, nil
+	}
+
+	data, newMeta, retryable, err := downloadInput(ctx, url, meta)
+	switch {
+	case err != nil && retryable && cached != nil:
+		zap.S().Warnf("Using cached copy of %s: %v", url, err)
+		return cached, nil
+	case err != nil:
+		return nil, err
+	case data == nil:
+		zap.S().Debugf("Cached copy of %s is up to date.", url)
+		return cached, nil
+	}
+	if err := writeCachedInput(dir, key, data, newMeta); err != nil {
+		zap.S().Warnf("Failed to cache %s: %v", url, err)
+	}
+	return data, nil
+}
+
+// downloadInput gets the input at url, revalidating the cached copy described by meta if it is not nil.
+// Returns nil data if the cached copy is up to date. retryable reports whether the error is from the connection
+// or a server failure rather than the request. The download is canceled with ctx or after inputTimeout.
+func downloadInput(ctx context.Context, url string, meta *inputMeta) (data []byte, newMeta inputMeta, retryable bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, inputMeta{}, false, fmt.Errorf("failed to create request for '%s': %w", url, err)
+	}
+	if meta != nil {
+		if meta.ETag != "" {
+			req.Header.Set("If-None-Match", meta.ETag)
+		}
+		if meta.LastModified != "" {
+			req.Header.Set("If-Modified-Since", meta.LastModified)
+		}
+	}
+	resp, err := inputClient.Do(req)
+	if err != nil {
+		// a canceled run is not a reason to fall back to the cached copy
+		return nil, inputMeta{}, ctx.Err() == nil, fmt.Errorf("failed to get '%s': %w", url, err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotModified && meta != nil:
+		return nil, *meta, false, nil
+	case resp.StatusCode != http.StatusOK:
+		retryable = resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests
+		return nil, inputMeta{}, retryable, fmt.Errorf("failed to get '%s': %s", url, resp.Status)
+	}
+	data, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, inputMeta{}, ctx.Err() == nil, fmt.Errorf("failed to read '%s': %w", url, err)
+	}
+	newMeta = inputMeta{URL: url, ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}
+	return data, newMeta, false, nil
+}
+
+// readCachedInput returns the cached input for key and its metadata, or nil if it is not cached.
+func readCachedInput(dir, key string) ([]byte, *inputMeta, error) {
+	metaData, err := os.ReadFile(filepath.Join(dir, key+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read cached input: %w", err)
+	}
+	var meta inputMeta
+	if err := json.Unmarshal(metaData, &meta); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode cached input: %w", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read cached input: %w", err)
+	}
+	return data, &meta, nil
+}
+
+// writeCachedInput stores the input and its metadata under key. Both are written to temporary files first so
+// concurrent runs never read a partially written input.
+func writeCachedInput(dir, key string, data []byte, meta inputMeta) error {
+	metaData, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("failed to marshal input metadata: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create cache dir '%s': %w", dir, err)
+	}
+	// the contents are written before the metadata so metadata is never read for missing contents
+	for _, file := range []struct {
+		name string
+		data []byte
+	}{{key, data}, {key + ".json", metaData}} {
+		tmp, err := os.CreateTemp(dir, key+"-*.tmp")
+		if err != nil {
+			return fmt.Errorf("failed to create cache file: %w", err)
+		}
+		defer os.Remove(tmp.Name())
+		if _, err := tmp.Write(file.data); err != nil {
+			tmp.Close()
+			return fmt.Errorf("failed to write cache file: %w", err)
+		}
+		if err := tmp.Close(); err != nil {
+			return fmt.Errorf("failed to write cache file: %w", err)
+		}
+		if err := os.Rename(tmp.Name(), filepath.Join(dir, file.name)); err != nil {
+			return fmt.Errorf("failed to write cache file: %w", err)
+		}
+	}
+	return nil
+}
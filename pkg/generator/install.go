@@ -0,0 +1,113 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// installStages groups the CRDs into the stages they are installed in, each stage is installed and ready
+// before the next one starts. With an install order each line of the file is a stage of the CRDs matching
+// its Kind.group entry, CRDs matching no line are installed last. Without one the CRDs that convert using
+// a webhook are installed after the rest so a slow conversion webhook does not hold up the other CRDs.
+func installStages(crds []*apiextv1.CustomResourceDefinition, installOrder []string) [][]*apiextv1.CustomResourceDefinition {
+	sorted := make([]*apiextv1.CustomResourceDefinition, len(crds))
+	copy(sorted, crds)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	stageOf := func(crd *apiextv1.CustomResourceDefinition) int {
+		if len(installOrder) == 0 {
+			if usesConversionWebhook(crd) {
+				return 1
+			}
+			return 0
+		}
+		gk := v1.GroupKind{Group: crd.Spec.Group, Kind: crd.Spec.Names.Kind}
+		for i, resource := range installOrder {
+			if matchResource(resource, gk) {
+				return i
+			}
+		}
+		return len(installOrder)
+	}
+
+	stages := make([][]*apiextv1.CustomResourceDefinition, len(installOrder)+2)
+	for _, crd := range sorted {
+		i := stageOf(crd)
+		stages[i] = append(stages[i], crd)
+	}
+	// drop the empty stages
+	result := stages[:0]
+	for _, stage := range stages {
+		if len(stage) != 0 {
+			result = append(result, stage)
+		}
+	}
+	return result
+}
+
+func usesConversionWebhook(crd *apiextv1.CustomResourceDefinition) bool {
+	return crd.Spec.Conversion != nil && crd.Spec.Conversion.Strategy == apiextv1.WebhookConverter
+}
+
+// conversionNamespaces returns the namespaces of the conversion webhook services referenced by the CRDs.
+func conversionNamespaces(crds []*apiextv1.CustomResourceDefinition) []string {
+	found := map[string]bool{}
+	for _, crd := range crds {
+		if !usesConversionWebhook(crd) || crd.Spec.Conversion.Webhook == nil || crd.Spec.Conversion.Webhook.ClientConfig == nil {
+			continue
+		}
+		if service := crd.Spec.Conversion.Webhook.ClientConfig.Service; service != nil && service.Namespace != "" {
+			found[service.Namespace] = true
+		}
+	}
+	return sortedKeys(found)
+}
+
+// installCRDs installs the CRDs into the cluster one stage at a time, see installStages.
+func installCRDs(ctx context.Context, opts *Options, cluster cluster, crds []*apiextv1.CustomResourceDefinition) error {
+	var installOrder []string
+	if opts.InstallOrder != "" {
+		var err error
+		installOrder, err = loadResourceFile(opts.InstallOrder, "install order file")
+		if err != nil {
+			return err
+		}
+	}
+	stages := installStages(crds, installOrder)
+	for i, stage := range stages {
+		if len(stages) > 1 {
+			zap.S().Infof("Installing stage %d of %d with %d CRDs.", i+1, len(stages), len(stage))
+		}
+		if err := cluster.ensureCRD(ctx, stage); err != nil {
+			return fmt.Errorf("failed to install stage %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// ensureNamespaces creates the namespaces that do not exist yet.
+func (d *apiClient) ensureNamespaces(ctx context.Context, namespaces []string) error {
+	if len(namespaces) == 0 {
+		return nil
+	}
+	cs, err := kubernetes.NewForConfig(d.cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create clientset: %w", err)
+	}
+	for _, name := range namespaces {
+		ns := &corev1.Namespace{ObjectMeta: v1.ObjectMeta{Name: name}}
+		_, err := cs.CoreV1().Namespaces().Create(ctx, ns, v1.CreateOptions{})
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create namespace '%s': %w", name, err)
+		}
+	}
+	return nil
+}
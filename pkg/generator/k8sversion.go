@@ -0,0 +1,78 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// k3sImages is the k3s image run for each supported Kubernetes minor version.
+var k3sImages = map[string]string{
+	"1.24": "rancher/k3s:v1.24.17-k3s1",
+	"1.25": "rancher/k3s:v1.25.14-k3s1",
+	"1.26": "rancher/k3s:v1.26.9-k3s1",
+	"1.27": DefaultImage,
+	"1.28": "rancher/k3s:v1.28.2-k3s1",
+}
+
+var (
+	minorVersionRegex = regexp.MustCompile(`^v?(\d+\.\d+)$`)
+	patchVersionRegex = regexp.MustCompile(`^v?(\d+\.\d+\.\d+)(?:[+-]k3s(\d+))?$`)
+)
+
+// KubernetesVersions returns the sorted Kubernetes minor versions that have a known k3s image.
+func KubernetesVersions() []string {
+	versions := sortedKeys(k3sImages)
+	sort.Slice(versions, func(i, j int) bool {
+		var iMajor, iMinor, jMajor, jMinor int
+		_, _ = fmt.Sscanf(versions[i], "%d.%d", &iMajor, &iMinor)
+		_, _ = fmt.Sscanf(versions[j], "%d.%d", &jMajor, &jMinor)
+		if iMajor != jMajor {
+			return iMajor < jMajor
+		}
+		return iMinor < jMinor
+	})
+	return versions
+}
+
+// imageForKubernetesVersion returns the k3s image for a Kubernetes version.
+// A minor version (v1.26) uses the known image for that minor, while a patch version (v1.26.9 or v1.26.9+k3s2)
+// is converted to the matching k3s image tag.
+func imageForKubernetesVersion(version string) (string, error) {
+	if match := minorVersionRegex.FindStringSubmatch(version); match != nil {
+		image, ok := k3sImages[match[1]]
+		if !ok {
+			return "", fmt.Errorf("no k3s image known for Kubernetes version '%s' must be a patch version or one of [%s]", version, strings.Join(KubernetesVersions(), ", "))
+		}
+		return image, nil
+	}
+	if match := patchVersionRegex.FindStringSubmatch(version); match != nil {
+		build := match[2]
+		if build == "" {
+			build = "1"
+		}
+		return fmt.Sprintf("rancher/k3s:v%s-k3s%s", match[1], build), nil
+	}
+	return "", fmt.Errorf("invalid Kubernetes version '%s' must be a minor version such as v1.27 or a patch version such as v1.27.5", version)
+}
+
+// resolveImage sets opts.Image to the k3s image for opts.KubernetesVersion when a version is selected.
+// It is safe to call more than once.
+func resolveImage(opts *Options) error {
+	if opts.KubernetesVersion == "" {
+		return nil
+	}
+	if opts.Engine != EngineDocker {
+		return fmt.Errorf("a Kubernetes version can only be selected with the %s engine", EngineDocker)
+	}
+	image, err := imageForKubernetesVersion(opts.KubernetesVersion)
+	if err != nil {
+		return err
+	}
+	if opts.Image != "" && opts.Image != image {
+		return fmt.Errorf("only one of an image or a Kubernetes version can be set")
+	}
+	opts.Image = image
+	return nil
+}
@@ -0,0 +1,88 @@
+package generator
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const (
+	// LintBoolDefaultTrue flags booleans that default to true, booleans should default to false
+	// so that the zero value is the default.
+	LintBoolDefaultTrue = "bool-default-true"
+	// LintIntDuration flags durations stored as plain integers without a unit in the field name.
+	LintIntDuration = "int-duration"
+	// LintDefaultNotInEnum flags defaults that are not one of the allowed enum values.
+	LintDefaultNotInEnum = "default-not-in-enum"
+	// LintAll enables every lint rule.
+	LintAll = "all"
+)
+
+var (
+	// LintRules lists every available lint rule.
+	LintRules = []string{LintBoolDefaultTrue, LintIntDuration, LintDefaultNotInEnum}
+
+	durationNameRegex = regexp.MustCompile(`(?i)(timeout|interval|duration|period|delay|ttl)$`)
+)
+
+// Finding is a problem found in a schema of the swagger doc.
+type Finding struct {
+	// Rule is the name of the rule that produced the finding.
+	Rule string
+	// Path is the location of the schema in the swagger doc, e.g. definitions/io.cattle.Foo/properties/spec.
+	Path string
+	// Message describes the problem.
+	Message string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s (%s)", f.Path, f.Message, f.Rule)
+}
+
+// LintDefaults checks the defaults of every schema in the swagger doc against the requested rules.
+// Rules may contain LintAll to enable every rule.
+func LintDefaults(swagger *spec.Swagger, rules []string) ([]Finding, error) {
+	enabled := map[string]bool{}
+	for _, rule := range rules {
+		if rule == LintAll {
+			for _, rule := range LintRules {
+				enabled[rule] = true
+			}
+			continue
+		}
+		if !containsString(LintRules, rule) {
+			return nil, fmt.Errorf("unknown lint rule '%s' must be one of [%s, %s]", rule, strings.Join(LintRules, ", "), LintAll)
+		}
+		enabled[rule] = true
+	}
+
+	var findings []Finding
+	walkSchemas(swagger, func(path string, schema *spec.Schema) {
+		for _, name := range sortedKeys(schema.Properties) {
+			prop := schema.Properties[name]
+			propPath := path + "/properties/" + name
+			if enabled[LintBoolDefaultTrue] && prop.Type.Contains("boolean") && prop.Default == true {
+				findings = append(findings, Finding{Rule: LintBoolDefaultTrue, Path: propPath, Message: "boolean defaults to true"})
+			}
+			if enabled[LintIntDuration] && prop.Type.Contains("integer") && durationNameRegex.MatchString(name) {
+				findings = append(findings, Finding{Rule: LintIntDuration, Path: propPath, Message: "duration is a plain integer without a unit in its name, use a duration string or add a unit suffix such as Seconds"})
+			}
+		}
+		if enabled[LintDefaultNotInEnum] && schema.Default != nil && len(schema.Enum) != 0 && !enumContains(schema.Enum, schema.Default) {
+			findings = append(findings, Finding{Rule: LintDefaultNotInEnum, Path: path, Message: fmt.Sprintf("default %v is not an allowed enum value", schema.Default)})
+		}
+	})
+	return findings, nil
+}
+
+func enumContains(enum []interface{}, value interface{}) bool {
+	for i := range enum {
+		if reflect.DeepEqual(enum[i], value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package generator
+
+import (
+	"context"
+	"sort"
+
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// ListGroupKinds starts the cluster, installs the input CRDs when a CRD source or Go packages are set,
+// and returns every GroupKind served by kube-apiserver sorted by group then kind.
+func ListGroupKinds(ctx context.Context, opts Options) (_ []v1.GroupKind, err error) {
+	opts.setDefaults()
+	cleanup, err := useGoPackages(&opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	var crds []*apiextv1.CustomResourceDefinition
+	if opts.CRDSource != "" {
+		if crds, err = loadCRDs(ctx, &opts); err != nil {
+			return nil, err
+		}
+	}
+
+	timer := newPhaseTimer(&opts)
+	defer timer.restoreLogger()
+	cluster, err := startCluster(ctx, &opts, timer)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		dumpLogsOnFailure(ctx, cluster, timer, err)
+		stopErr := cluster.stop(ctx)
+		if err == nil {
+			err = stopErr
+		}
+	}()
+
+	var swagger *spec.Swagger
+	if len(crds) != 0 {
+		swagger, err = fetchSwagger(ctx, &opts, cluster, timer, crds)
+	} else {
+		swagger, err = cluster.getSwagger(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return swaggerGroupKinds(swagger), nil
+}
+
+// swaggerGroupKinds returns every GroupKind with a path in the swagger doc sorted by group then kind.
+func swaggerGroupKinds(swagger *spec.Swagger) []v1.GroupKind {
+	found := map[v1.GroupKind]bool{}
+	if swagger.Paths != nil {
+		for _, pathItem := range swagger.Paths.Paths {
+			for _, gk := range groupKindsFromPath(pathItem) {
+				// discovery and version paths are not for a kind
+				if gk.Kind != "" {
+					found[gk] = true
+				}
+			}
+		}
+	}
+	groupKinds := make([]v1.GroupKind, 0, len(found))
+	for gk := range found {
+		groupKinds = append(groupKinds, gk)
+	}
+	sort.Slice(groupKinds, func(i, j int) bool {
+		if groupKinds[i].Group != groupKinds[j].Group {
+			return groupKinds[i].Group < groupKinds[j].Group
+		}
+		return groupKinds[i].Kind < groupKinds[j].Kind
+	})
+	return groupKinds
+}
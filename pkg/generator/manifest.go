@@ -0,0 +1,143 @@
+package generator
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"sigs.k8s.io/yaml"
+)
+
+// resourceManifest is a resources file in YAML or JSON that lists the resources to document with options of their own,
+// e.g.
+//
+//	resources:
+//	- group: provisioning.cattle.io
+//	  kind: Cluster
+//	  versions: [v1]
+//	  verbs: [get, list]
+//	  subresources: [status]
+//	  tags: [provisioning]
+type resourceManifest struct {
+	Resources []resourceEntry `json:"resources"`
+}
+
+// resourceEntry is a resource of a resources manifest. The kind and group may be globs like the Kind.group entries of a
+// flat resources file, core resources have no group.
+type resourceEntry struct {
+	// Group of the resource, empty for core resources.
+	Group string `json:"group,omitempty"`
+	// Kind of the resource.
+	Kind string `json:"kind"`
+	// Versions are the versions of the resource to document, every served version is documented when empty.
+	Versions []string `json:"versions,omitempty"`
+	// Verbs limits the operations of the resource's paths to the listed Kubernetes verbs.
+	Verbs []string `json:"verbs,omitempty"`
+	// Subresources limits the subresource paths of the resource to the listed subresources.
+	Subresources []string `json:"subresources,omitempty"`
+	// Tags are added to the tags of the resource's operations.
+	Tags []string `json:"tags,omitempty"`
+}
+
+// resource returns the Kind.group entry matching the resource's GroupKinds.
+func (e resourceEntry) resource() string {
+	return groupKindResource(v1.GroupKind{Group: e.Group, Kind: e.Kind})
+}
+
+// isResourceManifest reports whether the resources file, which may also be a URL, is a YAML or JSON manifest.
+func isResourceManifest(file string) bool {
+	if isURL(file) {
+		if u, err := url.Parse(file); err == nil {
+			file = u.Path
+		}
+	}
+	switch strings.ToLower(path.Ext(file)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// readResourceManifest parses the resources manifest in data, file describes the manifest in errors.
+func readResourceManifest(data []byte, file string) ([]resourceEntry, error) {
+	manifest := resourceManifest{}
+	if err := yaml.UnmarshalStrict(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse resources manifest '%s': %w", file, err)
+	}
+	for i, entry := range manifest.Resources {
+		if err := validateResource(entry.resource()); err != nil {
+			return nil, fmt.Errorf("invalid resource %d of '%s': %w", i+1, file, err)
+		}
+		for _, version := range entry.Versions {
+			if version == "" || strings.Contains(version, "/") {
+				return nil, fmt.Errorf("invalid resource %d of '%s': '%s' is not a version", i+1, file, version)
+			}
+		}
+	}
+	return manifest.Resources, nil
+}
+
+// manifestResources returns the Kind.group entries of the manifest entries, pinned to their versions.
+func manifestResources(entries []resourceEntry) []string {
+	var resources []string
+	for _, entry := range entries {
+		if len(entry.Versions) == 0 {
+			resources = append(resources, entry.resource())
+			continue
+		}
+		for _, version := range entry.Versions {
+			resources = append(resources, pinVersion(entry.resource(), version))
+		}
+	}
+	return resources
+}
+
+// applyResourceEntries filters the verbs and subresources of the listed paths and tags their operations using the
+// first manifest entry that matches the GroupKind of each path. The paths that are still kept are returned.
+func applyResourceEntries(swagger *spec.Swagger, paths []string, entries []resourceEntry) []string {
+	if len(entries) == 0 {
+		return paths
+	}
+	keepPaths := make([]string, 0, len(paths))
+	for _, pathName := range paths {
+		gvk, ok := pathGroupVersionKind(swagger.Paths.Paths[pathName])
+		if !ok {
+			keepPaths = append(keepPaths, pathName)
+			continue
+		}
+		var entry *resourceEntry
+		for i := range entries {
+			if matchResource(entries[i].resource(), v1.GroupKind{Group: gvk.Group, Kind: gvk.Kind}) {
+				entry = &entries[i]
+				break
+			}
+		}
+		if entry == nil {
+			keepPaths = append(keepPaths, pathName)
+			continue
+		}
+		if subresource := pathSubresource(pathName); subresource != "" && len(entry.Subresources) != 0 && !containsString(entry.Subresources, subresource) {
+			continue
+		}
+		if len(filterVerbs(swagger, []string{pathName}, entry.Verbs, nil)) == 0 {
+			continue
+		}
+		item := swagger.Paths.Paths[pathName]
+		for _, op := range pathOperations(&item) {
+			if *op == nil {
+				continue
+			}
+			for _, tag := range entry.Tags {
+				if !containsString((*op).Tags, tag) {
+					(*op).Tags = append((*op).Tags, tag)
+				}
+			}
+		}
+		swagger.Paths.Paths[pathName] = item
+		keepPaths = append(keepPaths, pathName)
+	}
+	return keepPaths
+}
@@ -0,0 +1,228 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rancher/wrangler/v2/pkg/apply"
+	"github.com/rancher/wrangler/v2/pkg/yaml"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+)
+
+const (
+	manifestSetID     = "crd-swagger-manifests"
+	manifestNamespace = "default"
+	manifestTime      = time.Minute * 2
+)
+
+var (
+	helmChartGVK = schema.FromAPIVersionAndKind(helmChartAPIVersion, helmChartKind)
+	jobGVR       = schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "jobs"}
+)
+
+// readyConditions are the status conditions waited on for applied manifests that report them.
+var readyConditions = []string{"Available", "Established"}
+
+// manifestsFromInput reads the Kubernetes objects from a YAML file, a directory of YAML files, or a remote file URL.
+func manifestsFromInput(path string) ([]runtime.Object, error) {
+	if isURL(path) {
+		resp, err := http.Get(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get manifests from '%s': %w", path, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to get manifests from '%s': %s", path, resp.Status)
+		}
+		return manifestsFromReader(path, resp.Body)
+	}
+	statInfo, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file '%s': %w", path, err)
+	}
+	if !statInfo.IsDir() {
+		return manifestsFromFile(path)
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dir '%s': %w", path, err)
+	}
+	var objs []runtime.Object
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+		fileObjs, err := manifestsFromFile(filepath.Join(path, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, fileObjs...)
+	}
+	return objs, nil
+}
+
+func manifestsFromFile(path string) ([]runtime.Object, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file '%s': %w", path, err)
+	}
+	defer file.Close()
+	return manifestsFromReader(path, file)
+}
+
+func manifestsFromReader(source string, reader io.Reader) ([]runtime.Object, error) {
+	objs, err := yaml.ToObjects(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode manifests from '%s': %w", source, err)
+	}
+	return objs, nil
+}
+
+// applyObjects applies the objects to the cluster and waits for the objects that report an Available or
+// Established condition to have it set, and for the install jobs of HelmCharts to complete. Applying is retried until timeout so objects can depend on CRDs
+// or APIServices created by earlier objects.
+func applyObjects(ctx context.Context, cfg *rest.Config, objs []runtime.Object, timeout time.Duration) error {
+	applier, err := apply.NewForConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create applier: %w", err)
+	}
+	applier = applier.WithContext(ctx).WithDynamicLookup().WithSetID(manifestSetID).WithDefaultNamespace(manifestNamespace).WithNoDelete()
+	dynamicClient, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	var applyErr error
+	applyFunc := func(context.Context) (bool, error) {
+		applyErr = applier.ApplyObjects(objs...)
+		if applyErr != nil {
+			zap.S().Debugf("Retrying manifest apply: %v", applyErr)
+			return false, nil
+		}
+		return true, nil
+	}
+	if err := wait.PollUntilContextTimeout(ctx, waitInterval, timeout, true, applyFunc); err != nil {
+		return fmt.Errorf("failed to apply manifests after %v: %w", timeout, applyErr)
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create discovery client: %w", err)
+	}
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient))
+
+	var notReady []string
+	readyFunc := func(ctx context.Context) (bool, error) {
+		notReady = notReady[:0]
+		for _, obj := range objs {
+			ready, err := manifestReady(ctx, mapper, dynamicClient, obj)
+			if err != nil {
+				return false, err
+			}
+			if !ready {
+				notReady = append(notReady, manifestName(obj))
+			}
+		}
+		return len(notReady) == 0, nil
+	}
+	if err := wait.PollUntilContextTimeout(ctx, waitInterval, timeout, true, readyFunc); err != nil {
+		return fmt.Errorf("manifests not ready after %v [%s]: %w", timeout, strings.Join(notReady, ", "), err)
+	}
+	return nil
+}
+
+// manifestReady reports if the object's Available or Established condition is true.
+// Objects that do not report either condition are always ready.
+func manifestReady(ctx context.Context, mapper meta.RESTMapper, dynamicClient dynamic.Interface, obj runtime.Object) (bool, error) {
+	objMeta, err := meta.Accessor(obj)
+	if err != nil {
+		return false, fmt.Errorf("failed to get metadata of manifest: %w", err)
+	}
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	if gvk.GroupKind() == helmChartGVK.GroupKind() {
+		return helmChartReady(ctx, dynamicClient, objMeta.GetName())
+	}
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return false, fmt.Errorf("failed to find resource for %s: %w", gvk.String(), err)
+	}
+	resource := dynamicClient.Resource(mapping.Resource)
+	var current *unstructured.Unstructured
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		namespace := objMeta.GetNamespace()
+		if namespace == "" {
+			namespace = manifestNamespace
+		}
+		current, err = resource.Namespace(namespace).Get(ctx, objMeta.GetName(), v1.GetOptions{})
+	} else {
+		current, err = resource.Get(ctx, objMeta.GetName(), v1.GetOptions{})
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get manifest %s: %w", manifestName(obj), err)
+	}
+	conditions, _, _ := unstructured.NestedSlice(current.Object, "status", "conditions")
+	for _, conditionType := range readyConditions {
+		for _, condition := range conditions {
+			conditionMap, ok := condition.(map[string]interface{})
+			if !ok || conditionMap["type"] != conditionType {
+				continue
+			}
+			if conditionMap["status"] != "True" {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// helmChartReady reports if the job installing the HelmChart has completed.
+func helmChartReady(ctx context.Context, dynamicClient dynamic.Interface, name string) (bool, error) {
+	job, err := dynamicClient.Resource(jobGVR).Namespace(helmChartNamespace).Get(ctx, helmChartJobName(name), v1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get install job of chart %s: %w", name, err)
+	}
+	conditions, _, _ := unstructured.NestedSlice(job.Object, "status", "conditions")
+	for _, condition := range conditions {
+		conditionMap, ok := condition.(map[string]interface{})
+		if !ok || conditionMap["status"] != "True" {
+			continue
+		}
+		switch conditionMap["type"] {
+		case "Complete":
+			return true, nil
+		case "Failed":
+			return false, fmt.Errorf("failed to install chart %s: %v", name, conditionMap["message"])
+		}
+	}
+	return false, nil
+}
+
+func manifestName(obj runtime.Object) string {
+	name := obj.GetObjectKind().GroupVersionKind().Kind
+	if objMeta, err := meta.Accessor(obj); err == nil {
+		name += " " + objMeta.GetName()
+	}
+	return name
+}
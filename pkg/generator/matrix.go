@@ -0,0 +1,93 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// GenerateMatrix generates a swagger doc for each Kubernetes version concurrently, each in its own
+// EngineDocker cluster with an automatically assigned port, running up to opts.MaxParallelClusters clusters at once. The docs are returned keyed by version.
+// Every version is generated even if others fail and the errors are joined together.
+func GenerateMatrix(ctx context.Context, opts Options, versions []string) (map[string]*spec.Swagger, error) {
+	if opts.Server != "" || opts.ClusterProvider != nil || opts.OpenAPIURL != "" {
+		return nil, fmt.Errorf("a version matrix can only be generated using a new cluster")
+	}
+	if opts.ClusterPort != "" {
+		return nil, fmt.Errorf("a cluster port can not be set when generating a version matrix")
+	}
+	if opts.Image != "" || opts.KubernetesVersion != "" {
+		return nil, fmt.Errorf("an image or Kubernetes version can not be set when generating a version matrix")
+	}
+	if opts.ContainerName == "" {
+		opts.ContainerName = defaultContainerName(&opts)
+	}
+	if opts.MaxParallelClusters < 0 || opts.MaxParallelFilters < 0 {
+		return nil, fmt.Errorf("the parallel cluster and filter limits must not be negative")
+	}
+	if opts.MaxParallelClusters == 0 {
+		opts.MaxParallelClusters = defaultMaxParallelClusters()
+	}
+	if opts.MaxParallelFilters == 0 {
+		opts.MaxParallelFilters = defaultMaxParallelFilters()
+	}
+	clusterLimiter := newLimiter(opts.MaxParallelClusters)
+	opts.filterLimiter = newLimiter(opts.MaxParallelFilters)
+	zap.S().Debugf("Running up to %d clusters and filtering up to %d docs at once.", opts.MaxParallelClusters, opts.MaxParallelFilters)
+
+	var (
+		lock   sync.Mutex
+		wg     sync.WaitGroup
+		docs   = make(map[string]*spec.Swagger, len(versions))
+		errs   []error
+		prefix = opts.ContainerName
+	)
+	for _, version := range uniqueVersions(versions) {
+		versionOpts := opts
+		versionOpts.KubernetesVersion = version
+		versionOpts.ContainerName = prefix + "-" + strings.NewReplacer("+", "-", ".", "-").Replace(version)
+		// concurrent pulls would interleave their progress output
+		versionOpts.PullOutput = nil
+		// concurrent runs would replace each other's global logger
+		versionOpts.LogFields = false
+
+		wg.Add(1)
+		go func(version string, versionOpts Options) {
+			defer wg.Done()
+			clusterLimiter.acquire()
+			zap.S().Infof("Generating swagger doc for Kubernetes %s.", version)
+			swagger, err := Generate(ctx, versionOpts)
+			clusterLimiter.release()
+			lock.Lock()
+			defer lock.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to generate swagger doc for Kubernetes %s: %w", version, err))
+			}
+			// a doc is returned along with the error when missing GroupKinds are ignored
+			if swagger != nil {
+				docs[version] = swagger
+			}
+		}(version, versionOpts)
+	}
+	wg.Wait()
+	return docs, errors.Join(errs...)
+}
+
+// uniqueVersions returns the versions without duplicates, in the order they were first listed.
+func uniqueVersions(versions []string) []string {
+	seen := make(map[string]bool, len(versions))
+	unique := make([]string, 0, len(versions))
+	for _, version := range versions {
+		if seen[version] {
+			continue
+		}
+		seen[version] = true
+		unique = append(unique, version)
+	}
+	return unique
+}
@@ -0,0 +1,25 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUniqueVersions(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions []string
+		want     []string
+	}{
+		{name: "empty", versions: nil, want: []string{}},
+		{name: "unique", versions: []string{"v1.27.1", "v1.26.5"}, want: []string{"v1.27.1", "v1.26.5"}},
+		{name: "duplicates keep first order", versions: []string{"v1.26.5", "v1.27.1", "v1.26.5", "v1.27.1"}, want: []string{"v1.26.5", "v1.27.1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := uniqueVersions(tt.versions); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("uniqueVersions(%v) = %v, want %v", tt.versions, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const extensionMinVersion = "x-min-kubernetes-version"
+
+// versionFeature is a CRD feature and the first Kubernetes minor version that serves it by default.
+type versionFeature struct {
+	name  string
+	minor int
+}
+
+var (
+	// featureCRDv1 is the baseline for every CRD read by this tool since only apiextensions.k8s.io/v1 is supported.
+	featureCRDv1             = versionFeature{name: "apiextensions.k8s.io/v1", minor: 16}
+	featureDeprecatedVersion = versionFeature{name: "deprecated versions", minor: 19}
+	featureValidationRules   = versionFeature{name: "x-kubernetes-validations (CEL)", minor: 25}
+	featureMessageExpression = versionFeature{name: "CEL messageExpression", minor: 27}
+	featureRuleReason        = versionFeature{name: "CEL reason and fieldPath", minor: 28}
+)
+
+// minVersionFeatures returns the features used by a CRD version that require the newest Kubernetes
+// version, and that version. Only features present in the apiextensions v1 types known to this tool are detected.
+func minVersionFeatures(version apiextv1.CustomResourceDefinitionVersion) (int, []string) {
+	used := map[versionFeature]bool{featureCRDv1: true}
+	if version.Deprecated {
+		used[featureDeprecatedVersion] = true
+	}
+	if version.Schema != nil && version.Schema.OpenAPIV3Schema != nil {
+		walkCRDSchema(version.Schema.OpenAPIV3Schema, func(props *apiextv1.JSONSchemaProps) {
+			for _, rule := range props.XValidations {
+				used[featureValidationRules] = true
+				if rule.MessageExpression != "" {
+					used[featureMessageExpression] = true
+				}
+				if rule.Reason != nil || rule.FieldPath != "" {
+					used[featureRuleReason] = true
+				}
+			}
+		})
+	}
+	minor := 0
+	var features []string
+	for feature := range used {
+		switch {
+		case feature.minor > minor:
+			minor = feature.minor
+			features = []string{feature.name}
+		case feature.minor == minor:
+			features = append(features, feature.name)
+		}
+	}
+	sort.Strings(features)
+	return minor, features
+}
+
+// walkCRDSchema calls fn for props and every schema nested in it.
+func walkCRDSchema(props *apiextv1.JSONSchemaProps, fn func(*apiextv1.JSONSchemaProps)) {
+	if props == nil {
+		return
+	}
+	fn(props)
+	for _, name := range sortedKeys(props.Properties) {
+		child := props.Properties[name]
+		walkCRDSchema(&child, fn)
+	}
+	for _, name := range sortedKeys(props.PatternProperties) {
+		child := props.PatternProperties[name]
+		walkCRDSchema(&child, fn)
+	}
+	if props.Items != nil {
+		walkCRDSchema(props.Items.Schema, fn)
+		for i := range props.Items.JSONSchemas {
+			walkCRDSchema(&props.Items.JSONSchemas[i], fn)
+		}
+	}
+	if props.AdditionalProperties != nil {
+		walkCRDSchema(props.AdditionalProperties.Schema, fn)
+	}
+	for _, list := range [][]apiextv1.JSONSchemaProps{props.AllOf, props.OneOf, props.AnyOf} {
+		for i := range list {
+			walkCRDSchema(&list[i], fn)
+		}
+	}
+	walkCRDSchema(props.Not, fn)
+}
+
+// annotateMinVersions logs the minimum Kubernetes version required by each served version of the CRDs
+// and sets it as the x-min-kubernetes-version extension of the matching definitions.
+func annotateMinVersions(swagger *spec.Swagger, crds []*apiextv1.CustomResourceDefinition) {
+	sorted := append([]*apiextv1.CustomResourceDefinition{}, crds...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	for _, crd := range sorted {
+		for _, version := range crd.Spec.Versions {
+			if !version.Served {
+				continue
+			}
+			gvk := schema.GroupVersionKind{Group: crd.Spec.Group, Version: version.Name, Kind: crd.Spec.Names.Kind}
+			minor, features := minVersionFeatures(version)
+			minVersion := fmt.Sprintf("v1.%d", minor)
+			zap.S().Infof("%s requires Kubernetes %s or later for %s.", gvk.String(), minVersion, strings.Join(features, ", "))
+			defName := definitionForGVK(swagger, gvk)
+			if defName == "" {
+				continue
+			}
+			def := swagger.Definitions[defName]
+			def.AddExtension(extensionMinVersion, minVersion)
+			swagger.Definitions[defName] = def
+		}
+	}
+}
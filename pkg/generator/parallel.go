@@ -0,0 +1,79 @@
+package generator
+
+import (
+	"bufio"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const (
+	// clusterCPUs is how many CPUs a k3s cluster is budgeted when deriving how many clusters run at once.
+	clusterCPUs = 2
+	// clusterMemory is how much memory a k3s cluster is budgeted when deriving how many clusters run at once.
+	clusterMemory = 1 << 30
+	// meminfoPath reports the host's memory on Linux.
+	meminfoPath = "/proc/meminfo"
+)
+
+// defaultMaxParallelClusters returns how many clusters the host can run at once, one per clusterCPUs CPUs
+// and clusterMemory of memory, and at least one.
+func defaultMaxParallelClusters() int {
+	n := runtime.NumCPU() / clusterCPUs
+	if memory := hostMemory(); memory != 0 && int(memory/clusterMemory) < n {
+		n = int(memory / clusterMemory)
+	}
+	if n < 1 {
+		return 1
+	}
+	return n
+}
+
+// defaultMaxParallelFilters returns how many docs are filtered at once, one per CPU since filtering is CPU bound.
+func defaultMaxParallelFilters() int {
+	return runtime.NumCPU()
+}
+
+// hostMemory returns the host's total memory in bytes, or 0 when it is not known.
+func hostMemory() uint64 {
+	file, err := os.Open(meminfoPath)
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// MemTotal:       16318412 kB
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 || fields[0] != "MemTotal:" || fields[2] != "kB" {
+			continue
+		}
+		kb, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0
+		}
+		return kb * 1024
+	}
+	return 0
+}
+
+// limiter bounds how many goroutines run a section at once. A nil limiter does not limit.
+type limiter chan struct{}
+
+func newLimiter(n int) limiter {
+	return make(limiter, n)
+}
+
+// acquire blocks until the section can be entered, release must be called when leaving it.
+func (l limiter) acquire() {
+	if l != nil {
+		l <- struct{}{}
+	}
+}
+
+func (l limiter) release() {
+	if l != nil {
+		<-l
+	}
+}
@@ -0,0 +1,94 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// PluginAPIVersion is the version of the post-processor protocol passed to plugins in EnvPluginAPIVersion.
+// It only changes if the protocol changes in a way existing plugins need to handle.
+//
+// A post-processor is an executable that reads the filtered swagger doc as JSON from stdin and writes the
+// mutated doc as JSON to stdout. Metadata about the run is passed in the Env* environment variables.
+// Anything written to stderr is logged and a non-zero exit code fails generation.
+const PluginAPIVersion = "v1"
+
+// Environment variables set for post-processor plugins.
+const (
+	// EnvPluginAPIVersion is the version of the post-processor protocol, see PluginAPIVersion.
+	EnvPluginAPIVersion = "CRD_SWAGGER_PLUGIN_API_VERSION"
+	// EnvCRDSource is the location the CRDs were read from.
+	EnvCRDSource = "CRD_SWAGGER_CRD_SOURCE"
+	// EnvGroupKinds is a comma separated sorted list of the documented GroupKinds, e.g. Cluster.management.cattle.io.
+	EnvGroupKinds = "CRD_SWAGGER_GROUP_KINDS"
+	// EnvEngine is the engine the cluster was run with, or empty when Server or OpenAPIURL was used.
+	EnvEngine = "CRD_SWAGGER_ENGINE"
+	// EnvImage is the k3s image the cluster was run with when using EngineDocker.
+	EnvImage = "CRD_SWAGGER_IMAGE"
+)
+
+// runPostProcessors passes the swagger doc through each plugin in order and returns the final doc.
+func runPostProcessors(ctx context.Context, swagger *spec.Swagger, opts *Options, desiredGroupKinds map[v1.GroupKind]bool) (*spec.Swagger, error) {
+	if len(opts.PostProcessors) == 0 {
+		return swagger, nil
+	}
+	env := append(os.Environ(), pluginEnv(opts, desiredGroupKinds)...)
+	for _, plugin := range opts.PostProcessors {
+		zap.S().Infof("Running post-processor %s.", plugin)
+		data, err := json.Marshal(swagger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal swagger doc: %w", err)
+		}
+		var stdout, stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, plugin)
+		cmd.Env = env
+		cmd.Stdin = bytes.NewReader(data)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		err = cmd.Run()
+		if stderr.Len() != 0 {
+			zap.S().Infof("Post-processor %s: %s", plugin, strings.TrimSpace(stderr.String()))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to run post-processor '%s': %w", plugin, err)
+		}
+		var processed spec.Swagger
+		if err := json.Unmarshal(stdout.Bytes(), &processed); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal swagger doc from post-processor '%s': %w", plugin, err)
+		}
+		swagger = &processed
+	}
+	return swagger, nil
+}
+
+// pluginEnv returns the environment variables describing the run for post-processors.
+func pluginEnv(opts *Options, desiredGroupKinds map[v1.GroupKind]bool) []string {
+	groupKinds := make([]string, 0, len(desiredGroupKinds))
+	for gk := range desiredGroupKinds {
+		groupKinds = append(groupKinds, gk.String())
+	}
+	sort.Strings(groupKinds)
+	engine, image := opts.Engine, ""
+	if opts.Server != "" || opts.ClusterProvider != nil || opts.OpenAPIURL != "" {
+		engine = ""
+	} else if opts.Engine == EngineDocker {
+		image = opts.Image
+	}
+	return []string{
+		EnvPluginAPIVersion + "=" + PluginAPIVersion,
+		EnvCRDSource + "=" + opts.CRDSource,
+		EnvGroupKinds + "=" + strings.Join(groupKinds, ","),
+		EnvEngine + "=" + engine,
+		EnvImage + "=" + image,
+	}
+}
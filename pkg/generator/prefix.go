@@ -0,0 +1,67 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+var pathParamRegex = regexp.MustCompile(`\{([^{}/]+)\}`)
+
+// unprefixedPath returns the path without the prefix added by prefixPaths, paths without the prefix are returned unchanged.
+func unprefixedPath(pathName, prefix string) string {
+	prefix = "/" + strings.Trim(prefix, "/")
+	if prefix == "/" {
+		return pathName
+	}
+	return strings.TrimPrefix(pathName, prefix)
+}
+
+// prefixPaths prepends prefix to every path of the swagger doc and adds a required string
+// path parameter for each {param} template in prefix, e.g. /k8s/clusters/{clusterId}.
+func prefixPaths(swagger *spec.Swagger, prefix string) error {
+	prefix = "/" + strings.Trim(prefix, "/")
+	if prefix == "/" || swagger.Paths == nil {
+		return nil
+	}
+	var params []spec.Parameter
+	for _, match := range pathParamRegex.FindAllStringSubmatch(prefix, -1) {
+		params = append(params, spec.Parameter{
+			ParamProps: spec.ParamProps{
+				Name:        match[1],
+				In:          "path",
+				Required:    true,
+				Description: fmt.Sprintf("%s from the path prefix %s", match[1], prefix),
+			},
+			SimpleSchema: spec.SimpleSchema{Type: "string"},
+		})
+	}
+	if strings.ContainsAny(pathParamRegex.ReplaceAllString(prefix, ""), "{}") {
+		return fmt.Errorf("invalid path parameter in path prefix '%s'", prefix)
+	}
+
+	paths := make(map[string]spec.PathItem, len(swagger.Paths.Paths))
+	for pathName, item := range swagger.Paths.Paths {
+		for _, param := range params {
+			if hasParameter(item.Parameters, param.Name) {
+				return fmt.Errorf("path prefix parameter '%s' is already used by path %s", param.Name, pathName)
+			}
+		}
+		item.Parameters = append(append([]spec.Parameter{}, params...), item.Parameters...)
+		paths[prefix+pathName] = item
+	}
+	swagger.Paths.Paths = paths
+	return nil
+}
+
+// hasParameter reports if a parameter named name is in params.
+func hasParameter(params []spec.Parameter, name string) bool {
+	for i := range params {
+		if params[i].Name == name {
+			return true
+		}
+	}
+	return false
+}
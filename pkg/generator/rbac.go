@@ -0,0 +1,183 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// extensionRequiredRBAC is set on each operation to the RBAC rule a user needs to call it.
+const extensionRequiredRBAC = "x-required-rbac"
+
+// actionRBACVerbs maps the x-kubernetes-action of an operation to its RBAC verb when they differ.
+var actionRBACVerbs = map[string]string{
+	"post":      "create",
+	"put":       "update",
+	"watchlist": "watch",
+}
+
+// methodRBACVerbs maps HTTP methods to RBAC verbs for operations without a known action, such as connect.
+var methodRBACVerbs = map[string]string{
+	"GET":    "get",
+	"HEAD":   "get",
+	"POST":   "create",
+	"PUT":    "update",
+	"PATCH":  "patch",
+	"DELETE": "delete",
+}
+
+const (
+	// RBACReadOnlyRole is the name of the ClusterRole granting read-only access to the documented kinds.
+	RBACReadOnlyRole = "crd-swagger-read-only"
+	// RBACReadWriteRole is the name of the ClusterRole granting access to every documented operation.
+	RBACReadWriteRole = "crd-swagger-read-write"
+)
+
+// readOnlyVerbs are the RBAC verbs that do not change resources.
+var readOnlyVerbs = []string{"get", "list", "watch"}
+
+// ClusterRoles returns example ClusterRoles granting read-only and read-write access to exactly the kinds
+// and subresources documented in the swagger doc, using the same mapping as the x-required-rbac extension.
+// pathPrefix is the prefix the doc's paths were generated with, see Options.PathPrefix. An error is returned when
+// the doc has no Kubernetes resource paths, such as a definitions only doc.
+func ClusterRoles(swagger *spec.Swagger, pathPrefix string) ([]*rbacv1.ClusterRole, error) {
+	// verbs of each resource keyed by apiGroup then resource
+	verbs := map[string]map[string]map[string]bool{}
+	if swagger.Paths != nil {
+		for pathName, item := range swagger.Paths.Paths {
+			resource := pathResource(unprefixedPath(pathName, pathPrefix))
+			if resource == "" {
+				continue
+			}
+			for method, op := range pathOperations(&item) {
+				if *op == nil {
+					continue
+				}
+				var gvk v1.GroupVersionKind
+				_ = (*op).Extensions.GetObject(extensionGVK, &gvk)
+				if verbs[gvk.Group] == nil {
+					verbs[gvk.Group] = map[string]map[string]bool{}
+				}
+				if verbs[gvk.Group][resource] == nil {
+					verbs[gvk.Group][resource] = map[string]bool{}
+				}
+				verbs[gvk.Group][resource][rbacVerb(method, *op)] = true
+			}
+		}
+	}
+
+	if len(verbs) == 0 {
+		return nil, fmt.Errorf("the swagger doc has no Kubernetes resource paths to derive ClusterRoles from")
+	}
+	roles := []*rbacv1.ClusterRole{
+		{ObjectMeta: v1.ObjectMeta{Name: RBACReadOnlyRole}, Rules: policyRules(verbs, readOnlyVerbs)},
+		{ObjectMeta: v1.ObjectMeta{Name: RBACReadWriteRole}, Rules: policyRules(verbs, nil)},
+	}
+	for _, role := range roles {
+		role.APIVersion = rbacv1.SchemeGroupVersion.String()
+		role.Kind = "ClusterRole"
+	}
+	return roles, nil
+}
+
+// policyRules converts the verbs of each resource to rules, limited to the allowed verbs when set.
+// Resources of the same apiGroup with the same verbs share a rule.
+func policyRules(verbs map[string]map[string]map[string]bool, allowed []string) []rbacv1.PolicyRule {
+	var rules []rbacv1.PolicyRule
+	for _, group := range sortedKeys(verbs) {
+		// index of the rule for each verb list in this group
+		ruleIndex := map[string]int{}
+		for _, resource := range sortedKeys(verbs[group]) {
+			var resourceVerbs []string
+			for _, verb := range sortedKeys(verbs[group][resource]) {
+				if verb != "" && (allowed == nil || containsString(allowed, verb)) {
+					resourceVerbs = append(resourceVerbs, verb)
+				}
+			}
+			if len(resourceVerbs) == 0 {
+				continue
+			}
+			key := strings.Join(resourceVerbs, ",")
+			if i, ok := ruleIndex[key]; ok {
+				rules[i].Resources = append(rules[i].Resources, resource)
+				continue
+			}
+			ruleIndex[key] = len(rules)
+			rules = append(rules, rbacv1.PolicyRule{APIGroups: []string{group}, Resources: []string{resource}, Verbs: resourceVerbs})
+		}
+	}
+	return rules
+}
+
+// annotateRBAC sets the x-required-rbac extension on every Kubernetes operation of the swagger doc
+// to the apiGroup, resource, and verb of the RBAC rule that allows calling it.
+func annotateRBAC(swagger *spec.Swagger) {
+	if swagger.Paths == nil {
+		return
+	}
+	for _, pathName := range sortedKeys(swagger.Paths.Paths) {
+		resource := pathResource(pathName)
+		if resource == "" {
+			continue
+		}
+		item := swagger.Paths.Paths[pathName]
+		for method, op := range pathOperations(&item) {
+			if *op == nil {
+				continue
+			}
+			var gvk v1.GroupVersionKind
+			_ = (*op).Extensions.GetObject(extensionGVK, &gvk)
+			(*op).AddExtension(extensionRequiredRBAC, map[string]string{
+				"apiGroup": gvk.Group,
+				"resource": resource,
+				"verb":     rbacVerb(method, *op),
+			})
+		}
+		swagger.Paths.Paths[pathName] = item
+	}
+}
+
+// rbacVerb returns the RBAC verb needed to call the operation.
+func rbacVerb(method string, op *spec.Operation) string {
+	action, _ := op.Extensions.GetString(extensionAction)
+	if verb, ok := actionRBACVerbs[action]; ok {
+		return verb
+	}
+	if action != "" && action != "connect" {
+		return action
+	}
+	return methodRBACVerbs[method]
+}
+
+// pathResource returns the RBAC resource of a Kubernetes API path, including the subresource,
+// e.g. foos/status for /apis/example.io/v1/namespaces/{namespace}/foos/{name}/status.
+// An empty string is returned for paths that are not for a resource.
+func pathResource(pathName string) string {
+	segments := strings.Split(strings.Trim(pathName, "/"), "/")
+	switch {
+	case len(segments) > 3 && segments[0] == "apis":
+		segments = segments[3:]
+	case len(segments) > 2 && segments[0] == "api":
+		segments = segments[2:]
+	default:
+		return ""
+	}
+	if segments[0] == "watch" {
+		segments = segments[1:]
+	}
+	// namespaces/{namespace}/... is a namespaced resource rather than the namespaces resource
+	if len(segments) > 2 && segments[0] == "namespaces" && strings.HasPrefix(segments[1], "{") {
+		segments = segments[2:]
+	}
+	if len(segments) == 0 || strings.HasPrefix(segments[0], "{") {
+		return ""
+	}
+	resource := segments[0]
+	if len(segments) > 2 {
+		resource += "/" + segments[2]
+	}
+	return resource
+}
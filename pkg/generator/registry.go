@@ -0,0 +1,99 @@
+package generator
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types/registry"
+	"go.uber.org/zap"
+)
+
+// dockerHubConfigKey is the key docker login uses for Docker Hub credentials in the docker config.
+const dockerHubConfigKey = "https://index.docker.io/v1/"
+
+// dockerConfig is the subset of ~/.docker/config.json used to find registry credentials.
+type dockerConfig struct {
+	Auths map[string]registry.AuthConfig `json:"auths"`
+}
+
+// encodedRegistryAuth returns the encoded credentials for the registry of image.
+// Credentials come from registryAuth in the form username:password if set,
+// otherwise from the auths stored in the docker config file. An empty string is returned when no credentials are found.
+func encodedRegistryAuth(image, registryAuth string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse image '%s': %w", image, err)
+	}
+	domain := reference.Domain(named)
+
+	var authConfig registry.AuthConfig
+	if registryAuth != "" {
+		username, password, found := strings.Cut(registryAuth, ":")
+		if !found {
+			return "", fmt.Errorf("registry auth must be in the form username:password")
+		}
+		authConfig = registry.AuthConfig{Username: username, Password: password}
+	} else {
+		var ok bool
+		authConfig, ok, err = dockerConfigAuth(domain)
+		if err != nil || !ok {
+			return "", err
+		}
+	}
+	authConfig.ServerAddress = domain
+	return registry.EncodeAuthConfig(authConfig)
+}
+
+// dockerConfigAuth reads the credentials for domain from the docker config file
+// found in $DOCKER_CONFIG or ~/.docker. Credential helpers are not supported.
+func dockerConfigAuth(domain string) (registry.AuthConfig, bool, error) {
+	configDir := os.Getenv("DOCKER_CONFIG")
+	if configDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return registry.AuthConfig{}, false, nil
+		}
+		configDir = filepath.Join(home, ".docker")
+	}
+	configPath := filepath.Join(configDir, "config.json")
+	data, err := os.ReadFile(configPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return registry.AuthConfig{}, false, nil
+	}
+	if err != nil {
+		return registry.AuthConfig{}, false, fmt.Errorf("failed to read docker config '%s': %w", configPath, err)
+	}
+	var config dockerConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return registry.AuthConfig{}, false, fmt.Errorf("failed to decode docker config '%s': %w", configPath, err)
+	}
+
+	keys := []string{domain, "https://" + domain}
+	if domain == "docker.io" {
+		keys = append(keys, dockerHubConfigKey)
+	}
+	for _, key := range keys {
+		authConfig, ok := config.Auths[key]
+		if !ok {
+			continue
+		}
+		if authConfig.Auth != "" {
+			// docker login stores the credentials as base64 encoded username:password
+			decoded, err := base64.StdEncoding.DecodeString(authConfig.Auth)
+			if err != nil {
+				return registry.AuthConfig{}, false, fmt.Errorf("failed to decode credentials for '%s' in '%s': %w", key, configPath, err)
+			}
+			authConfig.Username, authConfig.Password, _ = strings.Cut(string(decoded), ":")
+			authConfig.Auth = ""
+		}
+		zap.S().Infof("Using credentials for registry '%s' from '%s'.", domain, configPath)
+		return authConfig, true, nil
+	}
+	return registry.AuthConfig{}, false, nil
+}
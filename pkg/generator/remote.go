@@ -0,0 +1,70 @@
+package generator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// openAPIURLTimeout limits how long downloading the openapiv2 document may take so an unresponsive URL does not hang the run.
+const openAPIURLTimeout = time.Minute
+
+// openAPIURLSource is a cluster that only serves an existing openapiv2 document from a URL.
+// The CRDs are expected to already be installed in the API server serving the document.
+type openAPIURLSource struct {
+	url  string
+	opts *Options
+}
+
+func (o *openAPIURLSource) start(context.Context) error { return nil }
+
+func (o *openAPIURLSource) stop(context.Context) error { return nil }
+
+func (o *openAPIURLSource) ensureCRD(context.Context, []*apiextv1.CustomResourceDefinition) error {
+	return nil
+}
+
+func (o *openAPIURLSource) applyManifests(context.Context, []runtime.Object) error {
+	return fmt.Errorf("manifests can not be applied when using an openapi URL")
+}
+
+// getSwagger downloads the openapiv2 document from the URL. The download is canceled with ctx or after openAPIURLTimeout.
+func (o *openAPIURLSource) getSwagger(ctx context.Context) (*spec.Swagger, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, openAPIURLTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(timeoutCtx, http.MethodGet, o.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for '%s': %w", o.url, err)
+	}
+	req.Header.Set("Accept", "application/json")
+	// reuse the API server credentials so the token and CA are handled the same as with --server
+	client, err := rest.HTTPClientFor(serverRESTConfig(o.opts))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http client: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get swagger from '%s': %w", o.url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get swagger from '%s': %s", o.url, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read swagger from '%s': %w", o.url, err)
+	}
+	var swagger spec.Swagger
+	if err := json.Unmarshal(data, &swagger); err != nil {
+		return nil, fmt.Errorf("failed to decode swagger from '%s': %w", o.url, err)
+	}
+	return &swagger, nil
+}
@@ -0,0 +1,204 @@
+package generator
+
+import (
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const (
+	parameterRefPrefix = "#/parameters/"
+	responseRefPrefix  = "#/responses/"
+)
+
+// refInliner replaces references to definitions with copies of the definitions.
+type refInliner struct {
+	swagger *spec.Swagger
+	// keepCycles keeps references back to a definition being inlined, otherwise they are replaced by an empty
+	// schema that accepts any value.
+	keepCycles bool
+	// inlining are the definitions being inlined
+	inlining map[string]bool
+	// cycles are the definitions that reference themselves, directly or transitively
+	cycles map[string]bool
+}
+
+func newRefInliner(swagger *spec.Swagger, keepCycles bool) *refInliner {
+	return &refInliner{swagger: swagger, keepCycles: keepCycles, inlining: map[string]bool{}, cycles: map[string]bool{}}
+}
+
+// schema returns a copy of the schema with every reference to a definition replaced by the definition.
+// References to definitions that do not exist are kept.
+func (r *refInliner) schema(schema spec.Schema) spec.Schema {
+	if ref := schema.Ref.String(); ref != "" {
+		name := strings.TrimPrefix(ref, definitionRefPrefix)
+		def, ok := r.swagger.Definitions[name]
+		switch {
+		case !ok && r.keepCycles:
+			return schema
+		case !ok:
+			return spec.Schema{}
+		case r.inlining[name]:
+			r.cycles[name] = true
+			if r.keepCycles {
+				return schema
+			}
+			return spec.Schema{}
+		}
+		r.inlining[name] = true
+		defer delete(r.inlining, name)
+		return r.schema(def)
+	}
+	inlineMap := func(schemas map[string]spec.Schema) map[string]spec.Schema {
+		if schemas == nil {
+			return nil
+		}
+		inlined := make(map[string]spec.Schema, len(schemas))
+		for name, prop := range schemas {
+			inlined[name] = r.schema(prop)
+		}
+		return inlined
+	}
+	inlineList := func(schemas []spec.Schema) []spec.Schema {
+		if schemas == nil {
+			return nil
+		}
+		inlined := make([]spec.Schema, len(schemas))
+		for i := range schemas {
+			inlined[i] = r.schema(schemas[i])
+		}
+		return inlined
+	}
+	schema.Properties = inlineMap(schema.Properties)
+	schema.PatternProperties = inlineMap(schema.PatternProperties)
+	schema.AllOf = inlineList(schema.AllOf)
+	schema.AnyOf = inlineList(schema.AnyOf)
+	schema.OneOf = inlineList(schema.OneOf)
+	if schema.Not != nil {
+		not := r.schema(*schema.Not)
+		schema.Not = &not
+	}
+	if schema.Items != nil {
+		items := &spec.SchemaOrArray{Schemas: inlineList(schema.Items.Schemas)}
+		if schema.Items.Schema != nil {
+			item := r.schema(*schema.Items.Schema)
+			items.Schema = &item
+		}
+		schema.Items = items
+	}
+	if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+		additional := r.schema(*schema.AdditionalProperties.Schema)
+		schema.AdditionalProperties = &spec.SchemaOrBool{Allows: schema.AdditionalProperties.Allows, Schema: &additional}
+	}
+	return schema
+}
+
+// parameter returns a copy of the parameter, or the shared parameter it references, with its schema inlined.
+func (r *refInliner) parameter(param spec.Parameter) spec.Parameter {
+	if ref := param.Ref.String(); strings.HasPrefix(ref, parameterRefPrefix) {
+		if shared, ok := r.swagger.Parameters[strings.TrimPrefix(ref, parameterRefPrefix)]; ok {
+			param = shared
+		}
+	}
+	if param.Schema != nil {
+		schema := r.schema(*param.Schema)
+		param.Schema = &schema
+	}
+	return param
+}
+
+// response returns a copy of the response, or the shared response it references, with its schema inlined.
+func (r *refInliner) response(response spec.Response) spec.Response {
+	if ref := response.Ref.String(); strings.HasPrefix(ref, responseRefPrefix) {
+		if shared, ok := r.swagger.Responses[strings.TrimPrefix(ref, responseRefPrefix)]; ok {
+			response = shared
+		}
+	}
+	if response.Schema != nil {
+		schema := r.schema(*response.Schema)
+		response.Schema = &schema
+	}
+	return response
+}
+
+// resolveRefs inlines every reference to a definition, parameter, or response of the swagger doc so the doc is
+// self-contained. References that would inline a definition into itself are kept, along with the definitions they
+// reference. Docs without paths keep every definition since the definitions are their content.
+func resolveRefs(swagger *spec.Swagger) error {
+	inliner := newRefInliner(swagger, true)
+	if swagger.Paths != nil {
+		paths := make(map[string]spec.PathItem, len(swagger.Paths.Paths))
+		for pathName, item := range swagger.Paths.Paths {
+			item.Parameters = inlineParameters(inliner, item.Parameters)
+			for _, op := range pathOperations(&item) {
+				if *op == nil {
+					continue
+				}
+				resolved := **op
+				resolved.Parameters = inlineParameters(inliner, resolved.Parameters)
+				if resolved.Responses != nil {
+					responses := *resolved.Responses
+					if responses.Default != nil {
+						response := inliner.response(*responses.Default)
+						responses.Default = &response
+					}
+					codes := make(map[int]spec.Response, len(responses.StatusCodeResponses))
+					for code, response := range responses.StatusCodeResponses {
+						codes[code] = inliner.response(response)
+					}
+					responses.StatusCodeResponses = codes
+					resolved.Responses = &responses
+				}
+				*op = &resolved
+			}
+			paths[pathName] = item
+		}
+		swagger.Paths.Paths = paths
+	}
+
+	definitions := make(spec.Definitions, len(swagger.Definitions))
+	for name, def := range swagger.Definitions {
+		inliner.inlining[name] = true
+		definitions[name] = inliner.schema(def)
+		delete(inliner.inlining, name)
+	}
+	hasPaths := swagger.Paths != nil && len(swagger.Paths.Paths) != 0
+	swagger.Definitions = definitions
+	swagger.Parameters, swagger.Responses = nil, nil
+	if hasPaths {
+		// only the definitions still referenced by a cycle are kept
+		values := []interface{}{swagger.Paths}
+		refs, err := referencedBy(swagger, values)
+		if err != nil {
+			return err
+		}
+		for name := range swagger.Definitions {
+			if !refs.definitions[name] {
+				delete(swagger.Definitions, name)
+			}
+		}
+	}
+
+	if len(inliner.cycles) != 0 {
+		cycles := make([]string, 0, len(inliner.cycles))
+		for name := range inliner.cycles {
+			cycles = append(cycles, name)
+		}
+		sort.Strings(cycles)
+		zap.S().Warnf("Kept references to recursive definitions [%s]", strings.Join(cycles, ", "))
+	}
+	return nil
+}
+
+func inlineParameters(inliner *refInliner, params []spec.Parameter) []spec.Parameter {
+	if params == nil {
+		return nil
+	}
+	inlined := make([]spec.Parameter, len(params))
+	for i := range params {
+		inlined[i] = inliner.parameter(params[i])
+	}
+	return inlined
+}
@@ -0,0 +1,356 @@
+package generator
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// LoadResources reads the resources file at path, one Kind.group entry per line.
+// Built-in resources are listed the same way, e.g. Deployment.apps, and core resources by their kind, e.g. Pod.
+// Resources can also be listed kubectl style by their plural, e.g. roletemplates.management.cattle.io or pods.
+// An entry can be pinned to a version with a /version suffix, e.g. Cluster.provisioning.cattle.io/v1, so only the paths
+// and definitions of that version of the kind are documented.
+// Virtual resources that are never stored are listed with a template of their paths, see virtualPrefix.
+// Blank lines and lines starting with # are ignored.
+// YAML and JSON files are read as a resources manifest instead, see resourceEntry.
+func LoadResources(file string) ([]string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resources file: %w", err)
+	}
+	resources, _, err := parseResources(data, file)
+	return resources, err
+}
+
+// loadResources reads the resources file of the options, which may also be a URL.
+// The entries of a resources manifest are returned along with the resources they list.
+func loadResources(ctx context.Context, opts *Options) ([]string, []resourceEntry, error) {
+	var data []byte
+	var err error
+	if isURL(opts.ResourcesFile) {
+		data, err = fetchInput(ctx, opts, opts.ResourcesFile)
+	} else {
+		data, err = os.ReadFile(opts.ResourcesFile)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read resources file: %w", err)
+	}
+	return parseResources(data, opts.ResourcesFile)
+}
+
+// parseResources parses the resources file, either a resources manifest or a file of Kind.group lines.
+func parseResources(data []byte, file string) ([]string, []resourceEntry, error) {
+	if isResourceManifest(file) {
+		entries, err := readResourceManifest(data, file)
+		if err != nil {
+			return nil, nil, err
+		}
+		return manifestResources(entries), entries, nil
+	}
+	resources, err := readResources(bytes.NewReader(data), file, "resources file")
+	return resources, nil, err
+}
+
+// loadResourceFile reads the file of Kind.group entries, name describes the file in errors.
+func loadResourceFile(file, name string) ([]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	defer f.Close()
+	return readResources(f, file, name)
+}
+
+// readResources reads the Kind.group entries of the file from reader, name describes the file in errors.
+func readResources(reader io.Reader, file, name string) ([]string, error) {
+	var resources []string
+	scanner := bufio.NewScanner(reader)
+	for line := 1; scanner.Scan(); line++ {
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		if strings.HasPrefix(entry, virtualPrefix) {
+			if _, err := parseVirtual(entry); err != nil {
+				return nil, fmt.Errorf("invalid resource on line %d of '%s': %w", line, file, err)
+			}
+			resources = append(resources, entry)
+			continue
+		}
+		if err := validateResource(entry); err != nil {
+			return nil, fmt.Errorf("invalid resource on line %d of '%s': %w", line, file, err)
+		}
+		resources = append(resources, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s '%s': %w", name, file, err)
+	}
+	return resources, nil
+}
+
+// splitResource splits a Kind.group resource entry into its kind and group patterns.
+func splitResource(resource string) (kind, group string) {
+	resource, _ = splitVersion(resource)
+	kind, group, _ = strings.Cut(resource, ".")
+	return kind, group
+}
+
+// splitVersion splits the version a Kind.group/version resource entry is pinned to off the entry.
+func splitVersion(resource string) (entry, version string) {
+	entry, version, _ = strings.Cut(resource, "/")
+	return entry, version
+}
+
+// pinVersion returns the resource entry pinned to the version, entries are not pinned to an empty version.
+func pinVersion(resource, version string) string {
+	if version == "" {
+		return resource
+	}
+	return resource + "/" + version
+}
+
+func validateResource(resource string) error {
+	kind, group := splitResource(resource)
+	if _, version := splitVersion(resource); strings.Contains(resource, "/") && (version == "" || strings.Contains(version, "/")) {
+		return fmt.Errorf("'%s' must be pinned to a single version, e.g. Cluster.provisioning.cattle.io/v1", resource)
+	}
+	if kind == "" {
+		return fmt.Errorf("'%s' must be a Kind.group, e.g. Cluster.management.cattle.io or *.management.cattle.io", resource)
+	}
+	for _, pattern := range []string{kind, group} {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("'%s' is not a valid pattern: %w", resource, err)
+		}
+	}
+	return nil
+}
+
+// matchResource reports whether the GroupKind matches the resource entry, the kind and group of the entry
+// are glob patterns so *.management.cattle.io matches every kind in the group and Cluster.* matches the kind in any group.
+func matchResource(resource string, gk v1.GroupKind) bool {
+	kind, group := splitResource(resource)
+	kindMatch, _ := path.Match(kind, gk.Kind)
+	groupMatch, _ := path.Match(group, gk.Group)
+	return kindMatch && groupMatch
+}
+
+// resolvePlurals replaces the entries that name a resource by its plural.group, e.g. roletemplates.management.cattle.io,
+// with the Kind.group of the resource. plurals maps the plural.group of each known resource to its GroupKind.
+func resolvePlurals(resources []string, plurals map[string]v1.GroupKind) []string {
+	resolved := make([]string, len(resources))
+	for i, resource := range resources {
+		resolved[i] = resource
+		entry, version := splitVersion(resource)
+		if gk, ok := plurals[strings.ToLower(entry)]; ok {
+			resolved[i] = pinVersion(groupKindResource(gk), version)
+			zap.S().Debugf("Resolved resource %s to %s.", resource, resolved[i])
+		}
+	}
+	return resolved
+}
+
+// groupKindResource returns the resource entry matching exactly the GroupKind.
+func groupKindResource(gk v1.GroupKind) string {
+	if gk.Group == "" {
+		return gk.Kind
+	}
+	return gk.Kind + "." + gk.Group
+}
+
+// crdPlurals maps the plural.group of each CRD to its GroupKind.
+func crdPlurals(crds []*apiextv1.CustomResourceDefinition) map[string]v1.GroupKind {
+	plurals := make(map[string]v1.GroupKind, len(crds))
+	for _, crd := range crds {
+		gk := v1.GroupKind{Group: crd.Spec.Group, Kind: crd.Spec.Names.Kind}
+		plurals[strings.ToLower(crd.Spec.Names.Plural+"."+crd.Spec.Group)] = gk
+	}
+	return plurals
+}
+
+// swaggerPlurals maps the plural.group of each resource with paths in the swagger doc to its GroupKind,
+// core resources are mapped by their plural alone, e.g. pods.
+func swaggerPlurals(swagger *spec.Swagger) map[string]v1.GroupKind {
+	plurals := map[string]v1.GroupKind{}
+	if swagger.Paths == nil {
+		return plurals
+	}
+	for pathName, item := range swagger.Paths.Paths {
+		resource := pathResource(pathName)
+		if resource == "" || strings.Contains(resource, "/") {
+			// subresources are named after the resource they belong to
+			continue
+		}
+		for _, gk := range groupKindsFromPath(item) {
+			if gk.Kind == "" {
+				continue
+			}
+			plural := resource
+			if gk.Group != "" {
+				plural += "." + gk.Group
+			}
+			plurals[strings.ToLower(plural)] = gk
+		}
+	}
+	return plurals
+}
+
+// selectResources returns the CRDs whose GroupKind matches one of the resource entries.
+// Entries that match no CRD are matched by their alias instead, if they have one.
+func selectResources(crds []*apiextv1.CustomResourceDefinition, resources []string, aliases []resourceAlias) []*apiextv1.CustomResourceDefinition {
+	plurals := crdPlurals(crds)
+	resources = resolvePlurals(resources, plurals)
+	matchesCRD := func(resource string) bool {
+		for _, crd := range crds {
+			if matchResource(resource, v1.GroupKind{Group: crd.Spec.Group, Kind: crd.Spec.Names.Kind}) {
+				return true
+			}
+		}
+		return false
+	}
+	for i, resource := range resources {
+		if matchesCRD(resource) {
+			continue
+		}
+		if aliased, ok := aliasResource(resource, aliases); ok {
+			resources[i] = resolvePlurals([]string{aliased}, plurals)[0]
+		}
+	}
+
+	var selected []*apiextv1.CustomResourceDefinition
+	for _, crd := range crds {
+		gk := v1.GroupKind{Group: crd.Spec.Group, Kind: crd.Spec.Names.Kind}
+		for _, resource := range resources {
+			if matchResource(resource, gk) {
+				selected = append(selected, crd)
+				break
+			}
+		}
+	}
+	return selected
+}
+
+// addResourceGroupKinds adds the GroupKinds served in the swagger doc that match the resource entries
+// to desiredGroupKinds, this is how built-in resources such as Pod or Deployment.apps are documented.
+// Entries that match nothing are matched by their alias instead, if they have one, with a warning naming the alias.
+// Entries that match neither a desired nor a served GroupKind are reported with a *MissingGroupKindsError so typos are
+// not silently left out of the doc.
+// The versions of the GroupKinds that are only matched by entries pinned to a version are returned.
+func addResourceGroupKinds(swagger *spec.Swagger, resources []string, aliases []resourceAlias, desiredGroupKinds map[v1.GroupKind]bool) (map[v1.GroupKind]map[string]bool, error) {
+	available := swaggerGroupKinds(swagger)
+	plurals := swaggerPlurals(swagger)
+	resolved := resolvePlurals(resources, plurals)
+	versions := map[v1.GroupKind]map[string]bool{}
+	// unpinned are the GroupKinds matched by an entry without a version, every version of them is documented
+	unpinned := map[v1.GroupKind]bool{}
+	pin := func(gk v1.GroupKind, version string) {
+		if version == "" {
+			unpinned[gk] = true
+			return
+		}
+		if versions[gk] == nil {
+			versions[gk] = map[string]bool{}
+		}
+		versions[gk][version] = true
+	}
+	match := func(resource string) bool {
+		_, version := splitVersion(resource)
+		matched := false
+		for gk := range desiredGroupKinds {
+			if matchResource(resource, gk) {
+				matched = true
+				pin(gk, version)
+			}
+		}
+		for _, gk := range available {
+			if !matchResource(resource, gk) {
+				continue
+			}
+			matched = true
+			pin(gk, version)
+			if _, ok := desiredGroupKinds[gk]; !ok {
+				desiredGroupKinds[gk] = false
+			}
+		}
+		return matched
+	}
+	var unmatched []string
+	for i, resource := range resolved {
+		if match(resource) {
+			continue
+		}
+		if aliased, ok := aliasResource(resource, aliases); ok && match(resolvePlurals([]string{aliased}, plurals)[0]) {
+			zap.S().Warnf("Resource %s was not found, using its alias %s instead.", resources[i], aliased)
+			continue
+		}
+		description := resources[i]
+		kind, group := splitResource(resource)
+		if suggestions := suggestGroupKinds(v1.GroupKind{Group: group, Kind: kind}, available); len(suggestions) != 0 {
+			description += fmt.Sprintf(" (did you mean %s?)", strings.Join(suggestions, " or "))
+		}
+		unmatched = append(unmatched, description)
+	}
+	for gk := range unpinned {
+		delete(versions, gk)
+	}
+	if len(unmatched) != 0 {
+		return versions, fmt.Errorf("resources match none of the input CRDs or the cluster's resources: %w", &MissingGroupKindsError{GroupKinds: unmatched})
+	}
+	return versions, nil
+}
+
+// filterPinnedVersions removes the paths for the versions of pinned GroupKinds that are not pinned in versions.
+// Pinned versions without any path are reported with a *MissingGroupKindsError.
+func filterPinnedVersions(swagger *spec.Swagger, keepPaths []string, versions map[v1.GroupKind]map[string]bool) ([]string, error) {
+	if len(versions) == 0 {
+		return keepPaths, nil
+	}
+	found := map[schema.GroupVersionKind]bool{}
+	var kept []string
+	for _, pathName := range keepPaths {
+		gvk, ok := pathGroupVersionKind(swagger.Paths.Paths[pathName])
+		if pinned, isPinned := versions[v1.GroupKind{Group: gvk.Group, Kind: gvk.Kind}]; ok && isPinned {
+			if !pinned[gvk.Version] {
+				continue
+			}
+			found[gvk] = true
+		}
+		kept = append(kept, pathName)
+	}
+	var missing []string
+	for gk, pinned := range versions {
+		for version := range pinned {
+			if !found[schema.GroupVersionKind{Group: gk.Group, Version: version, Kind: gk.Kind}] {
+				missing = append(missing, pinVersion(groupKindResource(gk), version))
+			}
+		}
+	}
+	if len(missing) != 0 {
+		sort.Strings(missing)
+		return kept, fmt.Errorf("resources are pinned to versions the cluster does not serve: %w", &MissingGroupKindsError{GroupKinds: missing})
+	}
+	return kept, nil
+}
+
+// pathGroupVersionKind returns the GroupVersionKind of the operations of the path, if they have one.
+func pathGroupVersionKind(item spec.PathItem) (schema.GroupVersionKind, bool) {
+	for _, op := range []*spec.Operation{item.Get, item.Put, item.Post, item.Delete, item.Options, item.Head, item.Patch} {
+		var gvk schema.GroupVersionKind
+		if op != nil && op.Extensions.GetObject(extensionGVK, &gvk) == nil && gvk.Kind != "" {
+			return gvk, true
+		}
+	}
+	return schema.GroupVersionKind{}, false
+}
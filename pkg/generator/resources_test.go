@@ -0,0 +1,167 @@
+package generator
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+func TestValidateResource(t *testing.T) {
+	tests := []struct {
+		resource string
+		wantErr  bool
+	}{
+		{resource: "Cluster.management.cattle.io"},
+		{resource: "*.management.cattle.io"},
+		{resource: "Cluster.*"},
+		{resource: "Cluster.provisioning.cattle.io/v1"},
+		{resource: ".management.cattle.io", wantErr: true},
+		{resource: "Cluster.provisioning.cattle.io/", wantErr: true},
+		{resource: "Cluster.provisioning.cattle.io/v1/v2", wantErr: true},
+		{resource: "[.management.cattle.io", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.resource, func(t *testing.T) {
+			if err := validateResource(tt.resource); (err != nil) != tt.wantErr {
+				t.Errorf("validateResource(%s) error = %v, wantErr %v", tt.resource, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMatchResource(t *testing.T) {
+	cluster := v1.GroupKind{Group: "management.cattle.io", Kind: "Cluster"}
+	tests := []struct {
+		resource string
+		gk       v1.GroupKind
+		want     bool
+	}{
+		{resource: "Cluster.management.cattle.io", gk: cluster, want: true},
+		{resource: "*.management.cattle.io", gk: cluster, want: true},
+		{resource: "Cluster.*", gk: cluster, want: true},
+		{resource: "Cluster.management.cattle.io/v3", gk: cluster, want: true},
+		{resource: "Cluster.provisioning.cattle.io", gk: cluster, want: false},
+		{resource: "Project.management.cattle.io", gk: cluster, want: false},
+		{resource: "Pod", gk: v1.GroupKind{Kind: "Pod"}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.resource, func(t *testing.T) {
+			if got := matchResource(tt.resource, tt.gk); got != tt.want {
+				t.Errorf("matchResource(%s, %s) = %v, want %v", tt.resource, tt.gk, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolvePlurals(t *testing.T) {
+	plurals := map[string]v1.GroupKind{
+		"roletemplates.management.cattle.io": {Group: "management.cattle.io", Kind: "RoleTemplate"},
+		"pods":                               {Kind: "Pod"},
+	}
+	resources := []string{"RoleTemplates.management.cattle.io", "roletemplates.management.cattle.io/v3", "pods", "Cluster.management.cattle.io"}
+	want := []string{"RoleTemplate.management.cattle.io", "RoleTemplate.management.cattle.io/v3", "Pod", "Cluster.management.cattle.io"}
+	if got := resolvePlurals(resources, plurals); !reflect.DeepEqual(got, want) {
+		t.Errorf("resolvePlurals() = %v, want %v", got, want)
+	}
+}
+
+func TestAliasResource(t *testing.T) {
+	aliases := []resourceAlias{
+		{from: "Cluster.old.cattle.io", to: "Cluster.new.cattle.io"},
+		{from: "*.old.cattle.io", to: "*.renamed.cattle.io"},
+	}
+	tests := []struct {
+		resource string
+		want     string
+		wantOK   bool
+	}{
+		{resource: "Cluster.old.cattle.io", want: "Cluster.new.cattle.io", wantOK: true},
+		{resource: "Cluster.old.cattle.io/v1", want: "Cluster.new.cattle.io/v1", wantOK: true},
+		{resource: "Project.old.cattle.io", want: "Project.renamed.cattle.io", wantOK: true},
+		{resource: "*.old.cattle.io", want: "*.renamed.cattle.io", wantOK: true},
+		{resource: "Project.other.cattle.io", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.resource, func(t *testing.T) {
+			got, ok := aliasResource(tt.resource, aliases)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("aliasResource(%s) = %s, %v, want %s, %v", tt.resource, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestValidateAlias(t *testing.T) {
+	tests := []struct {
+		alias   resourceAlias
+		wantErr bool
+	}{
+		{alias: resourceAlias{from: "Cluster.old.cattle.io", to: "Cluster.new.cattle.io"}},
+		{alias: resourceAlias{from: "*.old.cattle.io", to: "*.new.cattle.io"}},
+		{alias: resourceAlias{from: "*.old.cattle.io", to: "Cluster.new.cattle.io"}, wantErr: true},
+		{alias: resourceAlias{from: "Cluster.*", to: "Cluster.new.cattle.io"}, wantErr: true},
+		{alias: resourceAlias{from: "Clu*.old.cattle.io", to: "Cluster.new.cattle.io"}, wantErr: true},
+		{alias: resourceAlias{from: "Cluster.old.cattle.io/v1", to: "Cluster.new.cattle.io/v1"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.alias.from+" "+tt.alias.to, func(t *testing.T) {
+			if err := validateAlias(tt.alias); (err != nil) != tt.wantErr {
+				t.Errorf("validateAlias() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func gvkOperation(gvk schema.GroupVersionKind) *spec.Operation {
+	return &spec.Operation{VendorExtensible: spec.VendorExtensible{Extensions: spec.Extensions{
+		extensionGVK: map[string]interface{}{"group": gvk.Group, "version": gvk.Version, "kind": gvk.Kind},
+	}}}
+}
+
+func TestFilterPinnedVersions(t *testing.T) {
+	foo := v1.GroupKind{Group: "a.io", Kind: "Foo"}
+	swagger := &spec.Swagger{SwaggerProps: spec.SwaggerProps{Paths: &spec.Paths{Paths: map[string]spec.PathItem{
+		"/apis/a.io/v1/foos": {PathItemProps: spec.PathItemProps{Get: gvkOperation(schema.GroupVersionKind{Group: "a.io", Version: "v1", Kind: "Foo"})}},
+		"/apis/a.io/v2/foos": {PathItemProps: spec.PathItemProps{Get: gvkOperation(schema.GroupVersionKind{Group: "a.io", Version: "v2", Kind: "Foo"})}},
+		"/apis/b.io/v1/bars": {PathItemProps: spec.PathItemProps{Get: gvkOperation(schema.GroupVersionKind{Group: "b.io", Version: "v1", Kind: "Bar"})}},
+	}}}}
+	paths := []string{"/apis/a.io/v1/foos", "/apis/a.io/v2/foos", "/apis/b.io/v1/bars"}
+	tests := []struct {
+		name        string
+		versions    map[v1.GroupKind]map[string]bool
+		want        []string
+		wantMissing []string
+	}{
+		{name: "no pins", want: paths},
+		{name: "pinned to v2", versions: map[v1.GroupKind]map[string]bool{foo: {"v2": true}}, want: []string{"/apis/a.io/v2/foos", "/apis/b.io/v1/bars"}},
+		{name: "pinned to both", versions: map[v1.GroupKind]map[string]bool{foo: {"v1": true, "v2": true}}, want: paths},
+		{
+			name:        "pinned to a missing version",
+			versions:    map[v1.GroupKind]map[string]bool{foo: {"v1": true, "v3": true}},
+			want:        []string{"/apis/a.io/v1/foos", "/apis/b.io/v1/bars"},
+			wantMissing: []string{"Foo.a.io/v3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := filterPinnedVersions(swagger, paths, tt.versions)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filterPinnedVersions() = %v, want %v", got, tt.want)
+			}
+			var missingErr *MissingGroupKindsError
+			if tt.wantMissing == nil {
+				if err != nil {
+					t.Errorf("filterPinnedVersions() error = %v", err)
+				}
+				return
+			}
+			if !errors.As(err, &missingErr) || !reflect.DeepEqual(missingErr.GroupKinds, tt.wantMissing) {
+				t.Errorf("filterPinnedVersions() error = %v, want missing %v", err, tt.wantMissing)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// retryInterval is how long generation waits before its first retry, later retries wait twice as long as the previous.
+const retryInterval = time.Second * 10
+
+// generateWithRetries runs generate until it succeeds, fails with an error that is not flaky, or opts.Retries
+// retries have failed. Every attempt tears down its cluster so the next one starts cleanly.
+func generateWithRetries(ctx context.Context, opts Options) (*spec.Swagger, error) {
+	if opts.Progress != nil {
+		// every attempt reports its progress from the start, the progress reported to the caller never decreases
+		progress, percent := opts.Progress, 0
+		opts.Progress = func(p Progress) {
+			if p.Percent < percent {
+				p.Percent = percent
+			}
+			percent = p.Percent
+			progress(p)
+		}
+	}
+	backoff := wait.Backoff{Duration: retryInterval, Factor: 2, Jitter: pollJitter, Steps: math.MaxInt32}
+	for attempt := 1; ; attempt++ {
+		swagger, err := generate(ctx, opts)
+		// a doc generated with missing GroupKinds is not retried
+		if err == nil || swagger != nil || attempt > opts.Retries || ctx.Err() != nil {
+			return swagger, err
+		}
+		reason, flaky := flakyFailure(err)
+		if !flaky {
+			return nil, err
+		}
+		delay := backoff.Step()
+		zap.S().Warnf("Generation failed because %s, retrying in %v (retry %d of %d): %v", reason, delay.Round(time.Second), attempt, opts.Retries, err)
+		if opts.Summary != nil {
+			opts.Summary.Retries = attempt
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("canceled while waiting to retry: %w", err)
+		case <-time.After(delay):
+		}
+		if opts.defaultedName {
+			// a kept container of the failed attempt is left running under its name
+			opts.ContainerName = defaultContainerName(&opts)
+		}
+	}
+}
+
+// flakyFailure returns why generation failed and whether the failure is a known flaky one that a retry is expected
+// to get past, such as the image pull being cut off or a timeout of a cluster that was still starting. Failures
+// caused by the input, the credentials, or a cluster that answered are not flaky.
+func flakyFailure(err error) (reason string, flaky bool) {
+	var stageErr *StageError
+	if !errors.As(err, &stageErr) {
+		return "", false
+	}
+	var netErr net.Error
+	switch stageErr.Stage {
+	case phasePull:
+		// the daemon reports a pull cut off by the registry as a message ending in EOF
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || strings.HasSuffix(err.Error(), "EOF") {
+			return "the image pull was cut off", true
+		}
+	case phaseClusterReady:
+		if errors.Is(err, errEmptyKubeconfig) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return "the kubeconfig was copied from the container while k3s was writing it", true
+		}
+	case phaseDiscovery, phaseOpenAPIFetch:
+		// a discovery poll that timed out without an error waited for GroupKinds the cluster does not serve
+		if hint, persistent := pollHint(err); hint != "" && !persistent {
+			return "the API server did not answer in time", true
+		}
+		if stageErr.Stage == phaseOpenAPIFetch && errors.As(err, &netErr) && netErr.Timeout() {
+			return "the API server did not answer in time", true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,241 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// walkSchemas calls visit for every schema in the swagger definitions, including nested schemas.
+// Schemas are visited bottom up so nested schemas have already been visited when their parent is.
+func walkSchemas(swagger *spec.Swagger, visit func(path string, schema *spec.Schema)) {
+	for _, name := range sortedKeys(swagger.Definitions) {
+		schema := swagger.Definitions[name]
+		walkSchema("definitions/"+name, &schema, visit)
+		swagger.Definitions[name] = schema
+	}
+}
+
+func walkSchema(path string, schema *spec.Schema, visit func(path string, schema *spec.Schema)) {
+	for _, name := range sortedKeys(schema.Properties) {
+		prop := schema.Properties[name]
+		walkSchema(path+"/properties/"+name, &prop, visit)
+		schema.Properties[name] = prop
+	}
+	for _, name := range sortedKeys(schema.PatternProperties) {
+		prop := schema.PatternProperties[name]
+		walkSchema(path+"/patternProperties/"+name, &prop, visit)
+		schema.PatternProperties[name] = prop
+	}
+	if schema.Items != nil {
+		if schema.Items.Schema != nil {
+			walkSchema(path+"/items", schema.Items.Schema, visit)
+		}
+		for i := range schema.Items.Schemas {
+			walkSchema(fmt.Sprintf("%s/items/%d", path, i), &schema.Items.Schemas[i], visit)
+		}
+	}
+	if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+		walkSchema(path+"/additionalProperties", schema.AdditionalProperties.Schema, visit)
+	}
+	if schema.Not != nil {
+		walkSchema(path+"/not", schema.Not, visit)
+	}
+	for i := range schema.AllOf {
+		walkSchema(fmt.Sprintf("%s/allOf/%d", path, i), &schema.AllOf[i], visit)
+	}
+	for i := range schema.AnyOf {
+		walkSchema(fmt.Sprintf("%s/anyOf/%d", path, i), &schema.AnyOf[i], visit)
+	}
+	for i := range schema.OneOf {
+		walkSchema(fmt.Sprintf("%s/oneOf/%d", path, i), &schema.OneOf[i], visit)
+	}
+	visit(path, schema)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// flattenAllOf merges the members of every allOf into their parent object schema. Members that reference a local
+// definition are replaced by a copy of the definition first. A schema is only flattened when all of its members are
+// object schemas without nested compositions, otherwise it is left untouched. The returned list describes merges
+// that dropped information.
+func flattenAllOf(swagger *spec.Swagger) []string {
+	var lossy []string
+	walkSchemas(swagger, func(path string, schema *spec.Schema) {
+		if len(schema.AllOf) == 0 {
+			return
+		}
+		if !isObjectType(schema) {
+			zap.S().Infof("Not flattening allOf at %s: parent schema is not an object", path)
+			return
+		}
+		members := make([]spec.Schema, len(schema.AllOf))
+		var dropped []string
+		for i := range schema.AllOf {
+			member, reason, siblings := resolveAllOfMember(swagger, &schema.AllOf[i])
+			if reason == "" {
+				reason = unsafeAllOfMember(&member)
+			}
+			if reason != "" {
+				zap.S().Infof("Not flattening allOf at %s: member %d %s", path, i, reason)
+				return
+			}
+			if siblings {
+				dropped = append(dropped, fmt.Sprintf("%s/allOf/%d: dropped the fields next to $ref", path, i))
+			}
+			members[i] = member
+		}
+		lossy = append(lossy, dropped...)
+		schema.AllOf = nil
+		for i := range members {
+			lossy = append(lossy, mergeAllOfMember(fmt.Sprintf("%s/allOf/%d", path, i), schema, &members[i])...)
+		}
+	})
+	return lossy
+}
+
+// resolveAllOfMember returns a copy of the definition a member references, or the member itself if it is inline.
+// The reason is set if the reference can not be resolved. Fields next to a $ref are ignored like JSON schema does,
+// siblings reports if the member had any.
+func resolveAllOfMember(swagger *spec.Swagger, member *spec.Schema) (resolved spec.Schema, reason string, siblings bool) {
+	ref := member.Ref.String()
+	if ref == "" {
+		return *member, "", false
+	}
+	name, ok := strings.CutPrefix(ref, definitionRefPrefix)
+	if !ok {
+		return spec.Schema{}, "is not a reference to a local definition", false
+	}
+	def, ok := swagger.Definitions[name]
+	if !ok {
+		return spec.Schema{}, fmt.Sprintf("references the missing definition %s", name), false
+	}
+	if def.Ref.String() != "" {
+		return spec.Schema{}, fmt.Sprintf("references the definition %s which is itself a reference", name), false
+	}
+	// copy the definition so merging into the parent does not share its maps and slices
+	data, err := json.Marshal(def)
+	if err == nil {
+		err = json.Unmarshal(data, &resolved)
+	}
+	if err != nil {
+		return spec.Schema{}, fmt.Sprintf("references the definition %s which can not be copied: %v", name, err), false
+	}
+	rest := *member
+	rest.Ref = spec.Ref{}
+	return resolved, "", !reflect.DeepEqual(rest, spec.Schema{})
+}
+
+// unsafeAllOfMember returns the reason a member can not be merged into its parent or an empty string if it can.
+func unsafeAllOfMember(member *spec.Schema) string {
+	switch {
+	case !isObjectType(member):
+		return "is not an object"
+	case len(member.AllOf) != 0 || len(member.AnyOf) != 0 || len(member.OneOf) != 0 || member.Not != nil:
+		return "contains a nested composition"
+	}
+	return ""
+}
+
+func isObjectType(schema *spec.Schema) bool {
+	return len(schema.Type) == 0 || (len(schema.Type) == 1 && schema.Type.Contains("object"))
+}
+
+// mergedSchemaFields are the fields of spec.SchemaProps and spec.SwaggerSchemaProps that mergeAllOfMember merges
+// field by field, every other field is copied from the member if the parent does not set it.
+var mergedSchemaFields = map[string]bool{"Ref": true, "Type": true, "Description": true, "Properties": true, "Required": true}
+
+// mergeAllOfMember merges member into parent. Properties, required fields, and extensions are merged by name, the
+// other fields are copied if the parent does not set them. Values that conflict with the parent keep the parent's
+// value and are reported as lossy, as is a member description the parent's does not contain.
+func mergeAllOfMember(path string, parent, member *spec.Schema) []string {
+	var lossy []string
+	if len(member.Type) != 0 {
+		parent.Type = member.Type
+	}
+	for _, name := range sortedKeys(member.Properties) {
+		prop := member.Properties[name]
+		existing, ok := parent.Properties[name]
+		if !ok {
+			if parent.Properties == nil {
+				parent.Properties = map[string]spec.Schema{}
+			}
+			parent.Properties[name] = prop
+			continue
+		}
+		if !reflect.DeepEqual(existing, prop) {
+			lossy = append(lossy, fmt.Sprintf("%s: conflicting definitions for property '%s', kept the first", path, name))
+		}
+	}
+	for _, required := range member.Required {
+		if !containsString(parent.Required, required) {
+			parent.Required = append(parent.Required, required)
+		}
+	}
+	for _, props := range [][2]reflect.Value{
+		{reflect.ValueOf(&parent.SchemaProps).Elem(), reflect.ValueOf(&member.SchemaProps).Elem()},
+		{reflect.ValueOf(&parent.SwaggerSchemaProps).Elem(), reflect.ValueOf(&member.SwaggerSchemaProps).Elem()},
+	} {
+		parentProps, memberProps := props[0], props[1]
+		for i := 0; i < memberProps.NumField(); i++ {
+			field := memberProps.Type().Field(i)
+			value := memberProps.Field(i)
+			if mergedSchemaFields[field.Name] || value.IsZero() {
+				continue
+			}
+			parentValue := parentProps.Field(i)
+			if parentValue.IsZero() {
+				parentValue.Set(value)
+			} else if !reflect.DeepEqual(parentValue.Interface(), value.Interface()) {
+				name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+				lossy = append(lossy, fmt.Sprintf("%s: conflicting %s, kept the first", path, name))
+			}
+		}
+	}
+	for _, key := range sortedKeys(member.Extensions) {
+		value := member.Extensions[key]
+		if existing, ok := parent.Extensions[key]; !ok {
+			parent.AddExtension(key, value)
+		} else if !reflect.DeepEqual(existing, value) {
+			lossy = append(lossy, fmt.Sprintf("%s: conflicting extension %s, kept the first", path, key))
+		}
+	}
+	for _, key := range sortedKeys(member.ExtraProps) {
+		value := member.ExtraProps[key]
+		if existing, ok := parent.ExtraProps[key]; !ok {
+			if parent.ExtraProps == nil {
+				parent.ExtraProps = map[string]interface{}{}
+			}
+			parent.ExtraProps[key] = value
+		} else if !reflect.DeepEqual(existing, value) {
+			lossy = append(lossy, fmt.Sprintf("%s: conflicting %s, kept the first", path, key))
+		}
+	}
+	if parent.Description == "" {
+		parent.Description = member.Description
+	} else if member.Description != "" && !strings.Contains(parent.Description, member.Description) {
+		lossy = append(lossy, fmt.Sprintf("%s: dropped member description", path))
+	}
+	return lossy
+}
+
+func containsString(list []string, value string) bool {
+	for i := range list {
+		if list[i] == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package generator
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+func parseDefinitions(t *testing.T, definitions string) *spec.Swagger {
+	t.Helper()
+	swagger := &spec.Swagger{}
+	if err := json.Unmarshal([]byte(`{"definitions":`+definitions+`}`), swagger); err != nil {
+		t.Fatalf("failed to parse definitions: %v", err)
+	}
+	return swagger
+}
+
+func TestFlattenAllOf(t *testing.T) {
+	tests := []struct {
+		name         string
+		definitions  string
+		wantProps    []string
+		wantRequired []string
+		wantAllOf    bool
+		wantLossy    int
+	}{
+		{
+			name: "inline members",
+			definitions: `{"Foo": {"type": "object", "allOf": [
+				{"properties": {"a": {"type": "string"}}, "required": ["a"]},
+				{"properties": {"b": {"type": "string"}}}
+			]}}`,
+			wantProps:    []string{"a", "b"},
+			wantRequired: []string{"a"},
+		},
+		{
+			name: "referenced member",
+			definitions: `{"Base": {"type": "object", "properties": {"a": {"type": "string"}}}, "Foo": {"allOf": [
+				{"$ref": "#/definitions/Base"},
+				{"properties": {"b": {"type": "integer"}}}
+			]}}`,
+			wantProps: []string{"a", "b"},
+		},
+		{
+			name: "conflicting property is lossy",
+			definitions: `{"Foo": {"allOf": [
+				{"properties": {"a": {"type": "string"}}},
+				{"properties": {"a": {"type": "integer"}}}
+			]}}`,
+			wantProps: []string{"a"},
+			wantLossy: 1,
+		},
+		{
+			name: "fields next to a reference are lossy",
+			definitions: `{"Base": {"type": "object", "properties": {"a": {"type": "string"}}}, "Foo": {"allOf": [
+				{"$ref": "#/definitions/Base", "description": "ignored"}
+			]}}`,
+			wantProps: []string{"a"},
+			wantLossy: 1,
+		},
+		{
+			name:        "non object member is kept",
+			definitions: `{"Foo": {"allOf": [{"type": "string"}]}}`,
+			wantAllOf:   true,
+		},
+		{
+			name:        "nested composition is kept",
+			definitions: `{"Foo": {"allOf": [{"oneOf": [{"type": "object"}]}]}}`,
+			wantAllOf:   true,
+		},
+		{
+			name:        "missing reference is kept",
+			definitions: `{"Foo": {"allOf": [{"$ref": "#/definitions/Missing"}]}}`,
+			wantAllOf:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			swagger := parseDefinitions(t, tt.definitions)
+			lossy := flattenAllOf(swagger)
+			if len(lossy) != tt.wantLossy {
+				t.Errorf("flattenAllOf() lossy = %v, want %d entries", lossy, tt.wantLossy)
+			}
+			foo := swagger.Definitions["Foo"]
+			if got := len(foo.AllOf) != 0; got != tt.wantAllOf {
+				t.Errorf("allOf kept = %v, want %v", got, tt.wantAllOf)
+			}
+			if tt.wantAllOf {
+				return
+			}
+			if got := sortedKeys(foo.Properties); !reflect.DeepEqual(got, tt.wantProps) {
+				t.Errorf("properties = %v, want %v", got, tt.wantProps)
+			}
+			if !reflect.DeepEqual(foo.Required, tt.wantRequired) {
+				t.Errorf("required = %v, want %v", foo.Required, tt.wantRequired)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const (
+	// selectableFieldsAnnotation keeps the selectable fields of each version of a CRD read from the input, since
+	// spec.versions[].selectableFields was added to apiextensions.k8s.io/v1 after the CRD types used here.
+	selectableFieldsAnnotation = "crd-swagger.cattle.io/selectable-fields"
+	extensionSelectableFields  = "x-selectable-fields"
+	fieldSelectorParam         = "fieldSelector"
+)
+
+// selectableFieldsCRD is the part of a CRD manifest with the selectable fields of its versions.
+type selectableFieldsCRD struct {
+	Kind string `json:"kind"`
+	Spec struct {
+		Versions []struct {
+			Name             string `json:"name"`
+			SelectableFields []struct {
+				JSONPath string `json:"jsonPath"`
+			} `json:"selectableFields"`
+		} `json:"versions"`
+	} `json:"spec"`
+}
+
+// setSelectableFields records the selectable fields of the CRD manifest in the selectable fields annotation of the
+// CRD decoded from it.
+func setSelectableFields(crd *apiextv1.CustomResourceDefinition, raw *selectableFieldsCRD) error {
+	fields := map[string][]string{}
+	for _, version := range raw.Spec.Versions {
+		for _, field := range version.SelectableFields {
+			fields[version.Name] = append(fields[version.Name], field.JSONPath)
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("failed to marshal selectable fields of '%s': %w", crd.Name, err)
+	}
+	if crd.Annotations == nil {
+		crd.Annotations = map[string]string{}
+	}
+	crd.Annotations[selectableFieldsAnnotation] = string(data)
+	return nil
+}
+
+// annotateSelectableFields sets the x-selectable-fields extension of the definitions of CRD versions with selectable
+// fields to their JSON paths, and documents them in the fieldSelector parameter of the versions' list operations.
+func annotateSelectableFields(swagger *spec.Swagger, crds []*apiextv1.CustomResourceDefinition) {
+	for _, crd := range crds {
+		fields := map[string][]string{}
+		if err := json.Unmarshal([]byte(crd.Annotations[selectableFieldsAnnotation]), &fields); err != nil {
+			continue
+		}
+		for _, version := range sortedKeys(fields) {
+			gvk := schema.GroupVersionKind{Group: crd.Spec.Group, Version: version, Kind: crd.Spec.Names.Kind}
+			if defName := definitionForGVK(swagger, gvk); defName != "" {
+				def := swagger.Definitions[defName]
+				def.AddExtension(extensionSelectableFields, fields[version])
+				swagger.Definitions[defName] = def
+			}
+			documentFieldSelector(swagger, gvk, fields[version])
+		}
+	}
+}
+
+// documentFieldSelector adds the selectable fields to the description of the fieldSelector parameter of the list
+// operations of the GroupVersionKind. Parameters shared through a reference are copied into the operation first.
+func documentFieldSelector(swagger *spec.Swagger, gvk schema.GroupVersionKind, fields []string) {
+	if swagger.Paths == nil {
+		return
+	}
+	// field selectors name the fields without the leading dot of their JSON path
+	sorted := make([]string, 0, len(fields))
+	for _, field := range fields {
+		sorted = append(sorted, strings.TrimPrefix(field, "."))
+	}
+	sort.Strings(sorted)
+	note := fmt.Sprintf("The selectable fields of %s are metadata.name, metadata.namespace, and %s.", gvk.Kind, strings.Join(sorted, ", "))
+	for pathName, item := range swagger.Paths.Paths {
+		op := item.Get
+		if op == nil || operationVerb(op) != "list" {
+			continue
+		}
+		var opGVK schema.GroupVersionKind
+		if err := op.Extensions.GetObject(extensionGVK, &opGVK); err != nil || opGVK != gvk {
+			continue
+		}
+		for i, param := range op.Parameters {
+			if ref := param.Ref.String(); ref != "" {
+				shared, ok := swagger.Parameters[strings.TrimPrefix(ref, "#/parameters/")]
+				if !ok {
+					continue
+				}
+				param = shared
+			}
+			if param.Name != fieldSelectorParam || param.In != "query" {
+				continue
+			}
+			param.Description = strings.TrimSpace(param.Description + " " + note)
+			op.Parameters[i] = param
+		}
+		swagger.Paths.Paths[pathName] = item
+	}
+}
@@ -0,0 +1,49 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/client-go/rest"
+)
+
+// serverCluster is an existing API server connected to directly with the credentials from the options,
+// or through the rest config of the options' ClusterProvider.
+// The CRDs are installed into the API server and left in place afterwards.
+type serverCluster struct {
+	apiClient
+	opts *Options
+}
+
+func (s *serverCluster) start(ctx context.Context) error {
+	var err error
+	if s.opts.ClusterProvider != nil {
+		if s.cfg, err = s.opts.ClusterProvider(ctx); err != nil {
+			return fmt.Errorf("failed to get rest config from cluster provider: %w", err)
+		}
+	} else {
+		s.cfg = serverRESTConfig(s.opts)
+	}
+	s.cs, err = clientset.NewForConfig(s.cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create new clientset: %w", err)
+	}
+	return s.waitForCluster(ctx)
+}
+
+func (s *serverCluster) stop(context.Context) error { return nil }
+
+// serverRESTConfig creates a rest config for Options.Server using the provided credentials.
+func serverRESTConfig(opts *Options) *rest.Config {
+	return &rest.Config{
+		Host:        opts.Server,
+		BearerToken: opts.Token,
+		Username:    opts.Username,
+		Password:    opts.Password,
+		TLSClientConfig: rest.TLSClientConfig{
+			CAFile:   opts.CAFile,
+			Insecure: opts.InsecureSkipTLSVerify,
+		},
+	}
+}
@@ -0,0 +1,73 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// Session keeps a cluster running between generations so docs for changed CRDs are generated without starting a
+// cluster each time, such as for an editor regenerating the doc of the CRD file being edited. Generations are
+// run one at a time.
+type Session struct {
+	opts    Options
+	cluster cluster
+	timer   *phaseTimer
+	lock    sync.Mutex
+}
+
+// NewSession starts the cluster used by the session's generations. The CRDs are passed to Generate so
+// opts.CRDSource, the resources file, and Go packages are not used.
+func NewSession(ctx context.Context, opts Options) (*Session, error) {
+	opts.setDefaults()
+	if opts.OpenAPIURL != "" {
+		return nil, fmt.Errorf("CRDs can not be installed when using an openapi URL")
+	}
+	timer := newPhaseTimer(&opts)
+	cluster, err := startCluster(ctx, &opts, timer)
+	if err != nil {
+		timer.restoreLogger()
+		return nil, err
+	}
+	return &Session{opts: opts, cluster: cluster, timer: timer}, nil
+}
+
+// Generate installs the CRDs in the YAML or JSON documents of data into the session's cluster and returns the
+// cluster's swagger doc filtered to them the same way as Generate. CRDs installed by earlier generations stay
+// in the cluster, a changed CRD replaces the installed one.
+func (s *Session) Generate(ctx context.Context, data []byte) (swagger *spec.Swagger, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	crdMap := map[string]*apiextv1.CustomResourceDefinition{}
+	if err := crdFromReader(bytes.NewReader(data), crdMap); err != nil {
+		return nil, fmt.Errorf("failed to get CRDs: %w", err)
+	}
+	if len(crdMap) == 0 {
+		return nil, fmt.Errorf("no CRDs found in the request")
+	}
+	crds := make([]*apiextv1.CustomResourceDefinition, 0, len(crdMap))
+	for _, name := range sortedKeys(crdMap) {
+		crds = append(crds, crdMap[name])
+	}
+
+	s.timer.restart()
+	defer s.timer.summary()
+	defer func() {
+		err = s.timer.stageError(err)
+	}()
+	return generateFromCluster(ctx, &s.opts, s.cluster, s.timer, crds)
+}
+
+// Close stops the session's cluster.
+func (s *Session) Close(ctx context.Context) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	defer s.timer.restoreLogger()
+	zap.S().Info("Stopping the session's cluster.")
+	return s.cluster.stop(ctx)
+}
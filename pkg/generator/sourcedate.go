@@ -0,0 +1,26 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// sourceDateEpochEnv is the reproducible builds variable holding the time to use for timestamps in outputs,
+// see https://reproducible-builds.org/specs/source-date-epoch/.
+const sourceDateEpochEnv = "SOURCE_DATE_EPOCH"
+
+// BuildTime returns the time to embed in outputs such as bundles and archives: SOURCE_DATE_EPOCH when it is set,
+// so outputs are reproducible, and the current time otherwise. The time is in UTC truncated to the second.
+func BuildTime() (time.Time, error) {
+	epoch := os.Getenv(sourceDateEpochEnv)
+	if epoch == "" {
+		return time.Now().UTC().Truncate(time.Second), nil
+	}
+	seconds, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s '%s' must be a number of seconds: %w", sourceDateEpochEnv, epoch, err)
+	}
+	return time.Unix(seconds, 0).UTC(), nil
+}
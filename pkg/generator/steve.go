@@ -0,0 +1,155 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const (
+	stevePathPrefix = "/v1/"
+	steveTag        = "steve"
+)
+
+// addStevePaths adds paths for Rancher's Steve API (/v1/{type}) to the swagger doc for each CRD.
+// Steve operations reference the definition of the CRD's storage version and only include the
+// operations for verbs kept by verbs and excludeVerbs.
+func addStevePaths(swagger *spec.Swagger, crds []*apiextv1.CustomResourceDefinition, verbs, excludeVerbs []string) {
+	if swagger.Paths == nil {
+		swagger.Paths = &spec.Paths{}
+	}
+	if swagger.Paths.Paths == nil {
+		swagger.Paths.Paths = map[string]spec.PathItem{}
+	}
+	keepVerb := func(verb string) bool {
+		return (len(verbs) == 0 || containsString(verbs, verb)) && !containsString(excludeVerbs, verb)
+	}
+	for _, crd := range crds {
+		gvk := schema.GroupVersionKind{Group: crd.Spec.Group, Version: storageVersion(crd), Kind: crd.Spec.Names.Kind}
+		defName := definitionForGVK(swagger, gvk)
+		if defName == "" {
+			zap.S().Warnf("Not adding Steve paths for %s, no definition was found for it.", gvk.String())
+			continue
+		}
+		steveType := strings.ToLower(crd.Spec.Group + "." + crd.Spec.Names.Plural)
+		newOp := func(name, verb, description string) *spec.Operation {
+			op := &spec.Operation{OperationProps: spec.OperationProps{
+				ID:          fmt.Sprintf("steve-%s-%s", name, steveType),
+				Description: fmt.Sprintf("%s using the Steve API", description),
+				Tags:        []string{steveTag},
+				Produces:    []string{"application/json"},
+				Responses:   &spec.Responses{ResponsesProps: spec.ResponsesProps{StatusCodeResponses: map[int]spec.Response{}}},
+			}}
+			op.AddExtension(extensionAction, verb)
+			op.AddExtension(extensionGVK, map[string]string{"group": gvk.Group, "version": gvk.Version, "kind": gvk.Kind})
+			return op
+		}
+		ref := spec.MustCreateRef("#/definitions/" + defName)
+		object := spec.Schema{SchemaProps: spec.SchemaProps{Ref: ref}}
+		body := spec.Parameter{ParamProps: spec.ParamProps{Name: "body", In: "body", Required: true, Schema: &object}}
+		collection := steveCollection(object)
+
+		basePath := stevePathPrefix + steveType
+		itemPath := basePath + "/{name}"
+		base := spec.PathItem{}
+		item := spec.PathItem{PathItemProps: spec.PathItemProps{Parameters: []spec.Parameter{stevePathParam("name", "name of the "+gvk.Kind)}}}
+		if keepVerb("list") {
+			base.Get = newOp("list", "list", "list objects of kind "+gvk.Kind)
+			base.Get.Responses.StatusCodeResponses[200] = steveResponse("OK", &collection)
+		}
+		if keepVerb("create") {
+			base.Post = newOp("create", "create", "create a "+gvk.Kind)
+			base.Post.Consumes = []string{"application/json"}
+			base.Post.Parameters = []spec.Parameter{body}
+			base.Post.Responses.StatusCodeResponses[201] = steveResponse("Created", &object)
+		}
+		if crd.Spec.Scope == apiextv1.NamespaceScoped {
+			namespaced := spec.PathItem{PathItemProps: spec.PathItemProps{Parameters: []spec.Parameter{stevePathParam("namespace", "object name and auth scope, such as for teams and projects")}}}
+			if keepVerb("list") {
+				namespaced.Get = newOp("list-namespaced", "list", "list objects of kind "+gvk.Kind+" in a namespace")
+				namespaced.Get.Responses.StatusCodeResponses[200] = steveResponse("OK", &collection)
+				swagger.Paths.Paths[basePath+"/{namespace}"] = namespaced
+			}
+			itemPath = basePath + "/{namespace}/{name}"
+			item.Parameters = append(namespaced.Parameters, item.Parameters...)
+		}
+		if keepVerb("get") {
+			item.Get = newOp("get", "get", "read the specified "+gvk.Kind)
+			item.Get.Responses.StatusCodeResponses[200] = steveResponse("OK", &object)
+		}
+		if keepVerb("update") {
+			item.Put = newOp("update", "update", "replace the specified "+gvk.Kind)
+			item.Put.Consumes = []string{"application/json"}
+			item.Put.Parameters = []spec.Parameter{body}
+			item.Put.Responses.StatusCodeResponses[200] = steveResponse("OK", &object)
+		}
+		if keepVerb("delete") {
+			item.Delete = newOp("delete", "delete", "delete the specified "+gvk.Kind)
+			item.Delete.Responses.StatusCodeResponses[200] = steveResponse("OK", &object)
+		}
+		if base.Get != nil || base.Post != nil {
+			swagger.Paths.Paths[basePath] = base
+		}
+		if item.Get != nil || item.Put != nil || item.Delete != nil {
+			swagger.Paths.Paths[itemPath] = item
+		}
+	}
+}
+
+// steveCollection returns the schema of a Steve list response containing objects of the item schema.
+func steveCollection(item spec.Schema) spec.Schema {
+	return spec.Schema{SchemaProps: spec.SchemaProps{
+		Type: spec.StringOrArray{"object"},
+		Properties: map[string]spec.Schema{
+			"type":         *spec.StringProperty(),
+			"resourceType": *spec.StringProperty(),
+			"revision":     *spec.StringProperty(),
+			"data":         *spec.ArrayProperty(&item),
+		},
+	}}
+}
+
+// steveResponse returns a response with the description and schema.
+func steveResponse(description string, schema *spec.Schema) spec.Response {
+	return spec.Response{ResponseProps: spec.ResponseProps{Description: description, Schema: schema}}
+}
+
+func stevePathParam(name, description string) spec.Parameter {
+	return spec.Parameter{
+		ParamProps:   spec.ParamProps{Name: name, In: "path", Required: true, Description: description},
+		SimpleSchema: spec.SimpleSchema{Type: "string"},
+	}
+}
+
+// storageVersion returns the name of the CRD's storage version, or its first version if none is marked for storage.
+func storageVersion(crd *apiextv1.CustomResourceDefinition) string {
+	for _, version := range crd.Spec.Versions {
+		if version.Storage {
+			return version.Name
+		}
+	}
+	if len(crd.Spec.Versions) == 0 {
+		return ""
+	}
+	return crd.Spec.Versions[0].Name
+}
+
+// definitionForGVK returns the name of the definition for gvk or an empty string if there is none.
+func definitionForGVK(swagger *spec.Swagger, gvk schema.GroupVersionKind) string {
+	for _, name := range sortedKeys(swagger.Definitions) {
+		var gvks []schema.GroupVersionKind
+		if err := swagger.Definitions[name].Extensions.GetObject(extensionGVK, &gvks); err != nil {
+			continue
+		}
+		for _, defGVK := range gvks {
+			if defGVK == gvk {
+				return name
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,98 @@
+package generator
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// stripDocs shrinks the swagger doc by removing every description when descriptions is set, and the vendor
+// extensions whose names match any of the extension patterns, e.g. x-kubernetes-* or x-kubernetes-action.
+// Response descriptions are kept since Swagger 2.0 requires them.
+func stripDocs(swagger *spec.Swagger, descriptions bool, extensions []string) error {
+	for _, pattern := range extensions {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid extension pattern '%s': %w", pattern, err)
+		}
+	}
+	strip := func(description *string, ext spec.Extensions) {
+		if descriptions {
+			*description = ""
+		}
+		for name := range ext {
+			for _, pattern := range extensions {
+				if matched, _ := path.Match(strings.ToLower(pattern), name); matched {
+					delete(ext, name)
+					break
+				}
+			}
+		}
+	}
+	var unused string
+	stripSchema := func(_ string, schema *spec.Schema) {
+		strip(&schema.Description, schema.Extensions)
+	}
+	stripParameter := func(param *spec.Parameter) {
+		strip(&param.Description, param.Extensions)
+		if param.Schema != nil {
+			walkSchema("", param.Schema, stripSchema)
+		}
+	}
+	stripResponse := func(response *spec.Response) {
+		description := response.Description
+		strip(&description, response.Extensions)
+		if response.Schema != nil {
+			walkSchema("", response.Schema, stripSchema)
+		}
+	}
+
+	strip(&unused, swagger.Extensions)
+	if swagger.Info != nil {
+		strip(&swagger.Info.Description, swagger.Info.Extensions)
+	}
+	for i := range swagger.Tags {
+		strip(&swagger.Tags[i].Description, swagger.Tags[i].Extensions)
+	}
+	walkSchemas(swagger, stripSchema)
+	for name, param := range swagger.Parameters {
+		stripParameter(&param)
+		swagger.Parameters[name] = param
+	}
+	for name, response := range swagger.Responses {
+		stripResponse(&response)
+		swagger.Responses[name] = response
+	}
+	if swagger.Paths == nil {
+		return nil
+	}
+	strip(&unused, swagger.Paths.Extensions)
+	for pathName, item := range swagger.Paths.Paths {
+		strip(&unused, item.Extensions)
+		// stripping is done in place, parameters and operations shared between paths are stripped more than once
+		for i := range item.Parameters {
+			stripParameter(&item.Parameters[i])
+		}
+		for _, op := range pathOperations(&item) {
+			if *op == nil {
+				continue
+			}
+			strip(&(*op).Description, (*op).Extensions)
+			for i := range (*op).Parameters {
+				stripParameter(&(*op).Parameters[i])
+			}
+			if responses := (*op).Responses; responses != nil {
+				if responses.Default != nil {
+					stripResponse(responses.Default)
+				}
+				for code, response := range responses.StatusCodeResponses {
+					stripResponse(&response)
+					responses.StatusCodeResponses[code] = response
+				}
+			}
+		}
+		swagger.Paths.Paths[pathName] = item
+	}
+	return nil
+}
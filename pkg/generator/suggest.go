@@ -0,0 +1,94 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// maxSuggestions is the most close matches suggested for a missing GroupKind.
+const maxSuggestions = 3
+
+// missingGroupKinds describes each missing GroupKind along with the served GroupKinds of the swagger doc
+// that closely match it, e.g. "RoleTemplates.management.cattle.io (did you mean RoleTemplate.management.cattle.io?)".
+func missingGroupKinds(missing []v1.GroupKind, swagger *spec.Swagger) []string {
+	var available []v1.GroupKind
+	if swagger != nil {
+		available = swaggerGroupKinds(swagger)
+	}
+	descriptions := make([]string, 0, len(missing))
+	for _, gk := range missing {
+		description := gk.String()
+		if suggestions := suggestGroupKinds(gk, available); len(suggestions) != 0 {
+			description += fmt.Sprintf(" (did you mean %s?)", strings.Join(suggestions, " or "))
+		}
+		descriptions = append(descriptions, description)
+	}
+	sort.Strings(descriptions)
+	return descriptions
+}
+
+// suggestGroupKinds returns the available GroupKinds closest to gk, a GroupKind is close when its kind matches
+// ignoring case or when few edits turn one Kind.group into the other.
+func suggestGroupKinds(gk v1.GroupKind, available []v1.GroupKind) []string {
+	type match struct {
+		name     string
+		distance int
+	}
+	target := strings.ToLower(gk.String())
+	// allow roughly one typo for every five characters
+	maxDistance := len(target)/5 + 1
+	var matches []match
+	for _, candidate := range available {
+		if candidate == gk {
+			continue
+		}
+		distance := editDistance(target, strings.ToLower(candidate.String()))
+		if strings.EqualFold(candidate.Kind, gk.Kind) && distance > maxDistance {
+			// same kind in another group
+			distance = maxDistance
+		}
+		if distance <= maxDistance {
+			matches = append(matches, match{name: candidate.String(), distance: distance})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].distance < matches[j].distance })
+	if len(matches) > maxSuggestions {
+		matches = matches[:maxSuggestions]
+	}
+	suggestions := make([]string, 0, len(matches))
+	for _, m := range matches {
+		suggestions = append(suggestions, m.name)
+	}
+	return suggestions
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = prev[j-1] + cost
+			if prev[j]+1 < curr[j] {
+				curr[j] = prev[j] + 1
+			}
+			if curr[j-1]+1 < curr[j] {
+				curr[j] = curr[j-1] + 1
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,73 @@
+package generator
+
+import (
+	"sort"
+	"time"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// Summary is a machine readable description of a Generate run, filled in when set as Options.Summary.
+// GroupKinds are written as Kind.group.
+type Summary struct {
+	// Image is the k3s image of the docker cluster, empty for other clusters.
+	Image string `json:"image,omitempty"`
+	// Requested are the GroupKinds of the input CRDs and resources file.
+	Requested []string `json:"requested"`
+	// Found are the requested GroupKinds the cluster serves.
+	Found []string `json:"found"`
+	// Missing are the requested GroupKinds the cluster does not serve.
+	Missing []string `json:"missing"`
+	// Paths and Definitions are the number of paths and definitions in the generated doc.
+	Paths       int `json:"paths"`
+	Definitions int `json:"definitions"`
+	// DurationSeconds is how long generation took.
+	DurationSeconds float64 `json:"durationSeconds"`
+	// Retries is how many times generation was retried after a flaky failure.
+	Retries int `json:"retries"`
+}
+
+// recordGroupKinds sets the requested, found, and missing GroupKinds of the summary.
+func (s *Summary) recordGroupKinds(groupKinds map[v1.GroupKind]bool) {
+	s.Requested, s.Found, s.Missing = []string{}, []string{}, []string{}
+	for gk, found := range groupKinds {
+		s.Requested = append(s.Requested, gk.String())
+		if found {
+			s.Found = append(s.Found, gk.String())
+		} else {
+			s.Missing = append(s.Missing, gk.String())
+		}
+	}
+	sort.Strings(s.Requested)
+	sort.Strings(s.Found)
+	sort.Strings(s.Missing)
+}
+
+// recordRequested sets the requested GroupKinds of the summary before it is known which of them are found.
+func (s *Summary) recordRequested(groupKinds map[v1.GroupKind]bool) {
+	s.Requested = []string{}
+	for gk := range groupKinds {
+		s.Requested = append(s.Requested, gk.String())
+	}
+	sort.Strings(s.Requested)
+}
+
+// finish records the image, the size of the generated doc, and the duration of the run.
+func (s *Summary) finish(opts *Options, swagger *spec.Swagger, start time.Time) {
+	if opts.OpenAPIURL == "" && opts.Server == "" && opts.ClusterProvider == nil && opts.Engine == EngineDocker {
+		s.Image = opts.Image
+	}
+	if swagger != nil {
+		if swagger.Paths != nil {
+			s.Paths = len(swagger.Paths.Paths)
+		}
+		s.Definitions = len(swagger.Definitions)
+	}
+	s.DurationSeconds = time.Since(start).Round(time.Millisecond).Seconds()
+	for _, list := range []*[]string{&s.Requested, &s.Found, &s.Missing} {
+		if *list == nil {
+			*list = []string{}
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	phasePull           = "pull"
+	phaseContainerStart = "container start"
+	phaseClusterReady   = "cluster ready"
+	phaseCRDInstall     = "CRD install"
+	phaseManifests      = "manifests"
+	phaseDiscovery      = "discovery"
+	phaseOpenAPIFetch   = "openapi fetch"
+	phaseFilter         = "filter"
+	phasePostProcess    = "post-process"
+)
+
+// phasePercents are the estimated percentages of generation completed once each phase is done.
+var phasePercents = map[string]int{
+	phasePull:           15,
+	phaseContainerStart: 25,
+	phaseClusterReady:   45,
+	phaseCRDInstall:     60,
+	phaseManifests:      70,
+	phaseDiscovery:      80,
+	phaseOpenAPIFetch:   80,
+	phaseFilter:         90,
+	phasePostProcess:    95,
+}
+
+// infrastructurePhases are the phases that fail because of the cluster rather than the input.
+var infrastructurePhases = map[string]bool{
+	phasePull: true, phaseContainerStart: true, phaseClusterReady: true, phaseCRDInstall: true,
+	phaseManifests: true, phaseDiscovery: true, phaseOpenAPIFetch: true,
+}
+
+// Progress reports the progress of generation to Options.Progress.
+type Progress struct {
+	// Stage is the phase of generation the progress is about, e.g. "CRD install", or "done" once the doc is generated.
+	Stage string
+	// Percent is the estimated percentage of generation completed, it never decreases.
+	Percent int
+	// Message describes the progress, e.g. "started".
+	Message string
+}
+
+// StageError is returned by Generate when a phase of generation fails. Its message is the message of the phase's
+// error so callers that only log errors see no difference.
+type StageError struct {
+	// Stage is the phase that failed, e.g. "CRD install".
+	Stage string
+	Err   error
+}
+
+func (e *StageError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+// Infrastructure reports if the phase that failed depends on the cluster, such as starting it or installing the CRDs,
+// rather than only on the input.
+func (e *StageError) Infrastructure() bool {
+	return infrastructurePhases[e.Stage]
+}
+
+// phaseTiming is how long a single phase of generation took.
+type phaseTiming struct {
+	Phase    string
+	Duration time.Duration
+}
+
+// phaseTimer records how long each phase of generation takes and warns about phases slower than the threshold.
+type phaseTimer struct {
+	threshold time.Duration
+	last      time.Time
+	timings   []phaseTiming
+	// progress is called when a phase starts and is done, if set
+	progress func(Progress)
+	percent  int
+	// current is the phase started but not done yet
+	current string
+	// logger is the global logger when the timer was created, the global logger is replaced by logger with
+	// logFields and the current phase added when withLogFields is set
+	logger        *zap.Logger
+	logFields     []zap.Field
+	withLogFields bool
+}
+
+func newPhaseTimer(opts *Options) *phaseTimer {
+	return &phaseTimer{
+		threshold:     opts.SlowThreshold,
+		last:          time.Now(),
+		progress:      opts.Progress,
+		logger:        zap.L(),
+		withLogFields: opts.LogFields,
+	}
+}
+
+// start marks phase as the one in progress, errors returned by Generate until it is done are reported as
+// a StageError of the phase.
+func (p *phaseTimer) start(phase string) {
+	p.current = phase
+	p.updateLogger()
+	p.report(phase, "started")
+}
+
+// addLogFields adds fields to every log entry until the logger is restored, if log fields are enabled.
+func (p *phaseTimer) addLogFields(fields ...zap.Field) {
+	p.logFields = append(p.logFields, fields...)
+	p.updateLogger()
+}
+
+// updateLogger replaces the global logger with one that adds the log fields and the current phase to every entry.
+func (p *phaseTimer) updateLogger() {
+	if !p.withLogFields {
+		return
+	}
+	fields := append([]zap.Field{}, p.logFields...)
+	if p.current != "" {
+		fields = append(fields, zap.String("phase", p.current))
+	}
+	zap.ReplaceGlobals(p.logger.With(fields...))
+}
+
+// restoreLogger restores the global logger the timer was created with.
+func (p *phaseTimer) restoreLogger() {
+	if p.withLogFields {
+		zap.ReplaceGlobals(p.logger)
+	}
+}
+
+// stageError wraps err in a StageError of the phase in progress, if there is one.
+func (p *phaseTimer) stageError(err error) error {
+	if err == nil || p.current == "" {
+		return err
+	}
+	return &StageError{Stage: p.current, Err: err}
+}
+
+func (p *phaseTimer) report(stage, message string) {
+	if p.progress != nil {
+		p.progress(Progress{Stage: stage, Percent: p.percent, Message: message})
+	}
+}
+
+// reset starts timing the next phase from now without recording the time since the previous phase.
+func (p *phaseTimer) reset() {
+	p.last = time.Now()
+}
+
+// restart clears the recorded timings and progress so the next generation is timed on its own, such as each
+// regeneration of Watch.
+func (p *phaseTimer) restart() {
+	p.timings = nil
+	p.percent = 0
+	p.current = ""
+	p.updateLogger()
+	p.reset()
+}
+
+// done records the time since the previous phase ended as the duration of phase.
+func (p *phaseTimer) done(phase string) {
+	now := time.Now()
+	timing := phaseTiming{Phase: phase, Duration: now.Sub(p.last)}
+	p.last = now
+	p.timings = append(p.timings, timing)
+	logPhaseTiming(timing, p.threshold)
+	if p.current == phase {
+		p.current = ""
+		p.updateLogger()
+	}
+	if percent := phasePercents[phase]; percent > p.percent {
+		p.percent = percent
+	}
+	p.report(phase, fmt.Sprintf("done in %v", timing.Duration.Round(time.Millisecond)))
+}
+
+// finish reports that the doc was generated.
+func (p *phaseTimer) finish() {
+	p.percent = 100
+	p.report("done", "generated the swagger doc")
+}
+
+// summary logs the duration of every recorded phase.
+func (p *phaseTimer) summary() {
+	parts := make([]string, 0, len(p.timings))
+	for _, timing := range p.timings {
+		parts = append(parts, timing.Phase+"="+timing.Duration.Round(time.Millisecond).String())
+	}
+	zap.S().Infof("Generation timings: %s", strings.Join(parts, ", "))
+}
+
+// logPhaseTiming logs the duration of a phase as a warning if it took longer than threshold.
+// A threshold of zero disables the warning.
+func logPhaseTiming(timing phaseTiming, threshold time.Duration) {
+	if threshold > 0 && timing.Duration > threshold {
+		zap.S().Warnf("Phase '%s' took %v which is longer than the slow threshold of %v.", timing.Phase, timing.Duration.Round(time.Millisecond), threshold)
+		return
+	}
+	zap.S().Debugf("Phase '%s' took %v.", timing.Phase, timing.Duration.Round(time.Millisecond))
+}
+
+// LogPhaseTiming logs how long a phase performed outside of Generate took, such as writing the output,
+// using the same format and threshold warnings as the phases of Generate.
+func LogPhaseTiming(phase string, start time.Time, threshold time.Duration) {
+	logPhaseTiming(phaseTiming{Phase: phase, Duration: time.Since(start)}, threshold)
+}
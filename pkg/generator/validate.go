@@ -0,0 +1,203 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const (
+	// ValidateInvalid flags parts of the swagger doc that break the Swagger 2.0 specification.
+	ValidateInvalid = "invalid"
+	// ValidateUnresolvedRef flags $refs that do not point to anything in the swagger doc.
+	ValidateUnresolvedRef = "unresolved-ref"
+)
+
+var (
+	parameterIns     = []string{"query", "header", "path", "formData", "body"}
+	parameterTypes   = []string{"string", "number", "integer", "boolean", "array", "file"}
+	localRefPrefixes = []string{"#/definitions/", "#/parameters/", "#/responses/"}
+)
+
+// ValidateSwagger checks the swagger doc against the rules of the Swagger 2.0 specification
+// and verifies that every $ref resolves to a definition, parameter, or response of the doc.
+func ValidateSwagger(swagger *spec.Swagger) ([]Finding, error) {
+	var findings []Finding
+	invalid := func(path, format string, args ...interface{}) {
+		findings = append(findings, Finding{Rule: ValidateInvalid, Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if swagger.Swagger != "2.0" {
+		invalid("swagger", "swagger version is '%s' instead of 2.0", swagger.Swagger)
+	}
+	if swagger.Info == nil || swagger.Info.Title == "" || swagger.Info.Version == "" {
+		invalid("info", "info title and version are required")
+	}
+	if swagger.Paths == nil {
+		invalid("paths", "paths are required")
+	} else {
+		operationIDs := map[string]string{}
+		for _, pathName := range sortedKeys(swagger.Paths.Paths) {
+			validatePath(swagger, pathName, operationIDs, invalid)
+		}
+	}
+
+	refFindings, err := unresolvedRefs(swagger)
+	if err != nil {
+		return nil, err
+	}
+	return append(findings, refFindings...), nil
+}
+
+func validatePath(swagger *spec.Swagger, pathName string, operationIDs map[string]string, invalid func(path, format string, args ...interface{})) {
+	pathItem := swagger.Paths.Paths[pathName]
+	itemPath := "paths" + pathName
+	if !strings.HasPrefix(pathName, "/") {
+		itemPath = "paths/" + pathName
+		invalid(itemPath, "path must begin with a slash")
+	}
+	templateParams := map[string]bool{}
+	for _, match := range pathParamRegex.FindAllStringSubmatch(pathName, -1) {
+		templateParams[match[1]] = true
+	}
+
+	pathParams := validateParameters(swagger, itemPath+"/parameters", pathItem.Parameters, invalid)
+	ops := pathOperations(&pathItem)
+	for _, verb := range sortedKeys(ops) {
+		op := *ops[verb]
+		if op == nil {
+			continue
+		}
+		opPath := itemPath + "/" + strings.ToLower(verb)
+		if op.ID != "" {
+			if other, ok := operationIDs[op.ID]; ok {
+				invalid(opPath, "operationId '%s' is also used by %s", op.ID, other)
+			} else {
+				operationIDs[op.ID] = opPath
+			}
+		}
+		if op.Responses == nil || (op.Responses.Default == nil && len(op.Responses.StatusCodeResponses) == 0) {
+			invalid(opPath+"/responses", "at least one response is required")
+		} else {
+			for code := range op.Responses.StatusCodeResponses {
+				if code < 100 || code > 599 {
+					invalid(opPath+"/responses/"+strconv.Itoa(code), "response code must be between 100 and 599")
+				}
+			}
+		}
+
+		declared := map[string]bool{}
+		for name := range pathParams {
+			declared[name] = true
+		}
+		for name := range validateParameters(swagger, opPath+"/parameters", op.Parameters, invalid) {
+			declared[name] = true
+		}
+		for _, name := range sortedKeys(templateParams) {
+			if !declared[name] {
+				invalid(opPath, "path parameter '%s' is not declared", name)
+			}
+		}
+		for _, name := range sortedKeys(declared) {
+			if !templateParams[name] {
+				invalid(opPath, "path parameter '%s' is not in the path", name)
+			}
+		}
+	}
+}
+
+// validateParameters checks each parameter and returns the names of the path parameters.
+// Referenced parameters are resolved from the parameters of the swagger doc.
+func validateParameters(swagger *spec.Swagger, path string, params []spec.Parameter, invalid func(path, format string, args ...interface{})) map[string]bool {
+	pathParams := map[string]bool{}
+	for i := range params {
+		param := params[i]
+		paramPath := fmt.Sprintf("%s/%d", path, i)
+		if ref := param.Ref.String(); ref != "" {
+			resolved, ok := swagger.Parameters[strings.TrimPrefix(ref, "#/parameters/")]
+			if !ok {
+				// reported as an unresolved ref
+				continue
+			}
+			param = resolved
+		}
+		if param.Name == "" {
+			invalid(paramPath, "parameter name is required")
+		}
+		if !containsString(parameterIns, param.In) {
+			invalid(paramPath, "parameter in '%s' must be one of [%s]", param.In, strings.Join(parameterIns, ", "))
+		}
+		if param.In == "body" {
+			if param.Schema == nil {
+				invalid(paramPath, "body parameter requires a schema")
+			}
+			continue
+		}
+		if !containsString(parameterTypes, param.Type) {
+			invalid(paramPath, "parameter type '%s' must be one of [%s]", param.Type, strings.Join(parameterTypes, ", "))
+		}
+		if param.Type == "array" && param.Items == nil {
+			invalid(paramPath, "array parameter requires items")
+		}
+		if param.In == "path" {
+			if !param.Required {
+				invalid(paramPath, "path parameter '%s' must be required", param.Name)
+			}
+			pathParams[param.Name] = true
+		}
+	}
+	return pathParams
+}
+
+// unresolvedRefs finds every $ref in the swagger doc that does not point to an existing definition, parameter, or response.
+func unresolvedRefs(swagger *spec.Swagger) ([]Finding, error) {
+	data, err := json.Marshal(swagger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal swagger doc: %w", err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal swagger doc: %w", err)
+	}
+
+	var findings []Finding
+	var walk func(path string, value interface{})
+	walk = func(path string, value interface{}) {
+		switch value := value.(type) {
+		case map[string]interface{}:
+			if ref, ok := value["$ref"].(string); ok && !refResolves(doc, ref) {
+				findings = append(findings, Finding{Rule: ValidateUnresolvedRef, Path: path, Message: fmt.Sprintf("$ref '%s' does not resolve", ref)})
+			}
+			for _, key := range sortedKeys(value) {
+				// path names already begin with a slash
+				walk(path+"/"+strings.TrimPrefix(key, "/"), value[key])
+			}
+		case []interface{}:
+			for i := range value {
+				walk(fmt.Sprintf("%s/%d", path, i), value[i])
+			}
+		}
+	}
+	for _, key := range sortedKeys(doc) {
+		walk(key, doc[key])
+	}
+	return findings, nil
+}
+
+// refResolves reports whether ref points to a definition, parameter, or response of the doc.
+// External refs are never generated so they do not resolve.
+func refResolves(doc map[string]interface{}, ref string) bool {
+	for _, prefix := range localRefPrefixes {
+		if !strings.HasPrefix(ref, prefix) {
+			continue
+		}
+		section, _ := doc[strings.TrimSuffix(strings.TrimPrefix(prefix, "#/"), "/")].(map[string]interface{})
+		name := strings.NewReplacer("~1", "/", "~0", "~").Replace(strings.TrimPrefix(ref, prefix))
+		_, ok := section[name]
+		return ok
+	}
+	return false
+}
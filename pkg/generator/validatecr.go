@@ -0,0 +1,201 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rancher/wrangler/v2/pkg/yaml"
+	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	openapierrors "k8s.io/kube-openapi/pkg/validation/errors"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+)
+
+// CRError is a problem found in a custom resource by ValidateCRs.
+type CRError struct {
+	// File is the file the resource was read from.
+	File string
+	// Object is the resource as Kind/name.
+	Object string
+	// Path is the JSON path of the invalid field, e.g. $.spec.replicas.
+	Path string
+	// Message describes the problem.
+	Message string
+}
+
+func (e CRError) String() string {
+	return fmt.Sprintf("%s: %s: %s: %s", e.File, e.Object, e.Path, e.Message)
+}
+
+// ServerSwagger returns the full swagger doc served by Options.Server using the credentials of the options.
+func ServerSwagger(opts Options) (*spec.Swagger, error) {
+	if opts.Server == "" {
+		return nil, fmt.Errorf("no API server set")
+	}
+	cfg := serverRESTConfig(&opts)
+	cs, err := clientset.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new clientset: %w", err)
+	}
+	client := apiClient{cfg: cfg, cs: cs}
+	return client.getSwagger(context.Background())
+}
+
+// ValidateCRs validates every resource in the YAML and JSON files at path, a file or a directory, against the schema
+// of its kind's definition in the swagger doc. Besides the schema's own rules, fields the schema does not declare are
+// reported since the API server drops them. The number of resources checked is returned with the errors found.
+func ValidateCRs(swagger *spec.Swagger, path string, recurse bool) ([]CRError, int, error) {
+	files, err := resourceFiles(path, recurse)
+	if err != nil {
+		return nil, 0, err
+	}
+	var crErrors []CRError
+	checked := 0
+	for _, file := range files {
+		data, err := os.Open(file)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to open file '%s': %w", file, err)
+		}
+		objs, err := yaml.UnmarshalWithJSONDecoder[*map[string]interface{}](data)
+		data.Close()
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to convert file '%s': %w", file, err)
+		}
+		for _, obj := range objs {
+			if obj == nil || *obj == nil {
+				// empty documents
+				continue
+			}
+			checked++
+			for _, crErr := range validateCR(swagger, *obj) {
+				crErr.File = file
+				crErrors = append(crErrors, crErr)
+			}
+		}
+	}
+	return crErrors, checked, nil
+}
+
+// resourceFiles returns the YAML and JSON files at path in lexical order.
+func resourceFiles(path string, recurse bool) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file '%s': %w", path, err)
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	var files []string
+	err = filepath.WalkDir(path, func(file string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if file != path && !recurse {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(file)) {
+		case ".yaml", ".yml", ".json":
+			files = append(files, file)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dir '%s': %w", path, err)
+	}
+	return files, nil
+}
+
+// validateCR validates a single resource against the definition of its GroupVersionKind.
+func validateCR(swagger *spec.Swagger, obj map[string]interface{}) []CRError {
+	apiVersion, _ := obj["apiVersion"].(string)
+	kind, _ := obj["kind"].(string)
+	object := kind + "/"
+	if metadata, ok := obj["metadata"].(map[string]interface{}); ok {
+		name, _ := metadata["name"].(string)
+		object += name
+	}
+	if apiVersion == "" || kind == "" {
+		return []CRError{{Object: object, Path: "$", Message: "apiVersion and kind are required"}}
+	}
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return []CRError{{Object: object, Path: "$.apiVersion", Message: err.Error()}}
+	}
+	defName := definitionForGVK(swagger, gv.WithKind(kind))
+	if defName == "" {
+		return []CRError{{Object: object, Path: "$", Message: fmt.Sprintf("no schema for %s %s in the swagger doc", apiVersion, kind)}}
+	}
+	// the validator does not resolve references and cycles are replaced by a schema that accepts any value
+	inliner := newRefInliner(swagger, false)
+	inliner.inlining[defName] = true
+	def := inliner.schema(swagger.Definitions[defName])
+
+	var crErrors []CRError
+	result := validate.NewSchemaValidator(&def, nil, "", strfmt.Default).Validate(obj)
+	for _, err := range result.Errors {
+		crErr := CRError{Object: object, Path: "$", Message: err.Error()}
+		var validation *openapierrors.Validation
+		if errors.As(err, &validation) {
+			crErr.Path = jsonPath(validation.Name)
+			crErr.Message = strings.TrimPrefix(crErr.Message, validation.Name+" in body ")
+		}
+		crErrors = append(crErrors, crErr)
+	}
+	for _, path := range unknownFields(def, obj, "$") {
+		crErrors = append(crErrors, CRError{Object: object, Path: path, Message: "unknown field"})
+	}
+	sort.SliceStable(crErrors, func(i, j int) bool { return crErrors[i].Path < crErrors[j].Path })
+	return crErrors
+}
+
+// jsonPath converts the dotted field name of a validation error to a JSON path.
+func jsonPath(name string) string {
+	name = strings.TrimPrefix(name, ".")
+	if name == "" {
+		return "$"
+	}
+	return "$." + name
+}
+
+// unknownFields returns the JSON paths of the fields of value that are not declared by the schema.
+// Objects that allow additional properties or preserve unknown fields are not checked.
+func unknownFields(schema spec.Schema, value interface{}, path string) []string {
+	if preserve, _ := schema.Extensions.GetBool("x-kubernetes-preserve-unknown-fields"); preserve {
+		return nil
+	}
+	var unknown []string
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for _, name := range sortedKeys(value) {
+			fieldPath := path + "." + name
+			if prop, ok := schema.Properties[name]; ok {
+				unknown = append(unknown, unknownFields(prop, value[name], fieldPath)...)
+				continue
+			}
+			switch {
+			case schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil:
+				unknown = append(unknown, unknownFields(*schema.AdditionalProperties.Schema, value[name], fieldPath)...)
+			case len(schema.Properties) != 0 && schema.AdditionalProperties == nil:
+				unknown = append(unknown, fieldPath)
+			}
+		}
+	case []interface{}:
+		if schema.Items == nil || schema.Items.Schema == nil {
+			return nil
+		}
+		for i, item := range value {
+			unknown = append(unknown, unknownFields(*schema.Items.Schema, item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+	return unknown
+}
@@ -0,0 +1,87 @@
+package generator
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// virtualPrefix marks the resources file entries of virtual resources, create-only kinds such as SelfSubjectReview
+// or TokenRequest that are never stored. Their paths often have no kind to match, so each entry names the kind and
+// a template of its paths, e.g. `virtual:TokenRequest.authentication.k8s.io /api/v1/namespaces/*/serviceaccounts/*/token`.
+const virtualPrefix = "virtual:"
+
+// pathParam matches the {param} segments of a path template.
+var pathParam = regexp.MustCompile(`\{[^}/]*\}`)
+
+// virtualResource is a virtual resource entry of the resources file.
+type virtualResource struct {
+	groupKind    v1.GroupKind
+	pathTemplate string
+}
+
+// parseVirtual parses a virtual resource entry.
+func parseVirtual(entry string) (virtualResource, error) {
+	fields := strings.Fields(strings.TrimPrefix(entry, virtualPrefix))
+	if len(fields) != 2 {
+		return virtualResource{}, fmt.Errorf("'%s' must be a virtual:Kind.group followed by a path template, e.g. virtual:SelfSubjectReview.authentication.k8s.io /apis/authentication.k8s.io/*/selfsubjectreviews", entry)
+	}
+	kind, group := splitResource(fields[0])
+	if kind == "" || strings.ContainsAny(fields[0], "*?[") {
+		return virtualResource{}, fmt.Errorf("'%s' must name the virtual resource by its Kind.group without patterns", entry)
+	}
+	if !strings.HasPrefix(fields[1], "/") {
+		return virtualResource{}, fmt.Errorf("'%s' path template must start with /", entry)
+	}
+	if _, err := path.Match(fields[1], ""); err != nil {
+		return virtualResource{}, fmt.Errorf("'%s' path template is not a valid pattern: %w", entry, err)
+	}
+	return virtualResource{groupKind: v1.GroupKind{Group: group, Kind: kind}, pathTemplate: fields[1]}, nil
+}
+
+// splitVirtual separates the virtual resource entries from the Kind.group entries, the entries were validated when loaded.
+func splitVirtual(resources []string) ([]string, []virtualResource) {
+	var kinds []string
+	var virtual []virtualResource
+	for _, resource := range resources {
+		if !strings.HasPrefix(resource, virtualPrefix) {
+			kinds = append(kinds, resource)
+			continue
+		}
+		resource, _ := parseVirtual(resource)
+		virtual = append(virtual, resource)
+	}
+	return kinds, virtual
+}
+
+// matchPathTemplate reports whether the swagger path matches the path template. Templates are glob patterns where *
+// matches a single segment, and {param} segments match any parameter so templates need not use the doc's names.
+func matchPathTemplate(template, pathName string) bool {
+	matched, _ := path.Match(pathParam.ReplaceAllString(template, "{}"), pathParam.ReplaceAllString(pathName, "{}"))
+	return matched
+}
+
+// addVirtualGroupKinds adds the GroupKind of each virtual resource to desiredGroupKinds, found if its path template
+// matches a path in the swagger doc, and returns the matching paths in lexical order.
+func addVirtualGroupKinds(swagger *spec.Swagger, virtual []virtualResource, desiredGroupKinds map[v1.GroupKind]bool) []string {
+	var keepPaths []string
+	for _, resource := range virtual {
+		found := false
+		if swagger.Paths != nil {
+			for _, pathName := range sortedKeys(swagger.Paths.Paths) {
+				if matchPathTemplate(resource.pathTemplate, pathName) {
+					keepPaths = append(keepPaths, pathName)
+					found = true
+				}
+			}
+		}
+		desiredGroupKinds[resource.groupKind] = desiredGroupKinds[resource.groupKind] || found
+	}
+	sort.Strings(keepPaths)
+	return keepPaths
+}
@@ -0,0 +1,130 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const watchInterval = time.Second
+
+// Watch starts a cluster and generates a swagger doc the same way as Generate, then regenerates the doc
+// every time the local files at opts.CRDSource change. Each generated doc is passed to onGenerate.
+// Failed regenerations are logged and do not stop the watch. Watch returns once ctx is canceled.
+func Watch(ctx context.Context, opts Options, onGenerate func(*spec.Swagger) error) (err error) {
+	opts.setDefaults()
+	if opts.GoPackages != "" {
+		return fmt.Errorf("can not watch Go packages, watch the CRDs generated by controller-gen instead")
+	}
+	if opts.CRDSource == "" {
+		return fmt.Errorf("a CRD source is required to watch")
+	}
+	if isURL(opts.CRDSource) {
+		return fmt.Errorf("can not watch remote file '%s'", opts.CRDSource)
+	}
+
+	// fail before starting the cluster if the initial input is not usable
+	crds, err := loadCRDs(ctx, &opts)
+	if err != nil {
+		return err
+	}
+
+	timer := newPhaseTimer(&opts)
+	defer timer.restoreLogger()
+	cluster, err := startCluster(ctx, &opts, timer)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// the watch context is canceled at this point so cleanup needs its own context
+		stopErr := cluster.stop(context.Background())
+		if err == nil {
+			err = stopErr
+		}
+	}()
+
+	fingerprint, err := inputFingerprint(opts.CRDSource, opts.Recurse)
+	if err != nil {
+		return err
+	}
+	for {
+		if crds != nil {
+			swagger, err := generateFromCluster(ctx, &opts, cluster, timer, crds)
+			if swagger != nil {
+				// missing GroupKinds were already logged when they are ignored
+				err = onGenerate(swagger)
+			}
+			if err != nil {
+				zap.S().Errorf("Failed to generate swagger: %v", err)
+			}
+			timer.summary()
+		}
+
+		zap.S().Infof("Watching '%s' for changes.", opts.CRDSource)
+		fingerprint, err = waitForChange(ctx, opts.CRDSource, opts.Recurse, fingerprint)
+		if errors.Is(err, context.Canceled) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		timer.restart()
+		crds, err = loadCRDs(ctx, &opts)
+		if err != nil {
+			zap.S().Errorf("Failed to load changed CRDs: %v", err)
+		}
+	}
+}
+
+// waitForChange polls the input files until their fingerprint differs from the previous one.
+func waitForChange(ctx context.Context, path string, recurse bool, previous string) (string, error) {
+	current := previous
+	err := wait.PollUntilContextCancel(ctx, watchInterval, false, func(context.Context) (bool, error) {
+		var err error
+		current, err = inputFingerprint(path, recurse)
+		if err != nil {
+			// files may briefly not exist while an editor saves them
+			zap.S().Debugf("Failed to check '%s' for changes: %v", path, err)
+			return false, nil
+		}
+		return current != previous, nil
+	})
+	if ctx.Err() != nil {
+		return "", context.Canceled
+	}
+	return current, err
+}
+
+// inputFingerprint summarizes the name, size, and modification time of every input file.
+func inputFingerprint(path string, recurse bool) (string, error) {
+	var builder strings.Builder
+	err := filepath.WalkDir(path, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if filePath != path && !recurse {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&builder, "%s:%d:%d\n", filePath, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to read '%s': %w", path, err)
+	}
+	return builder.String(), nil
+}
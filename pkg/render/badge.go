@@ -0,0 +1,35 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// badge is the shields.io endpoint badge schema https://shields.io/badges/endpoint-badge.
+type badge struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color"`
+}
+
+// Badge renders a shields.io endpoint badge with the number of kinds documented in the swagger doc.
+func Badge(swagger *spec.Swagger) ([]byte, error) {
+	count := len(DocumentedGroupKinds(swagger))
+	noun := "kinds"
+	if count == 1 {
+		noun = "kind"
+	}
+	data, err := json.Marshal(badge{
+		SchemaVersion: 1,
+		Label:         "API",
+		Message:       fmt.Sprintf("%d %s documented", count, noun),
+		Color:         "blue",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal badge: %w", err)
+	}
+	return data, nil
+}
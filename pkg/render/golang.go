@@ -0,0 +1,179 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// Go renders the definitions of the swagger doc as Go types in the package, with json tags and descriptions
+// as doc comments. Definitions are named the same as with TypeScript, inline objects are named after the type and
+// field they are in. Optional fields are pointers unless they are slices or maps, and values of any type such as
+// int-or-string fields are interface{}.
+func Go(swagger *spec.Swagger, pkg string) ([]byte, error) {
+	if !token.IsIdentifier(pkg) {
+		return nil, fmt.Errorf("invalid go package name '%s'", pkg)
+	}
+	names := typeNames(sortedKeys(swagger.Definitions))
+	gw := &goWriter{names: names, used: map[string]bool{}}
+	for _, name := range names {
+		gw.used[name] = true
+	}
+	gw.buf.WriteString("// Code generated by crd-swagger. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&gw.buf, "package %s\n", pkg)
+	for _, name := range sortedKeys(swagger.Definitions) {
+		gw.writeType(names[name], swagger.Definitions[name])
+		// inline objects are written after the type they are in
+		for len(gw.pending) != 0 {
+			nested := gw.pending[0]
+			gw.pending = gw.pending[1:]
+			gw.writeType(nested.name, nested.schema)
+		}
+	}
+	out, err := format.Source(gw.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format go types: %w", err)
+	}
+	return out, nil
+}
+
+type goWriter struct {
+	buf bytes.Buffer
+	// names maps definition names to their Go names
+	names map[string]string
+	// used holds every type name written or queued
+	used map[string]bool
+	// pending are the inline objects still to be written as their own types
+	pending []goType
+}
+
+type goType struct {
+	name   string
+	schema spec.Schema
+}
+
+// writeType writes the schema as a named Go type.
+func (gw *goWriter) writeType(name string, schema spec.Schema) {
+	gw.buf.WriteString("\n")
+	gw.writeDoc(schema.Description)
+	if !isObjectSchema(schema) || len(schema.Properties) == 0 {
+		fmt.Fprintf(&gw.buf, "type %s %s\n", name, gw.typeOf(schema, name))
+		return
+	}
+	fmt.Fprintf(&gw.buf, "type %s struct {\n", name)
+	fields := map[string]bool{}
+	for _, prop := range sortedKeys(schema.Properties) {
+		propSchema := schema.Properties[prop]
+		field := uniqueName(goFieldName(prop), fields)
+		fields[field] = true
+		fieldType := gw.typeOf(propSchema, name+field)
+		tag := prop
+		if !containsString(schema.Required, prop) {
+			tag += ",omitempty"
+			if !strings.HasPrefix(fieldType, "[]") && !strings.HasPrefix(fieldType, "map[") && fieldType != "interface{}" {
+				fieldType = "*" + fieldType
+			}
+		}
+		gw.writeDoc(propSchema.Description)
+		fmt.Fprintf(&gw.buf, "%s %s `json:%s`\n", field, fieldType, strconv.Quote(tag))
+	}
+	gw.buf.WriteString("}\n")
+}
+
+// writeDoc writes the description as a line comment.
+func (gw *goWriter) writeDoc(description string) {
+	description = strings.TrimSpace(description)
+	if description == "" {
+		return
+	}
+	for _, line := range strings.Split(description, "\n") {
+		fmt.Fprintf(&gw.buf, "%s\n", strings.TrimRight("// "+line, " "))
+	}
+}
+
+// typeOf returns the Go type of the schema, inline objects are queued as a type with the nested name.
+func (gw *goWriter) typeOf(schema spec.Schema, nestedName string) string {
+	if ref := schema.Ref.String(); ref != "" {
+		if name, ok := gw.names[strings.TrimPrefix(ref, definitionPrefix)]; ok {
+			return name
+		}
+		return "interface{}"
+	}
+	if intOrString, _ := schema.Extensions.GetBool("x-kubernetes-int-or-string"); intOrString || schema.Format == "int-or-string" {
+		return "interface{}"
+	}
+	if len(schema.AllOf) == 1 {
+		return gw.typeOf(schema.AllOf[0], nestedName)
+	}
+	if len(schema.AllOf) != 0 {
+		return "interface{}"
+	}
+	switch {
+	case schema.Type.Contains("string"):
+		return "string"
+	case schema.Type.Contains("integer"):
+		if schema.Format == "int32" {
+			return "int32"
+		}
+		return "int64"
+	case schema.Type.Contains("number"):
+		return "float64"
+	case schema.Type.Contains("boolean"):
+		return "bool"
+	case schema.Type.Contains("array"):
+		if schema.Items == nil || schema.Items.Schema == nil {
+			return "[]interface{}"
+		}
+		return "[]" + gw.typeOf(*schema.Items.Schema, nestedName+"Item")
+	case isObjectSchema(schema) && len(schema.Properties) != 0:
+		name := uniqueName(nestedName, gw.used)
+		gw.used[name] = true
+		gw.pending = append(gw.pending, goType{name: name, schema: schema})
+		return name
+	case schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil:
+		return "map[string]" + gw.typeOf(*schema.AdditionalProperties.Schema, nestedName+"Value")
+	case schema.Type.Contains("object"):
+		return "map[string]interface{}"
+	}
+	return "interface{}"
+}
+
+// goFieldName returns the exported Go field name of the json property in PascalCase.
+func goFieldName(property string) string {
+	var name strings.Builder
+	upper := true
+	for _, r := range property {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		name.WriteRune(r)
+	}
+	result := name.String()
+	if result == "" || unicode.IsDigit(rune(result[0])) {
+		result = "F" + result
+	}
+	return result
+}
+
+// uniqueName returns the name, or the name with the lowest number added that is not in used.
+func uniqueName(name string, used map[string]bool) string {
+	if !used[name] {
+		return name
+	}
+	for i := 2; ; i++ {
+		if candidate := name + strconv.Itoa(i); !used[candidate] {
+			return candidate
+		}
+	}
+}
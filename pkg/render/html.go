@@ -0,0 +1,92 @@
+package render
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+//go:generate curl -fsSL -o assets/redoc.standalone.js https://cdn.redoc.ly/redoc/v2.1.3/bundles/redoc.standalone.js
+
+// RedocCDNScript is the CDN location of the version of the Redoc bundle that is embedded in html output.
+const RedocCDNScript = "https://cdn.redoc.ly/redoc/v2.1.3/bundles/redoc.standalone.js"
+
+// redocBundlePath is the path of the vendored Redoc bundle in assets.
+const redocBundlePath = "assets/redoc.standalone.js"
+
+//go:embed assets
+var assets embed.FS
+
+var htmlTemplate = template.Must(template.New("html").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>{{ .Title }}</title>
+  <style>body { margin: 0; padding: 0; }</style>
+</head>
+<body>
+  <div id="redoc-container"></div>
+{{- if .InlineScript }}
+  <script>{{ .InlineScript }}</script>
+{{- else }}
+  <script src="{{ .ScriptURL }}"></script>
+{{- end }}
+  <script>
+    Redoc.init({{ .Spec }}, {}, document.getElementById("redoc-container"));
+  </script>
+</body>
+</html>
+`))
+
+// HTML renders the swagger doc as a single static HTML page that displays the doc with Redoc.
+// The doc and, unless redocScript is a URL, the Redoc bundle are embedded in the page so it works offline.
+// redocScript is either a URL the Redoc bundle is loaded from, such as RedocCDNScript, or the path to a local
+// copy of the bundle. If redocScript is empty the bundle vendored into crd-swagger is used.
+func HTML(swagger *spec.Swagger, redocScript string) ([]byte, error) {
+	data := struct {
+		Title        string
+		ScriptURL    string
+		InlineScript template.JS
+		Spec         *spec.Swagger
+	}{
+		Title:     "API Reference",
+		ScriptURL: redocScript,
+		Spec:      swagger,
+	}
+	if swagger.Info != nil && swagger.Info.Title != "" {
+		data.Title = swagger.Info.Title
+	}
+	switch {
+	case data.ScriptURL == "":
+		script, err := assets.ReadFile(redocBundlePath)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("the Redoc bundle is not vendored into this build, run go generate ./pkg/render or pass --redoc-script %s", RedocCDNScript)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the vendored redoc script: %w", err)
+		}
+		data.InlineScript = template.JS(script)
+	case strings.HasPrefix(data.ScriptURL, "http://") || strings.HasPrefix(data.ScriptURL, "https://"):
+	default:
+		script, err := os.ReadFile(data.ScriptURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read redoc script '%s': %w", data.ScriptURL, err)
+		}
+		// the bundle is provided by the user and trusted to be embedded as is
+		data.InlineScript = template.JS(script)
+	}
+
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render html: %w", err)
+	}
+	return buf.Bytes(), nil
+}
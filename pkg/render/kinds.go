@@ -0,0 +1,79 @@
+package render
+
+import (
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const (
+	extensionGVK = "x-kubernetes-group-version-kind"
+	// metaDefinitionPrefix starts the names of the definitions shared by every kind, such as Status and WatchEvent.
+	metaDefinitionPrefix = "io.k8s.apimachinery."
+)
+
+// documentedKinds returns the sorted GroupVersionKinds that have a definition or at least one path in the swagger
+// doc, so kinds are found in docs without paths. Lists of kinds and the definitions shared by every kind are not kinds.
+func documentedKinds(swagger *spec.Swagger) []schema.GroupVersionKind {
+	found := map[schema.GroupVersionKind]bool{}
+	for name, def := range swagger.Definitions {
+		if gvk, ok := definitionKind(name, def); ok {
+			found[gvk] = true
+		}
+	}
+	if swagger.Paths != nil {
+		for _, item := range swagger.Paths.Paths {
+			for _, op := range []*spec.Operation{item.Get, item.Put, item.Post, item.Delete, item.Options, item.Head, item.Patch} {
+				if op == nil {
+					continue
+				}
+				var gvk schema.GroupVersionKind
+				if err := op.Extensions.GetObject(extensionGVK, &gvk); err != nil || gvk.Kind == "" {
+					continue
+				}
+				found[gvk] = true
+			}
+		}
+	}
+	kinds := make([]schema.GroupVersionKind, 0, len(found))
+	for gvk := range found {
+		kinds = append(kinds, gvk)
+	}
+	sort.Slice(kinds, func(i, j int) bool {
+		return kinds[i].String() < kinds[j].String()
+	})
+	return kinds
+}
+
+// DocumentedGroupKinds returns the sorted GroupKinds that have a definition or at least one path in the swagger doc.
+func DocumentedGroupKinds(swagger *spec.Swagger) []schema.GroupKind {
+	seen := map[schema.GroupKind]bool{}
+	var groupKinds []schema.GroupKind
+	for _, gvk := range documentedKinds(swagger) {
+		if seen[gvk.GroupKind()] {
+			continue
+		}
+		seen[gvk.GroupKind()] = true
+		groupKinds = append(groupKinds, gvk.GroupKind())
+	}
+	return groupKinds
+}
+
+// definitionKind returns the GroupVersionKind of a kind's definition. Lists of kinds and the definitions shared by
+// every kind are not kinds.
+func definitionKind(name string, def spec.Schema) (schema.GroupVersionKind, bool) {
+	var gvks []schema.GroupVersionKind
+	if strings.HasPrefix(name, metaDefinitionPrefix) || def.Extensions.GetObject(extensionGVK, &gvks) != nil {
+		return schema.GroupVersionKind{}, false
+	}
+	// definitions shared by several kinds, such as DeleteOptions, list a GroupVersionKind for each
+	if len(gvks) != 1 || gvks[0].Kind == "" {
+		return schema.GroupVersionKind{}, false
+	}
+	if _, ok := def.Properties["items"]; ok && strings.HasSuffix(gvks[0].Kind, "List") {
+		return schema.GroupVersionKind{}, false
+	}
+	return gvks[0], true
+}
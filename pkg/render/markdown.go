@@ -0,0 +1,224 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const (
+	definitionPrefix     = "#/definitions/"
+	extensionValidations = "x-kubernetes-validations"
+)
+
+// validationRule is a CEL rule in the x-kubernetes-validations extension of a schema.
+type validationRule struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message,omitempty"`
+}
+
+// Markdown renders a Markdown page for every kind in the swagger doc with its operations, a table of its fields, and a
+// table of its CEL validation rules.
+// The pages are returned keyed by file name.
+func Markdown(swagger *spec.Swagger) (map[string][]byte, error) {
+	kindVersions := map[schema.GroupKind][]schema.GroupVersionKind{}
+	for _, gvk := range documentedKinds(swagger) {
+		kindVersions[gvk.GroupKind()] = append(kindVersions[gvk.GroupKind()], gvk)
+	}
+	pages := make(map[string][]byte, len(kindVersions))
+	for _, gk := range DocumentedGroupKinds(swagger) {
+		pages[gk.String()+".md"] = markdownPage(swagger, gk, kindVersions[gk])
+	}
+	return pages, nil
+}
+
+func markdownPage(swagger *spec.Swagger, gk schema.GroupKind, gvks []schema.GroupVersionKind) []byte {
+	var buf bytes.Buffer
+	versions := make([]string, 0, len(gvks))
+	for _, gvk := range gvks {
+		versions = append(versions, gvk.Version)
+	}
+	fmt.Fprintf(&buf, "# %s\n\n", gk.Kind)
+	fmt.Fprintf(&buf, "**Group:** `%s`  \n**Versions:** `%s`\n\n", groupName(gk.Group), strings.Join(versions, "`, `"))
+
+	buf.WriteString("## Operations\n\n")
+	buf.WriteString("| Method | Path | Verb | Description |\n| --- | --- | --- | --- |\n")
+	for _, row := range kindOperations(swagger, gk) {
+		buf.WriteString(row)
+	}
+
+	for _, gvk := range gvks {
+		name, def, ok := findDefinition(swagger, gvk)
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(&buf, "\n## %s `%s`\n\n", gvk.Kind, gvk.Version)
+		if def.Description != "" {
+			fmt.Fprintf(&buf, "%s\n\n", def.Description)
+		}
+		fmt.Fprintf(&buf, "Definition: `%s`\n\n", name)
+		buf.WriteString("| Field | Type | Required | Description |\n| --- | --- | --- | --- |\n")
+		writeFieldRows(&buf, "", def, map[string]bool{})
+
+		var rules bytes.Buffer
+		writeValidationRows(&rules, "", def)
+		if rules.Len() != 0 {
+			buf.WriteString("\n### Validation rules\n\n")
+			buf.WriteString("| Field | Rule | Message |\n| --- | --- | --- |\n")
+			buf.Write(rules.Bytes())
+		}
+	}
+	return buf.Bytes()
+}
+
+// kindOperations returns a table row for every operation in the doc for the GroupKind sorted by path.
+func kindOperations(swagger *spec.Swagger, gk schema.GroupKind) []string {
+	var rows []string
+	if swagger.Paths == nil {
+		return nil
+	}
+	for _, pathName := range sortedKeys(swagger.Paths.Paths) {
+		item := swagger.Paths.Paths[pathName]
+		for _, method := range []struct {
+			name string
+			op   *spec.Operation
+		}{
+			{"GET", item.Get}, {"PUT", item.Put}, {"POST", item.Post}, {"DELETE", item.Delete},
+			{"OPTIONS", item.Options}, {"HEAD", item.Head}, {"PATCH", item.Patch},
+		} {
+			if method.op == nil {
+				continue
+			}
+			var gvk schema.GroupVersionKind
+			if err := method.op.Extensions.GetObject(extensionGVK, &gvk); err != nil || gvk.GroupKind() != gk {
+				continue
+			}
+			verb, _ := method.op.Extensions.GetString("x-kubernetes-action")
+			rows = append(rows, fmt.Sprintf("| %s | `%s` | %s | %s |\n", method.name, pathName, verb, tableText(method.op.Description)))
+		}
+	}
+	return rows
+}
+
+// findDefinition returns the definition that has the GroupVersionKind in its x-kubernetes-group-version-kind extension.
+func findDefinition(swagger *spec.Swagger, gvk schema.GroupVersionKind) (string, spec.Schema, bool) {
+	for _, name := range sortedKeys(swagger.Definitions) {
+		def := swagger.Definitions[name]
+		var gvks []schema.GroupVersionKind
+		if err := def.Extensions.GetObject(extensionGVK, &gvks); err != nil {
+			continue
+		}
+		for i := range gvks {
+			if gvks[i] == gvk {
+				return name, def, true
+			}
+		}
+	}
+	return "", spec.Schema{}, false
+}
+
+// writeFieldRows writes a table row for every property of the schema and recurses into inline object properties.
+// References to other definitions are not expanded.
+func writeFieldRows(buf *bytes.Buffer, prefix string, schema spec.Schema, visited map[string]bool) {
+	for _, name := range sortedKeys(schema.Properties) {
+		prop := schema.Properties[name]
+		field := prefix + name
+		required := ""
+		if containsString(schema.Required, name) {
+			required = "yes"
+		}
+		fmt.Fprintf(buf, "| `%s` | %s | %s | %s |\n", field, schemaType(prop), required, tableText(prop.Description))
+		child := prop
+		if child.Items != nil && child.Items.Schema != nil {
+			child = *child.Items.Schema
+			field += "[]"
+		}
+		if len(child.Properties) != 0 && !visited[field] {
+			visited[field] = true
+			writeFieldRows(buf, field+".", child, visited)
+		}
+	}
+}
+
+// writeValidationRows writes a table row for every CEL rule of the schema and its inline properties and items.
+// Rules of the kind's schema itself are shown for the field ".".
+func writeValidationRows(buf *bytes.Buffer, field string, schema spec.Schema) {
+	var rules []validationRule
+	if err := schema.Extensions.GetObject(extensionValidations, &rules); err == nil {
+		name := field
+		if name == "" {
+			name = "."
+		}
+		for _, rule := range rules {
+			fmt.Fprintf(buf, "| `%s` | `` %s `` | %s |\n", name, tableText(rule.Rule), tableText(rule.Message))
+		}
+	}
+	for _, name := range sortedKeys(schema.Properties) {
+		childField := name
+		if field != "" {
+			childField = field + "." + name
+		}
+		writeValidationRows(buf, childField, schema.Properties[name])
+	}
+	if schema.Items != nil && schema.Items.Schema != nil {
+		writeValidationRows(buf, field+"[]", *schema.Items.Schema)
+	}
+}
+
+// schemaType describes the type of a schema, e.g. string, []integer, map[string]boolean, int-or-string, or a definition name.
+func schemaType(schema spec.Schema) string {
+	if ref := schema.Ref.String(); ref != "" {
+		return "`" + strings.TrimPrefix(ref, definitionPrefix) + "`"
+	}
+	switch {
+	case schema.Type.Contains("array") && schema.Items != nil && schema.Items.Schema != nil:
+		return "[]" + schemaType(*schema.Items.Schema)
+	case schema.Type.Contains("object") && schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil:
+		return "map[string]" + schemaType(*schema.AdditionalProperties.Schema)
+	case len(schema.Type) != 0:
+		typ := strings.Join(schema.Type, " or ")
+		if schema.Format != "" {
+			typ += " (" + schema.Format + ")"
+		}
+		return typ
+	}
+	if intOrString, _ := schema.Extensions.GetBool("x-kubernetes-int-or-string"); intOrString {
+		return "int-or-string"
+	}
+	return "object"
+}
+
+// tableText makes text safe to use in a single Markdown table cell.
+func tableText(text string) string {
+	text = strings.ReplaceAll(text, "|", "\\|")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func groupName(group string) string {
+	if group == "" {
+		return "core"
+	}
+	return group
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func containsString(list []string, value string) bool {
+	for i := range list {
+		if list[i] == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/KevinJoiner/crd-swagger/pkg/generator"
+)
+
+// PRCommentLimit is the most characters GitHub accepts in a comment body.
+const PRCommentLimit = 65536
+
+const (
+	severityBreaking = "🔴"
+	severityChanged  = "🟡"
+	severityAdded    = "🟢"
+
+	// otherKind groups the changes to shared definitions that are not for a kind
+	otherKind = "Shared definitions"
+	// truncateReserve is kept free for the note listing the changes left out of a comment that hit the limit
+	truncateReserve = 200
+)
+
+// addedChanges are the non-breaking changes that only add to the API.
+var addedChanges = map[string]bool{
+	generator.ChangePathAdded:       true,
+	generator.ChangeOperationAdded:  true,
+	generator.ChangeDefinitionAdded: true,
+	generator.ChangeFieldAdded:      true,
+}
+
+// PRComment renders the changes between two swagger docs as a Markdown summary for a pull request comment.
+// Changes are grouped in a collapsible section per kind, kinds with breaking changes come first and are expanded.
+// Each change is marked 🔴 when breaking, 🟡 when it changes behavior compatibly, and 🟢 when it only adds to the API.
+// Changes that do not fit in PRCommentLimit are counted at the end of the comment instead of listed.
+func PRComment(changes []generator.Change) []byte {
+	byKind := map[string][]generator.Change{}
+	breakingByKind := map[string]int{}
+	breaking := 0
+	for _, change := range changes {
+		kind := change.Kind
+		if kind == "" {
+			kind = otherKind
+		}
+		byKind[kind] = append(byKind[kind], change)
+		if change.Breaking() {
+			breakingByKind[kind]++
+			breaking++
+		}
+	}
+	kinds := sortedKeys(byKind)
+	sort.SliceStable(kinds, func(i, j int) bool {
+		return breakingByKind[kinds[i]] != 0 && breakingByKind[kinds[j]] == 0
+	})
+
+	var buf bytes.Buffer
+	buf.WriteString("## API changes\n\n")
+	if len(changes) == 0 {
+		buf.WriteString("No changes to the swagger doc.\n")
+		return buf.Bytes()
+	}
+	fmt.Fprintf(&buf, "%s in %s, %s %d breaking.\n\n", plural(len(changes), "change"), plural(len(kinds), "section"), severityBreaking, breaking)
+
+	omitted := 0
+	for _, kind := range kinds {
+		kindChanges := byKind[kind]
+		// breaking changes are listed first so they are the last to be left out
+		sort.SliceStable(kindChanges, func(i, j int) bool { return kindChanges[i].Breaking() && !kindChanges[j].Breaking() })
+
+		var section bytes.Buffer
+		open, severity := "", severityAdded
+		for _, change := range kindChanges {
+			if changeSeverity(change) == severityChanged {
+				severity = severityChanged
+			}
+		}
+		if breakingByKind[kind] != 0 {
+			open, severity = " open", severityBreaking
+		}
+		fmt.Fprintf(&section, "<details%s>\n<summary>%s <b>%s</b>: %s, %d breaking</summary>\n\n", open, severity, kind, plural(len(kindChanges), "change"), breakingByKind[kind])
+		section.WriteString("| | Change | Path |\n| --- | --- | --- |\n")
+		const sectionEnd = "\n</details>\n\n"
+		rows := 0
+		for _, change := range kindChanges {
+			row := fmt.Sprintf("| %s | %s | `%s` |\n", changeSeverity(change), tableText(change.Message), change.Path)
+			if buf.Len()+section.Len()+len(row)+len(sectionEnd)+truncateReserve > PRCommentLimit {
+				break
+			}
+			section.WriteString(row)
+			rows++
+		}
+		omitted += len(kindChanges) - rows
+		if rows == 0 {
+			continue
+		}
+		section.WriteString(sectionEnd)
+		buf.Write(section.Bytes())
+	}
+	if omitted != 0 {
+		fmt.Fprintf(&buf, "%s not shown to fit the comment size limit, run `crd-swagger diff` for the full list.\n", plural(omitted, "more change"))
+	}
+	return buf.Bytes()
+}
+
+func changeSeverity(change generator.Change) string {
+	switch {
+	case change.Breaking():
+		return severityBreaking
+	case addedChanges[change.Rule]:
+		return severityAdded
+	default:
+		return severityChanged
+	}
+}
+
+// plural returns the count followed by the noun, adding an s unless the count is one.
+func plural(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, noun)
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
@@ -0,0 +1,2 @@
+// Package render converts generated swagger documents into other documentation formats.
+package render
@@ -0,0 +1,138 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/KevinJoiner/crd-swagger/pkg/generator"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolName     = "crd-swagger"
+	toolURI      = "https://github.com/KevinJoiner/crd-swagger"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+}
+
+// SARIF renders findings as a SARIF log for code scanning tools.
+// If docURI is not empty it is used as the file location of every finding.
+func SARIF(findings []generator.Finding, docURI string) ([]byte, error) {
+	run := newSARIFRun()
+	for _, finding := range findings {
+		var physical *sarifPhysicalLocation
+		if docURI != "" {
+			physical = &sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: docURI}}
+		}
+		run.addResult(finding, physical)
+	}
+	return marshalSARIF(run)
+}
+
+// DiagnosticsSARIF renders diagnostics as a SARIF log for code scanning tools, located at their line in their file.
+func DiagnosticsSARIF(diagnostics []generator.Diagnostic) ([]byte, error) {
+	run := newSARIFRun()
+	for _, diagnostic := range diagnostics {
+		run.addResult(diagnostic.Finding, &sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: diagnostic.File},
+			Region:           &sarifRegion{StartLine: diagnostic.Line, StartColumn: diagnostic.Column},
+		})
+	}
+	return marshalSARIF(run)
+}
+
+func newSARIFRun() *sarifRun {
+	return &sarifRun{
+		Tool:    sarifTool{Driver: sarifDriver{Name: toolName, InformationURI: toolURI}},
+		Results: []sarifResult{},
+	}
+}
+
+// addResult adds the finding as a result of the run, physical is where the finding is in a file if it is known.
+func (r *sarifRun) addResult(finding generator.Finding, physical *sarifPhysicalLocation) {
+	seen := false
+	for _, rule := range r.Tool.Driver.Rules {
+		seen = seen || rule.ID == finding.Rule
+	}
+	if !seen {
+		r.Tool.Driver.Rules = append(r.Tool.Driver.Rules, sarifRule{ID: finding.Rule})
+	}
+	location := sarifLocation{PhysicalLocation: physical}
+	text := finding.Message
+	if finding.Path != "" {
+		location.LogicalLocations = []sarifLogicalLocation{{FullyQualifiedName: finding.Path}}
+		text = fmt.Sprintf("%s: %s", finding.Path, finding.Message)
+	}
+	r.Results = append(r.Results, sarifResult{
+		RuleID:    finding.Rule,
+		Level:     "warning",
+		Message:   sarifMessage{Text: text},
+		Locations: []sarifLocation{location},
+	})
+}
+
+func marshalSARIF(run *sarifRun) ([]byte, error) {
+	data, err := json.MarshalIndent(sarifLog{Version: sarifVersion, Schema: sarifSchema, Runs: []sarifRun{*run}}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal sarif: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,193 @@
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// swaggerHead holds the document fields written before the paths.
+type swaggerHead struct {
+	ID       string     `json:"id,omitempty"`
+	Consumes []string   `json:"consumes,omitempty"`
+	Produces []string   `json:"produces,omitempty"`
+	Schemes  []string   `json:"schemes,omitempty"`
+	Swagger  string     `json:"swagger,omitempty"`
+	Info     *spec.Info `json:"info,omitempty"`
+	Host     string     `json:"host,omitempty"`
+	BasePath string     `json:"basePath,omitempty"`
+}
+
+// swaggerTail holds the document fields written after the definitions.
+type swaggerTail struct {
+	Parameters          map[string]spec.Parameter   `json:"parameters,omitempty"`
+	Responses           map[string]spec.Response    `json:"responses,omitempty"`
+	SecurityDefinitions spec.SecurityDefinitions    `json:"securityDefinitions,omitempty"`
+	Security            []map[string][]string       `json:"security,omitempty"`
+	Tags                []spec.Tag                  `json:"tags,omitempty"`
+	ExternalDocs        *spec.ExternalDocumentation `json:"externalDocs,omitempty"`
+}
+
+// StreamJSON writes the swagger doc as JSON to w one path and definition at a time, so the encoded document
+// is never held in memory as a whole. The output has the same fields in the same order as json.Marshal.
+// When pretty is set the output is indented the same as json.MarshalIndent with two spaces.
+func StreamJSON(w io.Writer, swagger *spec.Swagger, pretty bool) error {
+	s := &jsonStream{w: bufio.NewWriter(w), pretty: pretty}
+	s.write("{")
+	head := swaggerHead{
+		ID: swagger.ID, Consumes: swagger.Consumes, Produces: swagger.Produces, Schemes: swagger.Schemes,
+		Swagger: swagger.Swagger, Info: swagger.Info, Host: swagger.Host, BasePath: swagger.BasePath,
+	}
+	s.fields(head)
+
+	if swagger.Paths == nil {
+		s.field("paths", nil)
+	} else {
+		values := make(map[string]interface{}, len(swagger.Paths.Paths)+len(swagger.Paths.Extensions))
+		for name, item := range swagger.Paths.Paths {
+			values[name] = item
+		}
+		for name, ext := range swagger.Paths.Extensions {
+			values[name] = ext
+		}
+		s.object("paths", values)
+	}
+	if len(swagger.Definitions) != 0 {
+		values := make(map[string]interface{}, len(swagger.Definitions))
+		for name, def := range swagger.Definitions {
+			values[name] = def
+		}
+		s.object("definitions", values)
+	}
+
+	tail := swaggerTail{
+		Parameters: swagger.Parameters, Responses: swagger.Responses, SecurityDefinitions: swagger.SecurityDefinitions,
+		Security: swagger.Security, Tags: swagger.Tags, ExternalDocs: swagger.ExternalDocs,
+	}
+	s.fields(tail)
+	for _, name := range sortedKeys(swagger.Extensions) {
+		s.field(name, swagger.Extensions[name])
+	}
+
+	if s.pretty && s.count != 0 {
+		s.write("\n")
+	}
+	s.write("}")
+	if s.err != nil {
+		return fmt.Errorf("failed to write swagger doc: %w", s.err)
+	}
+	return s.w.Flush()
+}
+
+// jsonStream writes the fields of a JSON object, the first error stops all further writes.
+type jsonStream struct {
+	w      *bufio.Writer
+	pretty bool
+	// count is the number of fields written to the object being written
+	count int
+	err   error
+}
+
+func (s *jsonStream) write(text string) {
+	if s.err == nil {
+		_, s.err = s.w.WriteString(text)
+	}
+}
+
+// key starts the next field of the object at depth.
+func (s *jsonStream) key(depth int, name string) {
+	if s.count != 0 {
+		s.write(",")
+	}
+	s.count++
+	key, _ := json.Marshal(name)
+	if s.pretty {
+		s.write("\n" + indentation(depth))
+		s.write(string(key) + ": ")
+		return
+	}
+	s.write(string(key) + ":")
+}
+
+// value writes the encoded value of a field at depth.
+func (s *jsonStream) value(depth int, value interface{}) {
+	if s.err != nil {
+		return
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		s.err = err
+		return
+	}
+	if s.pretty {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, data, indentation(depth), "  "); err != nil {
+			s.err = err
+			return
+		}
+		data = buf.Bytes()
+	}
+	_, s.err = s.w.Write(data)
+}
+
+func (s *jsonStream) field(name string, value interface{}) {
+	s.key(1, name)
+	s.value(1, value)
+}
+
+// fields writes each field of the encoded struct as a field of the document.
+func (s *jsonStream) fields(value interface{}) {
+	if s.err != nil {
+		return
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		s.err = err
+		return
+	}
+	// the fields are decoded in order to keep the order of the struct
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil {
+		s.err = err
+		return
+	}
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			s.err = err
+			return
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			s.err = err
+			return
+		}
+		s.field(token.(string), raw)
+	}
+}
+
+// object writes a field holding an object with the values sorted by name, each encoded on its own.
+func (s *jsonStream) object(name string, values map[string]interface{}) {
+	s.key(1, name)
+	s.write("{")
+	outer := s.count
+	s.count = 0
+	for _, key := range sortedKeys(values) {
+		s.key(2, key)
+		s.value(2, values[key])
+	}
+	if s.pretty && s.count != 0 {
+		s.write("\n" + indentation(1))
+	}
+	s.write("}")
+	s.count = outer
+}
+
+func indentation(depth int) string {
+	return strings.Repeat("  ", depth)
+}
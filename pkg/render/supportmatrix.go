@@ -0,0 +1,114 @@
+package render
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// supportMatrix records which kinds the swagger doc of each Kubernetes version in a version matrix documents,
+// so docs sites can show the version each kind is available since.
+type supportMatrix struct {
+	// Versions are the Kubernetes versions with a swagger doc, sorted.
+	Versions []string `json:"versions"`
+	// Kinds are every kind documented by at least one version, sorted by Kind.group.
+	Kinds []kindSupport `json:"kinds"`
+}
+
+// kindSupport is whether a kind is documented by each version of the matrix.
+type kindSupport struct {
+	// Kind is the Kind.group of the kind.
+	Kind string `json:"kind"`
+	// Versions has an entry for every version of the matrix.
+	Versions map[string]kindVersionSupport `json:"versions"`
+}
+
+// kindVersionSupport is whether a kind is documented by the swagger doc of a version.
+type kindVersionSupport struct {
+	Present bool `json:"present"`
+	// APIVersions are the group versions of the kind in the doc, e.g. v1 and v1beta1.
+	APIVersions []string `json:"apiVersions,omitempty"`
+	// SchemaHash is the sha256 of the kind's definitions, it changes between versions when the schema does.
+	SchemaHash string `json:"schemaHash,omitempty"`
+}
+
+// SupportMatrix renders a JSON table of which kinds the swagger docs keyed by Kubernetes version document,
+// with a hash of each kind's schema so changes between versions are visible.
+func SupportMatrix(docs map[string]*spec.Swagger) ([]byte, error) {
+	matrix := supportMatrix{Versions: make([]string, 0, len(docs))}
+	for version := range docs {
+		matrix.Versions = append(matrix.Versions, version)
+	}
+	sort.Strings(matrix.Versions)
+
+	kinds := map[schema.GroupKind]*kindSupport{}
+	for _, version := range matrix.Versions {
+		present, err := versionKinds(docs[version])
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash the kinds of Kubernetes %s: %w", version, err)
+		}
+		for gk, support := range present {
+			if kinds[gk] == nil {
+				kinds[gk] = &kindSupport{Kind: gk.String(), Versions: map[string]kindVersionSupport{}}
+			}
+			kinds[gk].Versions[version] = support
+		}
+	}
+	for _, support := range kinds {
+		for _, version := range matrix.Versions {
+			if _, ok := support.Versions[version]; !ok {
+				support.Versions[version] = kindVersionSupport{}
+			}
+		}
+		matrix.Kinds = append(matrix.Kinds, *support)
+	}
+	sort.Slice(matrix.Kinds, func(i, j int) bool {
+		return matrix.Kinds[i].Kind < matrix.Kinds[j].Kind
+	})
+
+	data, err := json.MarshalIndent(matrix, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal support matrix: %w", err)
+	}
+	return data, nil
+}
+
+// versionKinds returns the support of every kind documented by the swagger doc. The schema hash covers the
+// definitions of every group version of the kind.
+func versionKinds(swagger *spec.Swagger) (map[schema.GroupKind]kindVersionSupport, error) {
+	definitions := map[schema.GroupKind][]string{}
+	for name, def := range swagger.Definitions {
+		if gvk, ok := definitionKind(name, def); ok {
+			definitions[gvk.GroupKind()] = append(definitions[gvk.GroupKind()], name)
+		}
+	}
+	apiVersions := map[schema.GroupKind][]string{}
+	for _, gvk := range documentedKinds(swagger) {
+		apiVersions[gvk.GroupKind()] = append(apiVersions[gvk.GroupKind()], gvk.GroupVersion().String())
+	}
+
+	kinds := make(map[schema.GroupKind]kindVersionSupport, len(apiVersions))
+	for gk, versions := range apiVersions {
+		names := definitions[gk]
+		sort.Strings(names)
+		hash := sha256.New()
+		for _, name := range names {
+			data, err := json.Marshal(swagger.Definitions[name])
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal definition %s: %w", name, err)
+			}
+			fmt.Fprintf(hash, "%s\n%s\n", name, data)
+		}
+		support := kindVersionSupport{Present: true, APIVersions: versions}
+		if len(names) != 0 {
+			support.SchemaHash = "sha256:" + hex.EncodeToString(hash.Sum(nil))
+		}
+		kinds[gk] = support
+	}
+	return kinds, nil
+}
@@ -0,0 +1,207 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+var tsIdentifierRegex = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// TypeScript renders the definitions of the swagger doc as TypeScript interfaces with descriptions as JSDoc comments.
+// Each definition is named after its kind, e.g. Cluster, with as many of the preceding segments of the
+// definition name as needed to tell apart kinds with the same name, e.g. V3Cluster.
+func TypeScript(swagger *spec.Swagger) ([]byte, error) {
+	names := typeNames(sortedKeys(swagger.Definitions))
+	ts := &typeScriptWriter{names: names}
+	ts.buf.WriteString("// Code generated by crd-swagger. DO NOT EDIT.\n")
+	for _, name := range sortedKeys(swagger.Definitions) {
+		def := swagger.Definitions[name]
+		ts.buf.WriteString("\n")
+		ts.writeDoc(def.Description, "")
+		if isObjectSchema(def) && len(def.Properties) != 0 {
+			fmt.Fprintf(&ts.buf, "export interface %s ", names[name])
+			ts.writeProperties(def, "")
+			ts.buf.WriteString("\n")
+			continue
+		}
+		fmt.Fprintf(&ts.buf, "export type %s = %s;\n", names[name], ts.typeOf(def, ""))
+	}
+	return ts.buf.Bytes(), nil
+}
+
+type typeScriptWriter struct {
+	buf bytes.Buffer
+	// names maps definition names to their TypeScript names
+	names map[string]string
+}
+
+// writeDoc writes the description as a JSDoc comment at the indent.
+func (ts *typeScriptWriter) writeDoc(description, indent string) {
+	description = strings.TrimSpace(strings.ReplaceAll(description, "*/", `*\/`))
+	if description == "" {
+		return
+	}
+	lines := strings.Split(description, "\n")
+	if len(lines) == 1 {
+		fmt.Fprintf(&ts.buf, "%s/** %s */\n", indent, lines[0])
+		return
+	}
+	fmt.Fprintf(&ts.buf, "%s/**\n", indent)
+	for _, line := range lines {
+		fmt.Fprintf(&ts.buf, "%s *%s\n", indent, strings.TrimRight(" "+line, " "))
+	}
+	fmt.Fprintf(&ts.buf, "%s */\n", indent)
+}
+
+// writeProperties writes the properties of the object schema as a TypeScript object type.
+func (ts *typeScriptWriter) writeProperties(schema spec.Schema, indent string) {
+	ts.buf.WriteString("{\n")
+	inner := indent + "  "
+	for _, name := range sortedKeys(schema.Properties) {
+		prop := schema.Properties[name]
+		ts.writeDoc(prop.Description, inner)
+		optional := "?"
+		if containsString(schema.Required, name) {
+			optional = ""
+		}
+		key := name
+		if !tsIdentifierRegex.MatchString(name) {
+			key = strconv.Quote(name)
+		}
+		fmt.Fprintf(&ts.buf, "%s%s%s: %s;\n", inner, key, optional, ts.typeOf(prop, inner))
+	}
+	ts.buf.WriteString(indent + "}")
+}
+
+// typeOf returns the TypeScript type of the schema, inline objects are written at the indent.
+func (ts *typeScriptWriter) typeOf(schema spec.Schema, indent string) string {
+	if ref := schema.Ref.String(); ref != "" {
+		if name, ok := ts.names[strings.TrimPrefix(ref, definitionPrefix)]; ok {
+			return name
+		}
+		return "unknown"
+	}
+	if intOrString, _ := schema.Extensions.GetBool("x-kubernetes-int-or-string"); intOrString || schema.Format == "int-or-string" {
+		return "number | string"
+	}
+	if len(schema.AllOf) != 0 {
+		members := make([]string, 0, len(schema.AllOf))
+		for _, member := range schema.AllOf {
+			members = append(members, ts.typeOf(member, indent))
+		}
+		return strings.Join(members, " & ")
+	}
+	if len(schema.Enum) != 0 {
+		values := make([]string, 0, len(schema.Enum))
+		for _, value := range schema.Enum {
+			values = append(values, enumLiteral(value))
+		}
+		return strings.Join(values, " | ")
+	}
+	switch {
+	case schema.Type.Contains("string"):
+		return "string"
+	case schema.Type.Contains("integer"), schema.Type.Contains("number"):
+		return "number"
+	case schema.Type.Contains("boolean"):
+		return "boolean"
+	case schema.Type.Contains("array"):
+		if schema.Items == nil || schema.Items.Schema == nil {
+			return "unknown[]"
+		}
+		item := ts.typeOf(*schema.Items.Schema, indent)
+		if strings.Contains(item, " ") && !strings.HasSuffix(item, "}") {
+			item = "(" + item + ")"
+		}
+		return item + "[]"
+	case isObjectSchema(schema) && len(schema.Properties) != 0:
+		var nested typeScriptWriter
+		nested.names = ts.names
+		nested.writeProperties(schema, indent)
+		return nested.buf.String()
+	case schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil:
+		return "{ [key: string]: " + ts.typeOf(*schema.AdditionalProperties.Schema, indent) + " }"
+	case schema.Type.Contains("object"):
+		return "{ [key: string]: unknown }"
+	}
+	return "unknown"
+}
+
+func isObjectSchema(schema spec.Schema) bool {
+	return schema.Type.Contains("object") || len(schema.Type) == 0 && len(schema.Properties) != 0
+}
+
+func enumLiteral(value interface{}) string {
+	switch value := value.(type) {
+	case string:
+		return strconv.Quote(value)
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// typeNames returns a unique type name for each definition, using the fewest trailing segments of
+// the definition name that tell it apart from the others.
+func typeNames(definitions []string) map[string]string {
+	names := make(map[string]string, len(definitions))
+	segments := make(map[string]int, len(definitions))
+	for _, def := range definitions {
+		segments[def] = 1
+	}
+	for {
+		byName := map[string][]string{}
+		for _, def := range definitions {
+			name := typeName(def, segments[def])
+			names[def] = name
+			byName[name] = append(byName[name], def)
+		}
+		collision := false
+		for _, defs := range byName {
+			if len(defs) < 2 {
+				continue
+			}
+			for _, def := range defs {
+				if segments[def] < strings.Count(def, ".")+1 {
+					segments[def]++
+					collision = true
+				}
+			}
+		}
+		if !collision {
+			return names
+		}
+	}
+}
+
+// typeName joins the last count segments of the definition name in PascalCase.
+func typeName(definition string, count int) string {
+	parts := strings.FieldsFunc(definition, func(r rune) bool { return r == '.' })
+	if count < len(parts) {
+		parts = parts[len(parts)-count:]
+	}
+	var name strings.Builder
+	for _, part := range parts {
+		for i, r := range part {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+				continue
+			}
+			if i == 0 {
+				r = unicode.ToUpper(r)
+			}
+			name.WriteRune(r)
+		}
+	}
+	result := name.String()
+	if result == "" || unicode.IsDigit(rune(result[0])) {
+		result = "T" + result
+	}
+	return result
+}